@@ -0,0 +1,76 @@
+package metadata
+
+import (
+	"fmt"
+	"io"
+	"runtime/metrics"
+	"slices"
+)
+
+// AuditReport lists the differences between the runtime metrics of a Go
+// toolchain and the metrics supported by the library, see Audit.
+type AuditReport struct {
+	// Unsupported are the runtime metrics the library doesn't report.
+	Unsupported []string
+	// Missing are the metrics the library refers to that the runtime doesn't
+	// have. This is a bug: the features relying on them are degraded.
+	Missing []string
+	// UnmappedUnits are the units of runtime metrics without a Datadog unit,
+	// see RuntimeUnitMapping.
+	UnmappedUnits []string
+}
+
+// Audit compares the runtime metrics descs, usually metrics.All(), with the
+// names of the metrics the library supports and the ones it refers to
+// explicitly.
+func Audit(descs []metrics.Description, supported, referenced []string) AuditReport {
+	var r AuditReport
+	runtimeNames := map[string]bool{}
+	for _, d := range descs {
+		runtimeNames[d.Name] = true
+		if !slices.Contains(supported, d.Name) {
+			r.Unsupported = append(r.Unsupported, d.Name)
+		}
+		if _, ok := MetricUnit(d.Name); !ok {
+			r.UnmappedUnits = append(r.UnmappedUnits, RuntimeUnit(d.Name))
+		}
+	}
+	for _, name := range append(slices.Clone(supported), referenced...) {
+		if !runtimeNames[name] {
+			r.Missing = append(r.Missing, name)
+		}
+	}
+	for _, s := range []*[]string{&r.Unsupported, &r.Missing, &r.UnmappedUnits} {
+		slices.Sort(*s)
+		*s = slices.Compact(*s)
+	}
+	return r
+}
+
+// OK returns true if the library doesn't refer to metrics missing from the
+// runtime. Unsupported metrics and unmapped units only call for a review.
+func (r AuditReport) OK() bool {
+	return len(r.Missing) == 0
+}
+
+// Write writes the report to w, one section per category.
+func (r AuditReport) Write(w io.Writer) error {
+	for _, section := range []struct {
+		title string
+		names []string
+	}{
+		{"runtime metrics not reported by the library", r.Unsupported},
+		{"metrics referenced by the library missing from the runtime", r.Missing},
+		{"runtime units without a Datadog unit", r.UnmappedUnits},
+	} {
+		if _, err := fmt.Fprintf(w, "%s: %d\n", section.title, len(section.names)); err != nil {
+			return err
+		}
+		for _, name := range section.names {
+			if _, err := fmt.Fprintf(w, "  %s\n", name); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
@@ -0,0 +1,42 @@
+package metadata
+
+import (
+	"runtime/metrics"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestAudit(t *testing.T) {
+	descs := []metrics.Description{
+		{Name: "/gc/heap/live:bytes"},
+		{Name: "/gc/cycles/total:gc-cycles"},
+		{Name: "/test/distance:parsecs"},
+	}
+
+	t.Run("no gaps", func(t *testing.T) {
+		r := Audit(descs[:2], []string{"/gc/heap/live:bytes", "/gc/cycles/total:gc-cycles"}, []string{"/gc/heap/live:bytes"})
+		assert.True(t, r.OK())
+		assert.Equal(t, AuditReport{}, r)
+	})
+
+	t.Run("gaps", func(t *testing.T) {
+		r := Audit(descs, []string{"/gc/heap/live:bytes"}, []string{"/gc/heap/goal:bytes", "/gc/heap/live:bytes"})
+		assert.False(t, r.OK())
+		assert.Equal(t, []string{"/gc/cycles/total:gc-cycles", "/test/distance:parsecs"}, r.Unsupported)
+		assert.Equal(t, []string{"/gc/heap/goal:bytes"}, r.Missing)
+		assert.Equal(t, []string{"parsecs"}, r.UnmappedUnits)
+
+		var b strings.Builder
+		assert.NoError(t, r.Write(&b))
+		assert.Equal(t, `runtime metrics not reported by the library: 2
+  /gc/cycles/total:gc-cycles
+  /test/distance:parsecs
+metrics referenced by the library missing from the runtime: 1
+  /gc/heap/goal:bytes
+runtime units without a Datadog unit: 1
+  parsecs
+`, b.String())
+	})
+}
@@ -0,0 +1,11 @@
+package metadata
+
+// CuratedMetrics maps the Datadog names of the metrics highlighted on the
+// metric summary pages to their curated_metric type. The backend only
+// accepts cpu and memory, so metrics are filed under the closest one.
+var CuratedMetrics = map[string]string{
+	"runtime.go.metrics.gc_heap_live.bytes":               "memory",
+	"runtime.go.metrics.gc_pauses.seconds.p95":            "cpu",
+	"runtime.go.metrics.sched_goroutines.goroutines":      "cpu",
+	"runtime.go.metrics.cpu_classes_gc_total.cpu_seconds": "cpu",
+}
@@ -0,0 +1,23 @@
+package metadata
+
+// OrientationOverrides maps runtime/metrics or Datadog metric names to their
+// orientation: -1 if lower values are better, 1 if higher values are better,
+// and 0 otherwise. Metrics without an override get an orientation from
+// their name, e.g. -1 for pauses and latencies, see tools/metricmetadata.
+var OrientationOverrides = map[string]int{
+	// Contention and runtime overhead on the application's goroutines.
+	"/sync/mutex/wait/total:seconds":           -1,
+	"/cpu/classes/scavenge/assist:cpu-seconds": -1,
+	// Heap memory reserved for objects but not in use, i.e. fragmentation.
+	"/memory/classes/heap/unused:bytes": -1,
+	// The GC only uses CPU time that would otherwise be idle.
+	"/cpu/classes/gc/mark/idle:cpu-seconds": 0,
+	// CPU time available to the application.
+	"/cpu/classes/idle:cpu-seconds": 1,
+
+	"runtime.go.metrics.gc_frequency":         -1,
+	"runtime.go.metrics.derived.gc_thrashing": -1,
+	"runtime.go.metrics.heap_fragmentation":   -1,
+	"runtime.go.metrics.gc_cpu_fraction":      -1,
+	"runtime.go.metrics.skipped_values":       -1,
+}
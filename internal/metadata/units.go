@@ -0,0 +1,78 @@
+// Package metadata holds the metadata of the runtime metrics shared by
+// pkg/runtimemetrics and tools/metricmetadata.
+package metadata
+
+import "strings"
+
+// RuntimeUnitMapping maps runtime/metrics units to Datadog units. Units
+// without a Datadog equivalent are mapped to "".
+var RuntimeUnitMapping = map[string]string{
+	"bytes":       "byte",
+	"seconds":     "second",
+	"objects":     "object",
+	"percent":     "percent",
+	"threads":     "thread",
+	"events":      "event",
+	"cpu-seconds": "",
+	"goroutines":  "",
+	"gc-cycle":    "",
+	"gc-cycles":   "",
+	"calls":       "",
+	"cleanups":    "",
+	"finalizers":  "",
+}
+
+// RuntimeUnit returns the unit of a runtime/metrics name, e.g. bytes for
+// /gc/heap/live:bytes.
+func RuntimeUnit(runtimeName string) string {
+	_, unit, _ := strings.Cut(runtimeName, ":")
+	return unit
+}
+
+// DatadogUnit returns the Datadog unit of the given runtime/metrics unit, and
+// false if the unit is unknown. Known units without a Datadog equivalent
+// return "" and true.
+func DatadogUnit(unit string) (string, bool) {
+	ddUnit, ok := RuntimeUnitMapping[unit]
+	return ddUnit, ok
+}
+
+// Unit is the Datadog unit of a metric. PerUnit is set for rates, e.g.
+// {"byte", "second"} for bytes per second.
+type Unit struct {
+	Name    string
+	PerUnit string
+}
+
+// MetricUnitOverrides maps runtime/metrics or Datadog metric names to their
+// unit, for metrics whose unit can't be derived from a runtime/metrics unit,
+// such as the derived metrics.
+var MetricUnitOverrides = map[string]Unit{
+	"runtime.go.metrics.derived.seconds_since_gc.seconds": {Name: "second"},
+	"runtime.go.metrics.gc_frequency":                     {PerUnit: "second"},
+	"runtime.go.metrics.derived.gc_thrashing":             {},
+	"runtime.go.metrics.gc_heap_utilization":              {Name: "fraction"},
+	"runtime.go.metrics.heap_fragmentation":               {Name: "fraction"},
+	"runtime.go.metrics.gc_cpu_fraction":                  {Name: "fraction"},
+}
+
+// MetricUnit returns the Datadog unit of the runtime/metrics or Datadog
+// metric name, and false if its unit is unknown. Runtime units of the form
+// "x/y" are rates of x per y.
+func MetricUnit(name string) (Unit, bool) {
+	if u, ok := MetricUnitOverrides[name]; ok {
+		return u, true
+	}
+	unit, perUnit, isRate := strings.Cut(RuntimeUnit(name), "/")
+	var u Unit
+	var ok bool
+	if u.Name, ok = DatadogUnit(unit); !ok {
+		return Unit{}, false
+	}
+	if isRate {
+		if u.PerUnit, ok = DatadogUnit(perUnit); !ok {
+			return Unit{}, false
+		}
+	}
+	return u, true
+}
@@ -0,0 +1,47 @@
+package metadata
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestDatadogUnit(t *testing.T) {
+	assert.Equal(t, "bytes", RuntimeUnit("/gc/heap/live:bytes"))
+	assert.Equal(t, "cpu-seconds", RuntimeUnit("/cpu/classes/gc/total:cpu-seconds"))
+
+	unit, ok := DatadogUnit("bytes")
+	assert.True(t, ok)
+	assert.Equal(t, "byte", unit)
+
+	unit, ok = DatadogUnit("goroutines")
+	assert.True(t, ok)
+	assert.Empty(t, unit)
+
+	_, ok = DatadogUnit("parsecs")
+	assert.False(t, ok)
+}
+
+func TestMetricUnit(t *testing.T) {
+	for name, want := range map[string]Unit{
+		"/gc/heap/live:bytes":                    {Name: "byte"},
+		"/sched/goroutines:goroutines":           {},
+		"/test/throughput:bytes/seconds":         {Name: "byte", PerUnit: "second"},
+		"runtime.go.metrics.gc_frequency":        {PerUnit: "second"},
+		"runtime.go.metrics.gc_heap_utilization": {Name: "fraction"},
+	} {
+		got, ok := MetricUnit(name)
+		assert.True(t, ok, name)
+		assert.Equal(t, want, got, name)
+	}
+
+	for _, name := range []string{
+		"/test/distance:parsecs",
+		"/test/speed:parsecs/seconds",
+		"/test/speed:bytes/parsecs",
+		"runtime.go.metrics.unknown",
+	} {
+		_, ok := MetricUnit(name)
+		assert.False(t, ok, name)
+	}
+}
@@ -0,0 +1,6 @@
+// Package openmetricstest checks that the output of
+// runtimemetrics.WriteOpenMetrics is valid OpenMetrics, by parsing it with
+// the OpenMetrics parser of Prometheus. It is a separate module so that the
+// library doesn't depend on Prometheus, and needs a newer Go version than
+// the library supports.
+package openmetricstest
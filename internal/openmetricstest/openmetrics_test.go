@@ -0,0 +1,87 @@
+package openmetricstest
+
+import (
+	"bytes"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/DataDog/go-runtime-metrics-internal/pkg/runtimemetrics"
+	"github.com/prometheus/common/model"
+	"github.com/prometheus/prometheus/model/labels"
+	"github.com/prometheus/prometheus/model/textparse"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// parsed is the output of WriteOpenMetrics, parsed by parse.
+type parsed struct {
+	// types are the types of the metric families by name.
+	types map[string]model.MetricType
+	// series are the values of the samples by series, e.g.
+	// name_bucket{le="+Inf"}.
+	series map[string]float64
+}
+
+// parse parses b with the Prometheus parser for contentType, failing the test
+// on the first error.
+func parse(t *testing.T, b []byte, contentType string) parsed {
+	p, err := textparse.New(b, contentType, labels.NewSymbolTable(), textparse.ParserOptions{})
+	require.NoError(t, err)
+	require.IsType(t, &textparse.OpenMetricsParser{}, p)
+	res := parsed{types: map[string]model.MetricType{}, series: map[string]float64{}}
+	for {
+		entry, err := p.Next()
+		if errors.Is(err, io.EOF) {
+			return res
+		}
+		require.NoError(t, err)
+		switch entry {
+		case textparse.EntryType:
+			name, typ := p.Type()
+			res.types[string(name)] = typ
+		case textparse.EntrySeries:
+			series, _, value := p.Series()
+			res.series[string(series)] = value
+		}
+	}
+}
+
+func TestWriteOpenMetrics(t *testing.T) {
+	var buf bytes.Buffer
+	require.NoError(t, runtimemetrics.WriteOpenMetrics(&buf))
+	out := parse(t, buf.Bytes(), "application/openmetrics-text")
+
+	for _, d := range runtimemetrics.SupportedMetrics() {
+		ddMetricName, err := runtimemetrics.DatadogMetricName(d.Name)
+		require.NoError(t, err)
+		assert.Contains(t, out.types, runtimemetrics.OpenMetricsName(ddMetricName), d.Name)
+	}
+	assert.Equal(t, model.MetricTypeGauge, out.types["runtime_go_metrics_gc_heap_live_bytes"])
+	assert.Equal(t, model.MetricTypeCounter, out.types["runtime_go_metrics_gc_heap_allocs_bytes"])
+	assert.Positive(t, out.series["runtime_go_metrics_gc_heap_allocs_bytes_total"])
+	assert.Equal(t, model.MetricTypeHistogram, out.types["runtime_go_metrics_gc_pauses_seconds"])
+	assert.Contains(t, out.series, `runtime_go_metrics_gc_pauses_seconds_bucket{le="+Inf"}`)
+	assert.Equal(t, out.series[`runtime_go_metrics_gc_pauses_seconds_bucket{le="+Inf"}`], out.series["runtime_go_metrics_gc_pauses_seconds_count"])
+
+}
+
+func TestOpenMetricsHandler(t *testing.T) {
+	e, err := runtimemetrics.NewEmitter(runtimemetrics.NewChannelSink(make(chan runtimemetrics.MetricEvent, 1024)), nil)
+	require.NoError(t, err)
+	defer e.Stop()
+
+	server := httptest.NewServer(e.OpenMetricsHandler())
+	defer server.Close()
+	resp, err := http.Get(server.URL)
+	require.NoError(t, err)
+	defer resp.Body.Close()
+	require.Equal(t, http.StatusOK, resp.StatusCode)
+	body, err := io.ReadAll(resp.Body)
+	require.NoError(t, err)
+
+	out := parse(t, body, resp.Header.Get("Content-Type"))
+	assert.Equal(t, model.MetricTypeGauge, out.types["runtime_go_metrics_gc_heap_live_bytes"])
+}
@@ -0,0 +1,37 @@
+package runtimemetrics
+
+import (
+	"maps"
+	"runtime/metrics"
+	"slices"
+)
+
+// builtinAlias is the Datadog name a renamed runtime metric is reported
+// under once the metric it replaces, its predecessor, is gone.
+type builtinAlias struct {
+	predecessor string
+	name        string
+}
+
+// defaultMetricAliases maps runtime/metrics names to the Datadog name of the
+// metric they were renamed from, so dashboards keep working across Go
+// versions. See Options.MetricAliases.
+var defaultMetricAliases = map[string]builtinAlias{
+	// go1.22 deprecated /gc/pauses:seconds in favor of this identical metric.
+	"/sched/pauses/total/gc:seconds": {predecessor: "/gc/pauses:seconds", name: "runtime.go.metrics.gc_pauses.seconds"},
+}
+
+// metricAliases returns the default aliases overridden by the given ones. A
+// default alias only applies when its predecessor isn't one of descs,
+// otherwise the renamed metric keeps its own name: aliasing it would drop
+// its series in favor of the predecessor's.
+func metricAliases(overrides map[string]string, descs []metrics.Description) map[string]string {
+	aliases := make(map[string]string, len(defaultMetricAliases)+len(overrides))
+	for name, alias := range defaultMetricAliases {
+		if !slices.ContainsFunc(descs, func(d metrics.Description) bool { return d.Name == alias.predecessor }) {
+			aliases[name] = alias.name
+		}
+	}
+	maps.Copy(aliases, overrides)
+	return aliases
+}
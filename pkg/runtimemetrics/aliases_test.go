@@ -0,0 +1,85 @@
+package runtimemetrics
+
+import (
+	"log/slog"
+	"runtime"
+	"runtime/metrics"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestMetricAliases(t *testing.T) {
+	t.Run("should report an aliased metric under its stable name", func(t *testing.T) {
+		mock, _ := reportMetricWithOptions("/gc/heap/goal:bytes", metrics.KindUint64, &Options{
+			Logger:        slog.Default(),
+			MetricAliases: map[string]string{"/gc/heap/goal:bytes": "runtime.go.metrics.heap_goal.bytes"},
+		})
+		require.Len(t, mock.GaugeCalls(), 1)
+		assert.Equal(t, "runtime.go.metrics.heap_goal.bytes", mock.GaugeCalls()[0].Name)
+	})
+
+	t.Run("should report renamed metrics under their historical name once it is gone", func(t *testing.T) {
+		descs := []metrics.Description{metricDesc("/sched/pauses/total/gc:seconds", metrics.KindFloat64Histogram)}
+		mock := &statsdClientMock{}
+		rms := newRuntimeMetricStore(descs, mock, &Options{Logger: slog.Default()})
+		defer rms.close()
+		runtime.GC()
+		rms.report()
+		require.NotEmpty(t, mock.GaugeCalls())
+		assert.Equal(t, "runtime.go.metrics.gc_pauses.seconds.avg", mock.GaugeCalls()[0].Name)
+		require.NotEmpty(t, mock.DistributionCalls())
+		assert.Equal(t, "runtime.go.metrics.gc_pauses.seconds", mock.DistributionCalls()[0].Name)
+	})
+
+	t.Run("should keep the name of renamed metrics while their predecessor exists", func(t *testing.T) {
+		descs := []metrics.Description{
+			metricDesc("/sched/pauses/total/gc:seconds", metrics.KindFloat64Histogram),
+			metricDesc("/gc/pauses:seconds", metrics.KindFloat64Histogram),
+		}
+		rms := newRuntimeMetricStore(descs, &statsdClientMock{}, &Options{Logger: slog.Default()})
+		defer rms.close()
+		require.Len(t, rms.metrics, 2)
+		assert.Equal(t, "runtime.go.metrics.gc_pauses.seconds", rms.metrics["/gc/pauses:seconds"].ddMetricName)
+		assert.Equal(t, "runtime.go.metrics.sched_pauses_total_gc.seconds", rms.metrics["/sched/pauses/total/gc:seconds"].ddMetricName)
+	})
+
+	t.Run("should not report the same name twice", func(t *testing.T) {
+		descs := []metrics.Description{
+			metricDesc("/gc/heap/goal:bytes", metrics.KindUint64),
+			metricDesc("/gc/heap/live:bytes", metrics.KindUint64),
+		}
+		mock := &statsdClientMock{}
+		rms := newRuntimeMetricStore(descs, mock, &Options{
+			Logger:        slog.Default(),
+			MetricAliases: map[string]string{"/gc/heap/goal:bytes": "runtime.go.metrics.gc_heap_live.bytes"},
+		})
+		defer rms.close()
+		require.Len(t, rms.metrics, 1)
+		require.Contains(t, rms.metrics, "/gc/heap/live:bytes")
+
+		rms.report()
+		assert.Len(t, mock.GaugeCalls(), 1)
+	})
+
+	t.Run("should not rename any metric with the default options", func(t *testing.T) {
+		descs := metrics.All()
+		rms := newRuntimeMetricStore(descs, &statsdClientMock{}, &Options{Logger: slog.Default()})
+		defer rms.close()
+		for _, d := range descs {
+			name, err := datadogMetricName(d.Name)
+			if err != nil {
+				continue
+			}
+			require.Contains(t, rms.metrics, d.Name, "should be reported")
+			assert.Equal(t, name, rms.metrics[d.Name].ddMetricName, d.Name)
+		}
+	})
+
+	t.Run("should allow overriding the default aliases", func(t *testing.T) {
+		aliases := metricAliases(map[string]string{"/sched/pauses/total/gc:seconds": "runtime.go.metrics.stw.seconds"}, nil)
+		assert.Equal(t, "runtime.go.metrics.stw.seconds", aliases["/sched/pauses/total/gc:seconds"])
+		assert.Equal(t, "runtime.go.metrics.gc_pauses.seconds", defaultMetricAliases["/sched/pauses/total/gc:seconds"].name)
+	})
+}
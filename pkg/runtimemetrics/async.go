@@ -0,0 +1,172 @@
+package runtimemetrics
+
+import (
+	"errors"
+	"runtime/metrics"
+	"slices"
+	"time"
+)
+
+// asyncQueueSize bounds the number of submissions waiting for the statsd
+// client. A full report is typically a few hundred calls.
+const asyncQueueSize = 1024
+
+var errSubmitTimeout = errors.New("runtimemetrics: submission dropped, the statsd client didn't accept it before the report deadline")
+
+type asyncCallKind uint8
+
+const (
+	asyncGauge asyncCallKind = iota
+	asyncCount
+	asyncDistribution
+	asyncHistogram
+	asyncBarrier
+)
+
+type asyncCall struct {
+	kind      asyncCallKind
+	client    partialStatsdClientInterface
+	name      string
+	value     float64
+	count     int64
+	values    []float64
+	histogram *metrics.Float64Histogram
+	tags      []string
+	rate      float64
+	timestamp time.Time
+	// done is closed by the worker when it processes an asyncBarrier.
+	done chan struct{}
+}
+
+// asyncSubmitter implements partialStatsdClientInterface by queuing calls for
+// a single worker goroutine which forwards them, in order, to the statsd
+// client. This guarantees that a blocking client can't stall a report for
+// longer than the configured timeout: calls that can't be queued before the
+// report's deadline are dropped.
+//
+// Only the worker goroutine calls the statsd client. All other methods must
+// be called from the reporting goroutine.
+type asyncSubmitter struct {
+	queue   chan asyncCall
+	timeout time.Duration
+	stopped chan struct{}
+
+	// set by begin for the duration of a report
+	client   partialStatsdClientInterface
+	deadline time.Time
+	dropped  int
+}
+
+func newAsyncSubmitter(timeout time.Duration) *asyncSubmitter {
+	a := &asyncSubmitter{
+		queue:   make(chan asyncCall, asyncQueueSize),
+		timeout: timeout,
+		stopped: make(chan struct{}),
+	}
+	go a.work()
+	return a
+}
+
+func (a *asyncSubmitter) work() {
+	defer close(a.stopped)
+	for c := range a.queue {
+		switch c.kind {
+		case asyncGauge:
+			c.client.GaugeWithTimestamp(c.name, c.value, c.tags, c.rate, c.timestamp)
+		case asyncCount:
+			c.client.CountWithTimestamp(c.name, c.count, c.tags, c.rate, c.timestamp)
+		case asyncDistribution:
+			c.client.DistributionSamples(c.name, c.values, c.tags, c.rate)
+		case asyncHistogram:
+			c.client.(histogramStatsdClient).HistogramWithTimestamp(c.name, c.histogram, c.tags, c.timestamp)
+		case asyncBarrier:
+			close(c.done)
+		}
+	}
+}
+
+// begin starts a new report submitting to the given client. The deadline is
+// measured in wall clock time, like Options.MaxReportDuration, regardless of
+// the timestamp of the report.
+func (a *asyncSubmitter) begin(client partialStatsdClientInterface) {
+	a.client = client
+	a.deadline = time.Now().Add(a.timeout)
+	a.dropped = 0
+}
+
+// end waits until all calls of the current report have been forwarded to
+// the statsd client, or the report deadline is reached, and returns the number
+// of calls that were dropped.
+func (a *asyncSubmitter) end() int {
+	done := make(chan struct{})
+	if a.enqueue(asyncCall{kind: asyncBarrier, done: done}) != nil {
+		// Don't count the barrier as a dropped submission.
+		a.dropped--
+		return a.dropped
+	}
+	timer := time.NewTimer(time.Until(a.deadline))
+	defer timer.Stop()
+	select {
+	case <-done:
+	case <-timer.C:
+	}
+	return a.dropped
+}
+
+// close stops the worker once all queued calls have been forwarded, waiting
+// at most for the configured timeout.
+func (a *asyncSubmitter) close() {
+	close(a.queue)
+	timer := time.NewTimer(a.timeout)
+	defer timer.Stop()
+	select {
+	case <-a.stopped:
+	case <-timer.C:
+	}
+}
+
+func (a *asyncSubmitter) enqueue(c asyncCall) error {
+	select {
+	case a.queue <- c:
+		return nil
+	default:
+	}
+	if wait := time.Until(a.deadline); wait > 0 {
+		timer := time.NewTimer(wait)
+		defer timer.Stop()
+		select {
+		case a.queue <- c:
+			return nil
+		case <-timer.C:
+		}
+	}
+	a.dropped++
+	return errSubmitTimeout
+}
+
+// GaugeWithTimestamp implements partialStatsdClientInterface.
+func (a *asyncSubmitter) GaugeWithTimestamp(name string, value float64, tags []string, rate float64, timestamp time.Time) error {
+	return a.enqueue(asyncCall{kind: asyncGauge, client: a.client, name: name, value: value, tags: tags, rate: rate, timestamp: timestamp})
+}
+
+// CountWithTimestamp implements partialStatsdClientInterface.
+func (a *asyncSubmitter) CountWithTimestamp(name string, value int64, tags []string, rate float64, timestamp time.Time) error {
+	return a.enqueue(asyncCall{kind: asyncCount, client: a.client, name: name, count: value, tags: tags, rate: rate, timestamp: timestamp})
+}
+
+// DistributionSamples implements partialStatsdClientInterface.
+func (a *asyncSubmitter) DistributionSamples(name string, values []float64, tags []string, rate float64) error {
+	// The caller may reuse values once we return.
+	return a.enqueue(asyncCall{kind: asyncDistribution, client: a.client, name: name, values: slices.Clone(values), tags: tags, rate: rate})
+}
+
+// HistogramWithTimestamp implements histogramStatsdClient, if the statsd
+// client does.
+func (a *asyncSubmitter) HistogramWithTimestamp(name string, h *metrics.Float64Histogram, tags []string, timestamp time.Time) error {
+	if _, ok := a.client.(histogramStatsdClient); !ok {
+		return nil
+	}
+	// The buckets are never modified, only the counts may be reused.
+	h = &metrics.Float64Histogram{Counts: slices.Clone(h.Counts), Buckets: h.Buckets}
+	return a.enqueue(asyncCall{kind: asyncHistogram, client: a.client, name: name, histogram: h, tags: tags, timestamp: timestamp})
+}
@@ -0,0 +1,92 @@
+package runtimemetrics
+
+import (
+	"log/slog"
+	"runtime/metrics"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSubmitTimeout(t *testing.T) {
+	t.Run("a report returns within the deadline when the client blocks", func(t *testing.T) {
+		mock := &blockingStatsdClientMock{unblock: make(chan struct{})}
+		rms := newRuntimeMetricStore(metrics.All(), mock, &Options{Logger: slog.Default(), SubmitTimeout: 50 * time.Millisecond})
+		t.Cleanup(func() {
+			close(mock.unblock)
+			rms.close()
+		})
+
+		start := time.Now()
+		rms.report()
+		assert.Less(t, time.Since(start), time.Second)
+		// At most one call can be stuck in the client, the others are
+		// either dropped or queued.
+		assert.Positive(t, rms.async.dropped+len(rms.async.queue))
+
+		start = time.Now()
+		rms.report()
+		assert.Less(t, time.Since(start), time.Second)
+	})
+
+	t.Run("submissions are forwarded in order", func(t *testing.T) {
+		syncMock, asyncMock := &statsdClientMock{}, &statsdClientMock{}
+		desc := metricDesc("/gc/pauses:seconds", metrics.KindFloat64Histogram)
+		// Both stores read the same values, GC cycles may happen between
+		// real reads.
+		sampler := func() *fakeSampler {
+			return &fakeSampler{steps: []map[string]value{
+				{desc.Name: histogramValue(&metrics.Float64Histogram{Counts: []uint64{0, 0, 0}, Buckets: []float64{0, 1e-6, 1e-3, 1}})},
+				{desc.Name: histogramValue(&metrics.Float64Histogram{Counts: []uint64{2, 3, 1}, Buckets: []float64{0, 1e-6, 1e-3, 1}})},
+			}}
+		}
+		syncStore := newRuntimeMetricStoreWithSampler([]metrics.Description{desc}, sampler(), syncMock, &Options{Logger: slog.Default()})
+		asyncStore := newRuntimeMetricStoreWithSampler([]metrics.Description{desc}, sampler(), asyncMock, &Options{Logger: slog.Default(), SubmitTimeout: time.Minute})
+		t.Cleanup(asyncStore.close)
+
+		syncStore.report()
+		asyncStore.report()
+
+		require.Equal(t, len(syncMock.GaugeCalls()), len(asyncMock.GaugeCalls()))
+		for i := range syncMock.GaugeCalls() {
+			assert.Equal(t, syncMock.GaugeCalls()[i].Name, asyncMock.GaugeCalls()[i].Name)
+		}
+		require.NotEmpty(t, syncMock.DistributionCalls())
+		assert.Equal(t, len(syncMock.DistributionCalls()), len(asyncMock.DistributionCalls()))
+		assert.Zero(t, asyncStore.async.dropped)
+	})
+
+	t.Run("the deadline doesn't depend on the report timestamp", func(t *testing.T) {
+		mock := &statsdClientMock{}
+		// Far from the wall clock, a deadline computed from it would be
+		// long past.
+		now := time.Date(2000, 1, 1, 0, 0, 0, 0, time.UTC)
+		rms := newRuntimeMetricStore(metrics.All(), mock, &Options{
+			Logger:        slog.Default(),
+			SubmitTimeout: time.Minute,
+			clock:         fakeNow(func() time.Time { return now }),
+		})
+		t.Cleanup(rms.close)
+
+		rms.report()
+		assert.Zero(t, rms.async.dropped)
+		// end waited for the worker to forward all the calls.
+		assert.Empty(t, rms.async.queue)
+		assert.NotEmpty(t, mock.GaugeCalls())
+	})
+
+	t.Run("queued submissions are drained on close", func(t *testing.T) {
+		mock := &blockingStatsdClientMock{unblock: make(chan struct{})}
+		a := newAsyncSubmitter(time.Minute)
+		a.begin(mock)
+		for i := 0; i < 10; i++ {
+			require.NoError(t, a.GaugeWithTimestamp("foo", float64(i), nil, 1, time.Now()))
+		}
+		close(mock.unblock)
+		a.close()
+		require.Len(t, mock.GaugeCalls(), 10)
+		assert.Equal(t, 9.0, mock.GaugeCalls()[9].Value)
+	})
+}
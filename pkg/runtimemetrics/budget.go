@@ -0,0 +1,104 @@
+package runtimemetrics
+
+import (
+	"log/slog"
+	"runtime/metrics"
+	"time"
+)
+
+// PeriodForBudget returns the reporting period to configure, see
+// Options.Period, so that the emitter submits about dpm data points per
+// minute with the default options.
+//
+// Each report submits one data point per series: one for each scalar runtime
+// metric, and seven for each histogram (the avg, min, max, median, p95 and
+// p99 gauges, plus the distribution). So the period is:
+//
+//	period = 1 minute * series per report / dpm
+//
+// A non-positive dpm returns the default period, and the period is at least
+// MinPeriod.
+func PeriodForBudget(dpm int) time.Duration {
+	return max(periodForBudget(dpm, seriesPerReport(metrics.All())), MinPeriod)
+}
+
+func periodForBudget(dpm, series int) time.Duration {
+	if dpm <= 0 {
+		return DefaultPeriod
+	}
+	return time.Minute * time.Duration(series) / time.Duration(dpm)
+}
+
+// seriesPerReport returns the number of series submitted by a report of the
+// given metrics with the default options.
+func seriesPerReport(descs []metrics.Description) int {
+	var series int
+	for _, d := range descs {
+		switch d.Kind {
+		case metrics.KindUint64, metrics.KindFloat64:
+			series++
+		case metrics.KindFloat64Histogram:
+			series += len(histogramStatNames) + 1
+		}
+	}
+	return series
+}
+
+// suggestPeriodReports is the number of reports SuggestPeriod measures.
+const suggestPeriodReports = 5
+
+// SuggestPeriod measures the cost of a few reports of the runtime metrics
+// with the default options, submitted to a client discarding them, and
+// returns the shortest period, see Options.Period, keeping the time spent
+// reporting under targetCPUFraction of one CPU, e.g. 0.001 for 0.1%. This
+// helps avoid over-collecting on constrained hardware.
+//
+// The measurement takes a few milliseconds and depends on the load of the
+// machine, so the suggestion is best computed at startup and not relied on
+// for precision. A targetCPUFraction outside of (0, 1] returns the default
+// period, and the period is at least MinPeriod.
+func SuggestPeriod(targetCPUFraction float64) time.Duration {
+	if !(targetCPUFraction > 0 && targetCPUFraction <= 1) {
+		return DefaultPeriod
+	}
+	return max(periodForCost(measureReportCost(suggestPeriodReports), targetCPUFraction), MinPeriod)
+}
+
+// measureReportCost returns the average duration of n reports of all the
+// runtime metrics.
+func measureReportCost(n int) time.Duration {
+	rms := newRuntimeMetricStore(metrics.All(), discardClient{}, &Options{Logger: slog.Default()})
+	defer rms.close()
+	start := time.Now()
+	for i := 0; i < n; i++ {
+		rms.report()
+	}
+	return time.Since(start) / time.Duration(n)
+}
+
+// periodForCost returns the period at which reports costing cost use
+// targetCPUFraction of a CPU, rounded up to the millisecond.
+func periodForCost(cost time.Duration, targetCPUFraction float64) time.Duration {
+	period := time.Duration(float64(cost) / targetCPUFraction)
+	if rem := period % time.Millisecond; rem != 0 {
+		period += time.Millisecond - rem
+	}
+	return max(period, time.Millisecond)
+}
+
+// discardClient is a statsd client discarding all submissions, see
+// SuggestPeriod. Unlike the no-op clients detected by isNoOpClient, reports
+// to it collect the metrics.
+type discardClient struct{}
+
+func (discardClient) GaugeWithTimestamp(string, float64, []string, float64, time.Time) error {
+	return nil
+}
+
+func (discardClient) CountWithTimestamp(string, int64, []string, float64, time.Time) error {
+	return nil
+}
+
+func (discardClient) DistributionSamples(string, []float64, []string, float64) error {
+	return nil
+}
@@ -0,0 +1,63 @@
+package runtimemetrics
+
+import (
+	"math"
+	"runtime/metrics"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestPeriodForBudget(t *testing.T) {
+	t.Run("should compute the period for a given budget and series count", func(t *testing.T) {
+		assert.Equal(t, 10*time.Second, periodForBudget(600, 100))
+		assert.Equal(t, time.Minute, periodForBudget(100, 100))
+		assert.Equal(t, 30*time.Second, periodForBudget(200, 100))
+	})
+
+	t.Run("should return the default period for an invalid budget", func(t *testing.T) {
+		assert.Equal(t, DefaultPeriod, periodForBudget(0, 100))
+		assert.Equal(t, DefaultPeriod, periodForBudget(-1, 100))
+	})
+
+	t.Run("should count seven series per histogram", func(t *testing.T) {
+		descs := []metrics.Description{
+			{Name: "/a:bytes", Kind: metrics.KindUint64},
+			{Name: "/b:seconds", Kind: metrics.KindFloat64},
+			{Name: "/c:seconds", Kind: metrics.KindFloat64Histogram},
+		}
+		assert.Equal(t, 9, seriesPerReport(descs))
+	})
+
+	t.Run("should use all runtime metrics", func(t *testing.T) {
+		series := seriesPerReport(metrics.All())
+		assert.Equal(t, periodForBudget(1000, series), PeriodForBudget(1000))
+	})
+}
+
+func TestSuggestPeriod(t *testing.T) {
+	t.Run("should scale with the measured cost", func(t *testing.T) {
+		assert.Equal(t, time.Second, periodForCost(time.Millisecond, 0.001))
+		assert.Equal(t, 2*time.Second, periodForCost(2*time.Millisecond, 0.001))
+		assert.Equal(t, 4*time.Second, periodForCost(2*time.Millisecond, 0.0005))
+	})
+
+	t.Run("should round up to the millisecond", func(t *testing.T) {
+		assert.Equal(t, 2*time.Millisecond, periodForCost(1500*time.Microsecond, 1))
+		assert.Equal(t, time.Millisecond, periodForCost(0, 0.5))
+	})
+
+	t.Run("should return the default period for an invalid target", func(t *testing.T) {
+		for _, target := range []float64{0, -0.1, 1.5, math.NaN()} {
+			assert.Equal(t, DefaultPeriod, SuggestPeriod(target), target)
+		}
+	})
+
+	t.Run("should measure the reports", func(t *testing.T) {
+		assert.Positive(t, measureReportCost(2))
+		period := SuggestPeriod(0.01)
+		assert.Positive(t, period)
+		assert.Zero(t, period%time.Millisecond)
+	})
+}
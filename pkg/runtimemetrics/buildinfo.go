@@ -0,0 +1,28 @@
+package runtimemetrics
+
+import "runtime/debug"
+
+const buildInfoMetricName = "runtime.go.metrics.build_info"
+
+// readBuildInfo is a variable so tests can simulate binaries with and without
+// build info.
+var readBuildInfo = debug.ReadBuildInfo
+
+// buildInfoTags returns the tags attached to the build_info metric. Settings
+// that are missing from the build info (e.g. binaries built outside of a VCS
+// checkout or with -buildvcs=false) are omitted rather than reported empty.
+func buildInfoTags(bi *debug.BuildInfo) []string {
+	tags := make([]string, 0, 3)
+	for _, s := range bi.Settings {
+		switch s.Key {
+		case "vcs.revision":
+			tags = append(tags, "vcs_revision:"+s.Value)
+		case "vcs.time":
+			tags = append(tags, "vcs_time:"+s.Value)
+		}
+	}
+	if bi.GoVersion != "" {
+		tags = append(tags, "go_version:"+bi.GoVersion)
+	}
+	return tags
+}
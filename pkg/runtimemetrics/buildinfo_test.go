@@ -0,0 +1,67 @@
+package runtimemetrics
+
+import (
+	"log/slog"
+	"runtime/debug"
+	"runtime/metrics"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestBuildInfo(t *testing.T) {
+	setBuildInfo := func(t *testing.T, bi *debug.BuildInfo, ok bool) {
+		old := readBuildInfo
+		readBuildInfo = func() (*debug.BuildInfo, bool) { return bi, ok }
+		t.Cleanup(func() { readBuildInfo = old })
+	}
+
+	t.Run("should report build_info with vcs and go version tags", func(t *testing.T) {
+		setBuildInfo(t, &debug.BuildInfo{
+			GoVersion: "go1.22.3",
+			Settings: []debug.BuildSetting{
+				{Key: "-compiler", Value: "gc"},
+				{Key: "vcs.revision", Value: "830ed3f"},
+				{Key: "vcs.time", Value: "2024-05-01T12:00:00Z"},
+			},
+		}, true)
+
+		mock := &statsdClientMock{}
+		rms := newRuntimeMetricStore([]metrics.Description{}, mock, &Options{Logger: slog.Default(), BuildInfo: true})
+		rms.report()
+
+		require.Len(t, mock.GaugeCalls(), 1)
+		call := mock.GaugeCalls()[0]
+		assert.Equal(t, "runtime.go.metrics.build_info", call.Name)
+		assert.Equal(t, 1.0, call.Value)
+		assertTagValue(t, "vcs_revision", "830ed3f", call.Tags)
+		assertTagValue(t, "vcs_time", "2024-05-01T12:00:00Z", call.Tags)
+		assertTagValue(t, "go_version", "go1.22.3", call.Tags)
+		// base tags are attached as well
+		assert.Subset(t, call.Tags, rms.baseTags)
+	})
+
+	t.Run("should not report build_info when build info is unavailable", func(t *testing.T) {
+		setBuildInfo(t, nil, false)
+
+		mock := &statsdClientMock{}
+		rms := newRuntimeMetricStore([]metrics.Description{}, mock, &Options{Logger: slog.Default(), BuildInfo: true})
+		rms.report()
+
+		assert.Empty(t, mock.GaugeCalls())
+	})
+
+	t.Run("should not report build_info when the option is disabled", func(t *testing.T) {
+		mock := &statsdClientMock{}
+		rms := newRuntimeMetricStore([]metrics.Description{}, mock, &Options{Logger: slog.Default()})
+		rms.report()
+
+		assert.Empty(t, mock.GaugeCalls())
+	})
+
+	t.Run("should omit missing vcs settings", func(t *testing.T) {
+		tags := buildInfoTags(&debug.BuildInfo{GoVersion: "go1.22.3"})
+		assert.Equal(t, []string{"go_version:go1.22.3"}, tags)
+	})
+}
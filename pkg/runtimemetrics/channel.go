@@ -0,0 +1,112 @@
+package runtimemetrics
+
+import (
+	"errors"
+	"runtime/metrics"
+	"slices"
+	"sync/atomic"
+	"time"
+)
+
+// MetricKind is the kind of a MetricEvent.
+type MetricKind int
+
+const (
+	// GaugeMetric is a gauge, its value is in MetricEvent.Value.
+	GaugeMetric MetricKind = iota
+	// CountMetric is a count, its value is in MetricEvent.Value.
+	CountMetric
+	// DistributionMetric is a set of distribution samples, their values are
+	// in MetricEvent.Values.
+	DistributionMetric
+	// HistogramMetric is a runtime histogram, in MetricEvent.Histogram. Only
+	// a Sink receives them, see Sink.Histogram.
+	HistogramMetric
+)
+
+// String returns the name of the kind.
+func (k MetricKind) String() string {
+	switch k {
+	case GaugeMetric:
+		return "gauge"
+	case CountMetric:
+		return "count"
+	case DistributionMetric:
+		return "distribution"
+	case HistogramMetric:
+		return "histogram"
+	default:
+		return "unknown"
+	}
+}
+
+// MetricEvent is a metric submitted by the emitter, as sent by ChannelSink,
+// received by a Sink, returned by Collect and retained in Report.Samples.
+type MetricEvent struct {
+	Kind   MetricKind
+	Name   string
+	Value  float64
+	Values []float64
+	// Histogram is the runtime histogram of histograms, with the values
+	// recorded since the previous report for cumulative histograms.
+	Histogram *metrics.Float64Histogram
+	// Tags are owned by the receiver, except for the events of a Sink.
+	Tags []string
+	Rate float64
+	// Timestamp is zero for distributions, which are timestamped on receipt
+	// by statsd.
+	Timestamp time.Time
+}
+
+// errChannelFull is returned by ChannelSink when an event is dropped.
+var errChannelFull = errors.New("runtimemetrics: channel sink is full, dropped metric event")
+
+// ChannelSink is a statsd client replacement that sends metrics as
+// MetricEvent values to a channel, so that applications can process them
+// programmatically.
+//
+// Sends never block: an event is dropped if the channel is full, so that a
+// slow consumer can't stall reports. Use a buffered channel large enough to
+// hold a whole report, a few hundred events, and see Dropped.
+type ChannelSink struct {
+	ch      chan<- MetricEvent
+	dropped atomic.Int64
+}
+
+// NewChannelSink returns a ChannelSink sending to ch. It can be passed to
+// NewEmitter instead of a statsd client. ch is never closed.
+func NewChannelSink(ch chan<- MetricEvent) *ChannelSink {
+	return &ChannelSink{ch: ch}
+}
+
+// GaugeWithTimestamp sends a GaugeMetric event.
+func (s *ChannelSink) GaugeWithTimestamp(name string, value float64, tags []string, rate float64, timestamp time.Time) error {
+	return s.send(MetricEvent{Kind: GaugeMetric, Name: name, Value: value, Tags: tags, Rate: rate, Timestamp: timestamp})
+}
+
+// CountWithTimestamp sends a CountMetric event.
+func (s *ChannelSink) CountWithTimestamp(name string, value int64, tags []string, rate float64, timestamp time.Time) error {
+	return s.send(MetricEvent{Kind: CountMetric, Name: name, Value: float64(value), Tags: tags, Rate: rate, Timestamp: timestamp})
+}
+
+// DistributionSamples sends a DistributionMetric event.
+func (s *ChannelSink) DistributionSamples(name string, values []float64, tags []string, rate float64) error {
+	return s.send(MetricEvent{Kind: DistributionMetric, Name: name, Values: slices.Clone(values), Tags: tags, Rate: rate})
+}
+
+// Dropped returns the number of events dropped because the channel was full.
+func (s *ChannelSink) Dropped() int64 {
+	return s.dropped.Load()
+}
+
+func (s *ChannelSink) send(e MetricEvent) error {
+	// The emitter shares tag slices across metrics.
+	e.Tags = slices.Clone(e.Tags)
+	select {
+	case s.ch <- e:
+		return nil
+	default:
+		s.dropped.Add(1)
+		return errChannelFull
+	}
+}
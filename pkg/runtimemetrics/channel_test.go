@@ -0,0 +1,54 @@
+package runtimemetrics
+
+import (
+	"log/slog"
+	"runtime/debug"
+	"runtime/metrics"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestChannelSink(t *testing.T) {
+	t.Run("should send the metrics of a report", func(t *testing.T) {
+		old := debug.SetGCPercent(123)
+		defer debug.SetGCPercent(old)
+
+		ch := make(chan MetricEvent, 16)
+		desc := metricDesc("/gc/gogc:percent", metrics.KindUint64)
+		rms := newRuntimeMetricStore([]metrics.Description{desc}, NewChannelSink(ch), &Options{Logger: slog.Default()})
+		rms.report()
+
+		require.Len(t, ch, 1)
+		e := <-ch
+		assert.Equal(t, GaugeMetric, e.Kind)
+		assert.Equal(t, "runtime.go.metrics.gc_gogc.percent", e.Name)
+		assert.Equal(t, 123.0, e.Value)
+		assert.Contains(t, e.Tags, "gogc:123")
+		assert.False(t, e.Timestamp.IsZero())
+	})
+
+	t.Run("should send each kind", func(t *testing.T) {
+		ch := make(chan MetricEvent, 3)
+		s := NewChannelSink(ch)
+		ts := time.Unix(1700000000, 0)
+		require.NoError(t, s.CountWithTimestamp("count", 2, nil, 1, ts))
+		values := []float64{1, 2}
+		require.NoError(t, s.DistributionSamples("dist", values, nil, 0.5))
+		values[0] = 42
+
+		assert.Equal(t, MetricEvent{Kind: CountMetric, Name: "count", Value: 2, Rate: 1, Timestamp: ts}, <-ch)
+		assert.Equal(t, MetricEvent{Kind: DistributionMetric, Name: "dist", Values: []float64{1, 2}, Rate: 0.5}, <-ch)
+	})
+
+	t.Run("should drop events when the channel is full", func(t *testing.T) {
+		ch := make(chan MetricEvent, 1)
+		s := NewChannelSink(ch)
+		assert.NoError(t, s.GaugeWithTimestamp("a", 1, nil, 1, time.Now()))
+		assert.Error(t, s.GaugeWithTimestamp("b", 1, nil, 1, time.Now()))
+		assert.Equal(t, int64(1), s.Dropped())
+		assert.Equal(t, "a", (<-ch).Name)
+	})
+}
@@ -0,0 +1,35 @@
+//go:build go1.25
+
+// synctest requires the synchronous timer channels of go1.23, which modules
+// declaring an older Go version don't get by default.
+//go:debug asynctimerchan=0
+
+package runtimemetrics
+
+import (
+	"testing"
+	"testing/synctest"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// TestEmitterSynctest checks that the real clock works in synctest bubbles,
+// so that users can test code starting an emitter with testing/synctest.
+func TestEmitterSynctest(t *testing.T) {
+	synctest.Test(t, func(t *testing.T) {
+		mock := &statsdClientMock{}
+		e, err := NewEmitter(mock, &Options{Period: 10 * time.Second})
+		require.NoError(t, err)
+		start := time.Now()
+
+		time.Sleep(10 * time.Second)
+		synctest.Wait()
+		calls := mock.GaugeCalls()
+		require.NotEmpty(t, calls)
+		assert.Equal(t, start.Add(10*time.Second), calls[0].Timestamp)
+
+		e.Stop()
+	})
+}
@@ -0,0 +1,127 @@
+package runtimemetrics
+
+import (
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// fakeClock is a clock that only moves when told to. Its tickers deliver each
+// tick synchronously, see Advance.
+type fakeClock struct {
+	mu      sync.Mutex
+	now     time.Time
+	tickers []*fakeTicker
+	// tickerCreated receives a value each time a ticker is created.
+	tickerCreated chan struct{}
+}
+
+func newFakeClock() *fakeClock {
+	return &fakeClock{
+		now:           time.Date(2024, 5, 1, 12, 0, 0, 0, time.UTC),
+		tickerCreated: make(chan struct{}, 16),
+	}
+}
+
+func (c *fakeClock) Now() time.Time {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.now
+}
+
+func (c *fakeClock) NewTicker(d time.Duration) ticker {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	t := &fakeTicker{
+		c:       make(chan time.Time),
+		stopped: make(chan struct{}),
+		period:  d,
+		next:    c.now.Add(d),
+	}
+	c.tickers = append(c.tickers, t)
+	c.tickerCreated <- struct{}{}
+	return t
+}
+
+// Advance moves the clock forward by d, delivering the ticks that are due
+// along the way. It returns once the last tick has been received, use
+// Options.OnReport to wait for the report it triggered. Tests should advance
+// by at most one period at a time, otherwise the clock moves while a report
+// is in progress. Ticks are dropped for stopped tickers.
+func (c *fakeClock) Advance(d time.Duration) {
+	c.mu.Lock()
+	end := c.now.Add(d)
+	c.mu.Unlock()
+	for {
+		c.mu.Lock()
+		var due *fakeTicker
+		for _, t := range c.tickers {
+			if !t.next.After(end) && (due == nil || t.next.Before(due.next)) {
+				due = t
+			}
+		}
+		if due == nil {
+			c.now = end
+			c.mu.Unlock()
+			return
+		}
+		c.now = due.next
+		due.next = due.next.Add(due.period)
+		now := c.now
+		c.mu.Unlock()
+
+		select {
+		case due.c <- now:
+		case <-due.stopped:
+		}
+	}
+}
+
+type fakeTicker struct {
+	c        chan time.Time
+	stopped  chan struct{}
+	stopOnce sync.Once
+	period   time.Duration
+	next     time.Time
+}
+
+func (t *fakeTicker) C() <-chan time.Time { return t.c }
+
+func (t *fakeTicker) Stop() { t.stopOnce.Do(func() { close(t.stopped) }) }
+
+// startFakeEmitter starts an emitter driven by a fakeClock. Reports are sent
+// to the returned channel once complete.
+func startFakeEmitter(t *testing.T, client partialStatsdClientInterface, opts Options) (*Emitter, *fakeClock, <-chan ReportStats) {
+	t.Helper()
+	clock := newFakeClock()
+	reports := make(chan ReportStats, 1)
+	opts.clock = clock
+	opts.OnReport = func(s ReportStats) { reports <- s }
+	e, err := NewEmitter(client, &opts)
+	require.NoError(t, err)
+	t.Cleanup(e.Stop)
+	<-clock.tickerCreated
+	return e, clock, reports
+}
+
+func TestEmitterClock(t *testing.T) {
+	mock := &statsdClientMock{}
+	_, clock, reports := startFakeEmitter(t, mock, Options{Period: 10 * time.Second})
+	start := clock.Now()
+
+	clock.Advance(9 * time.Second)
+	assert.Empty(t, mock.GaugeCalls(), "should not report before the first tick")
+
+	clock.Advance(time.Second)
+	stats := <-reports
+	assert.Equal(t, start.Add(10*time.Second), stats.Timestamp)
+	calls := mock.GaugeCalls()
+	require.NotEmpty(t, calls)
+	assert.Equal(t, stats.Timestamp, calls[0].Timestamp)
+
+	clock.Advance(10 * time.Second)
+	assert.Equal(t, start.Add(20*time.Second), (<-reports).Timestamp)
+}
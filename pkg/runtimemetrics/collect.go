@@ -0,0 +1,54 @@
+package runtimemetrics
+
+import (
+	"errors"
+	"log/slog"
+	"runtime/metrics"
+	"slices"
+	"time"
+)
+
+// Collect reads the runtime metrics once and returns them under their
+// Datadog names with the default options, e.g. for command-line dumps,
+// without an emitter or a statsd client. It can be called while an emitter
+// is running.
+//
+// There is no previous read to compute deltas from, so histograms cover all
+// the values recorded since the process started. It returns an error if no
+// metric could be read.
+func Collect() ([]MetricEvent, error) {
+	var c sampleCollector
+	rms := newRuntimeMetricStore(metrics.All(), &c, &Options{Logger: slog.Default()})
+	defer rms.close()
+	// Forget the values read by the constructor, so that the report doesn't
+	// subtract them from the histograms.
+	for _, rm := range rms.metrics {
+		rm.currentValue = value{}
+	}
+	rms.report()
+	if len(c.samples) == 0 {
+		return nil, errors.New("runtimemetrics: no runtime metric could be collected")
+	}
+	return c.samples, nil
+}
+
+// sampleCollector is a statsd client replacement accumulating the submitted
+// metrics, see Collect.
+type sampleCollector struct {
+	samples []MetricEvent
+}
+
+func (c *sampleCollector) GaugeWithTimestamp(name string, value float64, tags []string, rate float64, timestamp time.Time) error {
+	c.samples = append(c.samples, MetricEvent{Kind: GaugeMetric, Name: name, Value: value, Tags: slices.Clone(tags), Rate: rate, Timestamp: timestamp})
+	return nil
+}
+
+func (c *sampleCollector) CountWithTimestamp(name string, value int64, tags []string, rate float64, timestamp time.Time) error {
+	c.samples = append(c.samples, MetricEvent{Kind: CountMetric, Name: name, Value: float64(value), Tags: slices.Clone(tags), Rate: rate, Timestamp: timestamp})
+	return nil
+}
+
+func (c *sampleCollector) DistributionSamples(name string, values []float64, tags []string, rate float64) error {
+	c.samples = append(c.samples, MetricEvent{Kind: DistributionMetric, Name: name, Values: slices.Clone(values), Tags: slices.Clone(tags), Rate: rate})
+	return nil
+}
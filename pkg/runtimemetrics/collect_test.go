@@ -0,0 +1,40 @@
+package runtimemetrics
+
+import (
+	"runtime"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCollect(t *testing.T) {
+	runtime.GC()
+
+	samples, err := Collect()
+	require.NoError(t, err)
+	require.NotEmpty(t, samples)
+
+	byName := map[string]MetricEvent{}
+	for _, s := range samples {
+		byName[s.Name] = s
+	}
+	enabled, ok := byName["runtime.go.metrics.gc_limiter_last_enabled.gc_cycle"]
+	require.True(t, ok)
+	assert.Equal(t, GaugeMetric, enabled.Kind)
+	assert.Contains(t, byName, "runtime.go.metrics.gc_pauses.seconds.p99")
+	assert.Contains(t, byName, "runtime.go.metrics.gc_pauses.seconds")
+
+	t.Run("cumulative metrics are absolute values", func(t *testing.T) {
+		cycles := byName["runtime.go.metrics.gc_cycles_total.gc_cycles"]
+		assert.GreaterOrEqual(t, cycles.Value, 1.0)
+	})
+
+	t.Run("can be called while an emitter is running", func(t *testing.T) {
+		e, err := NewEmitter(&statsdClientMock{}, nil)
+		require.NoError(t, err)
+		defer e.Stop()
+		_, err = Collect()
+		assert.NoError(t, err)
+	})
+}
@@ -0,0 +1,41 @@
+package runtimemetrics
+
+import (
+	"bufio"
+	"os"
+	"regexp"
+	"strings"
+)
+
+// cgroupPath is the file the container ID is read from, see
+// Options.ContainerIDTag. It is only changed by tests.
+var cgroupPath = "/proc/self/cgroup"
+
+// containerIDRegex matches the container ID at the end of a cgroup path:
+// Docker and containerd IDs, UUIDs of systemd scopes, and ECS Fargate task
+// IDs, e.g. /kubepods/burstable/pod<uid>/<id> or
+// /system.slice/docker-<id>.scope.
+var containerIDRegex = regexp.MustCompile(`([0-9a-f]{64}|[0-9a-f]{8}[-_][0-9a-f]{4}[-_][0-9a-f]{4}[-_][0-9a-f]{4}[-_][0-9a-f]{12}|[0-9a-f]{32}-\d+)(?:\.scope)?$`)
+
+// readContainerID returns the ID of the container the process runs in,
+// parsed from the cgroup file at path, or "" if it can't be found, e.g.
+// outside of a container or on other operating systems than Linux.
+func readContainerID(path string) string {
+	f, err := os.Open(path)
+	if err != nil {
+		return ""
+	}
+	defer f.Close()
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		// Lines are hierarchy-ID:controllers:path.
+		parts := strings.SplitN(scanner.Text(), ":", 3)
+		if len(parts) != 3 {
+			continue
+		}
+		if m := containerIDRegex.FindStringSubmatch(parts[2]); m != nil {
+			return m[1]
+		}
+	}
+	return ""
+}
@@ -0,0 +1,82 @@
+package runtimemetrics
+
+import (
+	"log/slog"
+	"os"
+	"path/filepath"
+	"runtime/metrics"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestReadContainerID(t *testing.T) {
+	for _, tt := range []struct {
+		name   string
+		cgroup string
+		want   string
+	}{
+		{
+			name: "docker",
+			cgroup: "13:name=systemd:/docker/3726184226f5d3147c25fdeab5b60097e378e8a720503a5e19ecfdf29f869860\n" +
+				"12:pids:/docker/3726184226f5d3147c25fdeab5b60097e378e8a720503a5e19ecfdf29f869860\n",
+			want: "3726184226f5d3147c25fdeab5b60097e378e8a720503a5e19ecfdf29f869860",
+		},
+		{
+			name:   "kubernetes",
+			cgroup: "11:perf_event:/kubepods/besteffort/pod3d274242-8ee0-11e9-a8a6-1e68d864ef1a/3e74d3fd9db4c9dd921ae05c2502fb984d0cde1b36e581b13f79c639da4518a1\n",
+			want:   "3e74d3fd9db4c9dd921ae05c2502fb984d0cde1b36e581b13f79c639da4518a1",
+		},
+		{
+			name:   "systemd scope",
+			cgroup: "0::/system.slice/docker-34dc0b5e626f2c5c4c5170e34b10e7654ce36f0fcd532739f4445baabea03376.scope\n",
+			want:   "34dc0b5e626f2c5c4c5170e34b10e7654ce36f0fcd532739f4445baabea03376",
+		},
+		{
+			name:   "ecs fargate",
+			cgroup: "1:name=systemd:/ecs/34dc0b5e626f2c5c4c5170e34b10e765-1234567890\n",
+			want:   "34dc0b5e626f2c5c4c5170e34b10e765-1234567890",
+		},
+		{
+			name:   "not in a container",
+			cgroup: "0::/user.slice/user-1000.slice/session-3.scope\n",
+			want:   "",
+		},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			path := filepath.Join(t.TempDir(), "cgroup")
+			require.NoError(t, os.WriteFile(path, []byte(tt.cgroup), 0o644))
+			assert.Equal(t, tt.want, readContainerID(path))
+		})
+	}
+
+	t.Run("missing file", func(t *testing.T) {
+		assert.Empty(t, readContainerID(filepath.Join(t.TempDir(), "cgroup")))
+	})
+}
+
+func TestContainerIDTag(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "cgroup")
+	require.NoError(t, os.WriteFile(path, []byte("12:pids:/docker/3726184226f5d3147c25fdeab5b60097e378e8a720503a5e19ecfdf29f869860\n"), 0o644))
+	old := cgroupPath
+	cgroupPath = path
+	defer func() { cgroupPath = old }()
+
+	t.Run("should be attached to all metrics", func(t *testing.T) {
+		mock := &statsdClientMock{}
+		desc := metricDesc("/gc/heap/live:bytes", metrics.KindUint64)
+		rms := newRuntimeMetricStore([]metrics.Description{desc}, mock, &Options{Logger: slog.Default(), ContainerIDTag: true})
+		rms.report()
+		require.NotEmpty(t, mock.GaugeCalls())
+		assert.Contains(t, mock.GaugeCalls()[0].Tags, "container_id:3726184226f5d3147c25fdeab5b60097e378e8a720503a5e19ecfdf29f869860")
+	})
+
+	t.Run("should be skipped without a container ID", func(t *testing.T) {
+		cgroupPath = filepath.Join(t.TempDir(), "missing")
+		rms := newRuntimeMetricStore(nil, nil, &Options{Logger: slog.Default(), ContainerIDTag: true})
+		for _, tag := range rms.baseTags {
+			assert.NotContains(t, tag, "container_id:")
+		}
+	})
+}
@@ -0,0 +1,287 @@
+package runtimemetrics
+
+import (
+	"log/slog"
+	"runtime/metrics"
+	"slices"
+	"time"
+)
+
+// Derived metrics are computed from the runtime/metrics collected by the
+// store, rather than read from the runtime directly, see
+// Options.DerivedMetrics.
+
+const (
+	gcCyclesMetricName = "/gc/cycles/total:gc-cycles"
+	gcCPUMetricName    = "/cpu/classes/gc/total:cpu-seconds"
+	totalCPUMetricName = "/cpu/classes/total:cpu-seconds"
+	heapLiveMetricName = "/gc/heap/live:bytes"
+	heapGoalMetricName = "/gc/heap/goal:bytes"
+
+	heapFreeMetricName     = "/memory/classes/heap/free:bytes"
+	heapReleasedMetricName = "/memory/classes/heap/released:bytes"
+	heapObjectsMetricName  = "/memory/classes/heap/objects:bytes"
+)
+
+const (
+	secondsSinceGCMetricName    = "runtime.go.metrics.derived.seconds_since_gc.seconds"
+	gcFrequencyMetricName       = "runtime.go.metrics.gc_frequency"
+	gcThrashingMetricName       = "runtime.go.metrics.derived.gc_thrashing"
+	gcHeapUtilizationMetricName = "runtime.go.metrics.gc_heap_utilization"
+	heapFragmentationMetricName = "runtime.go.metrics.heap_fragmentation"
+	gcCPUFractionMetricName     = "runtime.go.metrics.gc_cpu_fraction"
+)
+
+// DerivedMetric describes a gauge reported with Options.DerivedMetrics.
+type DerivedMetric struct {
+	// Name is the Datadog name of the gauge.
+	Name        string
+	Description string
+	// Inputs are the runtime/metrics the gauge is computed from. It is only
+	// reported if all of them are collected.
+	Inputs []string
+}
+
+var derivedMetricDescriptions = []DerivedMetric{
+	{
+		Name:        secondsSinceGCMetricName,
+		Description: "Time since the last report that observed a GC cycle. It isn't reported until a GC cycle has been observed.",
+		Inputs:      []string{gcCyclesMetricName},
+	},
+	{
+		Name:        gcFrequencyMetricName,
+		Description: "Number of GC cycles per second over the last reporting period.",
+		Inputs:      []string{gcCyclesMetricName},
+	},
+	{
+		Name:        gcThrashingMetricName,
+		Description: "1 once the fraction of CPU time used by the GC exceeded the thrashing threshold for several consecutive periods, 0 otherwise.",
+		Inputs:      []string{gcCPUMetricName, totalCPUMetricName},
+	},
+	{
+		Name:        gcHeapUtilizationMetricName,
+		Description: "Ratio of the live heap to the heap goal, i.e. how close the heap is to triggering the next GC cycle.",
+		Inputs:      []string{heapLiveMetricName, heapGoalMetricName},
+	},
+	{
+		Name:        heapFragmentationMetricName,
+		Description: "Fraction of the heap that is free but not released to the OS: free / (free + released + objects).",
+		Inputs:      []string{heapFreeMetricName, heapReleasedMetricName, heapObjectsMetricName},
+	},
+	{
+		Name:        gcCPUFractionMetricName,
+		Description: "Fraction of the CPU time used by the GC over the last reporting period.",
+		Inputs:      []string{gcCPUMetricName, totalCPUMetricName},
+	},
+}
+
+// DerivedMetrics returns the descriptions of the gauges reported with
+// Options.DerivedMetrics.
+func DerivedMetrics() []DerivedMetric {
+	res := slices.Clone(derivedMetricDescriptions)
+	for i := range res {
+		res[i].Inputs = slices.Clone(res[i].Inputs)
+	}
+	return res
+}
+
+const (
+	// DefaultGCThrashingThreshold is the default value of
+	// Options.GCThrashingThreshold.
+	DefaultGCThrashingThreshold = 0.25
+	// DefaultGCThrashingPeriods is the default value of
+	// Options.GCThrashingPeriods.
+	DefaultGCThrashingPeriods = 3
+)
+
+type derivedMetrics struct {
+	// store are the collected runtime metrics.
+	store map[string]*runtimeMetric
+
+	// gcCycles is nil if /gc/cycles/total:gc-cycles isn't collected.
+	gcCycles *runtimeMetric
+	// lastGC is the timestamp of the first report that observed the last
+	// increase of the GC cycle count. It is zero until an increase has been
+	// observed, so that we don't report the time since the process started.
+	lastGC time.Time
+
+	// gcCPU and totalCPU are nil if their runtime metric isn't collected.
+	gcCPU, totalCPU *runtimeMetric
+	gcThrashing     gcThrashingDetector
+
+	// heapLive and heapGoal are nil if their runtime metric isn't collected.
+	heapLive, heapGoal *runtimeMetric
+
+	// heapFree, heapReleased and heapObjects are nil if their runtime metric
+	// isn't collected.
+	heapFree, heapReleased, heapObjects *runtimeMetric
+}
+
+func newDerivedMetrics(store map[string]*runtimeMetric, opts *Options) *derivedMetrics {
+	d := &derivedMetrics{
+		store:    store,
+		gcCycles: store[gcCyclesMetricName],
+		gcCPU:    store[gcCPUMetricName],
+		totalCPU: store[totalCPUMetricName],
+		heapLive: store[heapLiveMetricName],
+		heapGoal: store[heapGoalMetricName],
+
+		heapFree:     store[heapFreeMetricName],
+		heapReleased: store[heapReleasedMetricName],
+		heapObjects:  store[heapObjectsMetricName],
+		gcThrashing: gcThrashingDetector{
+			threshold: opts.GCThrashingThreshold,
+			periods:   opts.GCThrashingPeriods,
+		},
+	}
+	if d.gcThrashing.threshold <= 0 {
+		d.gcThrashing.threshold = DefaultGCThrashingThreshold
+	}
+	if d.gcThrashing.periods <= 0 {
+		d.gcThrashing.periods = DefaultGCThrashingPeriods
+	}
+	return d
+}
+
+// names returns the names of the gauges that can be reported, given the
+// collected runtime metrics.
+func (d *derivedMetrics) names() []string {
+	var names []string
+	for _, m := range derivedMetricDescriptions {
+		collected := true
+		for _, in := range m.Inputs {
+			_, ok := d.store[in]
+			collected = collected && ok
+		}
+		if collected {
+			names = append(names, m.Name)
+		}
+	}
+	return names
+}
+
+// reset forgets the state accumulated across reports, e.g. when reports are
+// paused.
+func (d *derivedMetrics) reset() {
+	d.gcThrashing.reset()
+}
+
+// report submits the derived metrics. The ones computed from deltas are
+// computed but not submitted if suppressDeltas is set, see
+// Options.WarmupPeriods.
+func (d *derivedMetrics) report(statsd partialStatsdClientInterface, tags []string, logger *slog.Logger, suppressDeltas bool) {
+	if rm := d.gcCycles; rm != nil && rm.currentValue.Kind() == metrics.KindUint64 && rm.previousValue.Kind() == metrics.KindUint64 {
+		cycles := rm.currentValue.Uint64() - rm.previousValue.Uint64()
+		if cycles > 0 {
+			d.lastGC = rm.timestamp
+		}
+		if !d.lastGC.IsZero() && !suppressDeltas {
+			since := rm.timestamp.Sub(d.lastGC).Seconds()
+			statsd.GaugeWithTimestamp(secondsSinceGCMetricName, since, tags, 1, rm.timestamp)
+		}
+		// The previous value of the first report is the baseline read when
+		// the store is created, skip the report if there is none. Sub uses
+		// the monotonic clock readings of the timestamps, see
+		// runtimeMetricStore.update.
+		if interval := rm.timestamp.Sub(rm.previousTimestamp).Seconds(); !rm.previousTimestamp.IsZero() && interval > 0 && !suppressDeltas {
+			statsd.GaugeWithTimestamp(gcFrequencyMetricName, float64(cycles)/interval, tags, 1, rm.timestamp)
+		}
+	}
+
+	if d.gcCPU != nil && d.totalCPU != nil &&
+		d.gcCPU.previousValue.Kind() == metrics.KindFloat64 && d.totalCPU.previousValue.Kind() == metrics.KindFloat64 {
+		gcCPU := d.gcCPU.currentValue.Float64() - d.gcCPU.previousValue.Float64()
+		totalCPU := d.totalCPU.currentValue.Float64() - d.totalCPU.previousValue.Float64()
+		thrashing, changed := d.gcThrashing.observe(gcCPU, totalCPU)
+		if changed && thrashing {
+			logger.Warn("runtimemetrics: the GC is thrashing, the process might be close to its memory limit",
+				slog.Attr{Key: "gc_cpu_fraction_threshold", Value: slog.Float64Value(d.gcThrashing.threshold)},
+				slog.Attr{Key: "periods", Value: slog.IntValue(d.gcThrashing.periods)},
+			)
+		} else if changed {
+			logger.Warn("runtimemetrics: the GC is no longer thrashing")
+		}
+		var value float64
+		if thrashing {
+			value = 1
+		}
+		if !suppressDeltas {
+			statsd.GaugeWithTimestamp(gcThrashingMetricName, value, tags, 1, d.totalCPU.timestamp)
+			statsd.GaugeWithTimestamp(gcCPUFractionMetricName, gcCPUFraction(gcCPU, totalCPU), tags, 1, d.totalCPU.timestamp)
+		}
+	}
+
+	if d.heapLive != nil && d.heapGoal != nil &&
+		d.heapLive.currentValue.Kind() == metrics.KindUint64 && d.heapGoal.currentValue.Kind() == metrics.KindUint64 {
+		statsd.GaugeWithTimestamp(gcHeapUtilizationMetricName,
+			heapUtilization(d.heapLive.currentValue.Uint64(), d.heapGoal.currentValue.Uint64()), tags, 1, d.heapGoal.timestamp)
+	}
+
+	if d.heapFree != nil && d.heapReleased != nil && d.heapObjects != nil &&
+		d.heapFree.currentValue.Kind() == metrics.KindUint64 &&
+		d.heapReleased.currentValue.Kind() == metrics.KindUint64 &&
+		d.heapObjects.currentValue.Kind() == metrics.KindUint64 {
+		statsd.GaugeWithTimestamp(heapFragmentationMetricName,
+			heapFragmentation(d.heapFree.currentValue.Uint64(), d.heapReleased.currentValue.Uint64(), d.heapObjects.currentValue.Uint64()),
+			tags, 1, d.heapObjects.timestamp)
+	}
+}
+
+// heapUtilization returns the ratio of the live heap to the heap goal, or 0
+// if there is no goal yet.
+func heapUtilization(live, goal uint64) float64 {
+	if goal == 0 {
+		return 0
+	}
+	return float64(live) / float64(goal)
+}
+
+// gcCPUFraction returns the fraction of the CPU time used by the GC over a
+// period, or 0 if no CPU time was used.
+func gcCPUFraction(gcCPU, totalCPU float64) float64 {
+	if totalCPU <= 0 {
+		return 0
+	}
+	return gcCPU / totalCPU
+}
+
+// heapFragmentation returns the fraction of the heap that is free but not
+// released to the OS, free / (free + released + objects), or 0 if the heap
+// is empty.
+func heapFragmentation(free, released, objects uint64) float64 {
+	total := float64(free) + float64(released) + float64(objects)
+	if total == 0 {
+		return 0
+	}
+	return float64(free) / total
+}
+
+// gcThrashingDetector detects GC death spirals: the GC is considered to be
+// thrashing once the fraction of CPU time it used exceeded the threshold for
+// the given number of consecutive periods, and until it doesn't anymore.
+type gcThrashingDetector struct {
+	threshold float64
+	periods   int
+
+	consecutive int
+	thrashing   bool
+}
+
+// observe updates the detector with the GC and total CPU time used during a
+// period, and returns whether the GC is thrashing and whether that changed
+// with this period.
+func (d *gcThrashingDetector) observe(gcCPU, totalCPU float64) (thrashing, changed bool) {
+	if totalCPU > 0 && gcCPU/totalCPU > d.threshold {
+		d.consecutive++
+	} else {
+		d.consecutive = 0
+	}
+	was := d.thrashing
+	d.thrashing = d.consecutive >= d.periods
+	return d.thrashing, d.thrashing != was
+}
+
+func (d *gcThrashingDetector) reset() {
+	d.consecutive = 0
+	d.thrashing = false
+}
@@ -0,0 +1,339 @@
+package runtimemetrics
+
+import (
+	"bytes"
+	"log/slog"
+	"math"
+	"runtime"
+	"runtime/metrics"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// gaugeValues returns the values of the gauges with the given name.
+func gaugeValues(mock *statsdClientMock, name string) []float64 {
+	var values []float64
+	for _, call := range mock.GaugeCalls() {
+		if call.Name == name {
+			values = append(values, call.Value)
+		}
+	}
+	return values
+}
+
+func TestSecondsSinceGC(t *testing.T) {
+	const name = "runtime.go.metrics.derived.seconds_since_gc.seconds"
+	mock := &statsdClientMock{}
+	desc := metricDesc(gcCyclesMetricName, metrics.KindUint64)
+	rms := newRuntimeMetricStore([]metrics.Description{desc}, mock, &Options{Logger: slog.Default(), DerivedMetrics: true})
+	start := time.Now()
+	reportAt := func(now time.Time) {
+		rms.now = func() time.Time { return now }
+		rms.report()
+	}
+
+	// Note: This test could fail if an unexpected GC occurs. This should be
+	// extremely unlikely.
+	reportAt(start)
+	assert.Empty(t, gaugeValues(mock, name), "should be suppressed before the first observed cycle")
+
+	runtime.GC()
+	reportAt(start.Add(10 * time.Second))
+	require.Equal(t, []float64{0}, gaugeValues(mock, name))
+
+	reportAt(start.Add(25 * time.Second))
+	require.Equal(t, []float64{0, 15}, gaugeValues(mock, name))
+
+	runtime.GC()
+	reportAt(start.Add(35 * time.Second))
+	require.Equal(t, []float64{0, 15, 0}, gaugeValues(mock, name))
+}
+
+func TestGCFrequency(t *testing.T) {
+	const name = "runtime.go.metrics.gc_frequency"
+	mock := &statsdClientMock{}
+	desc := metricDesc(gcCyclesMetricName, metrics.KindUint64)
+	start := time.Now()
+	rms := newRuntimeMetricStore([]metrics.Description{desc}, mock, &Options{Logger: slog.Default(), DerivedMetrics: true})
+	reportAt := func(now time.Time) {
+		rms.now = func() time.Time { return now }
+		rms.report()
+	}
+
+	// Note: This test could fail if an unexpected GC occurs. This should be
+	// extremely unlikely.
+	reportAt(start.Add(10 * time.Second))
+	for i := 0; i < 5; i++ {
+		runtime.GC()
+	}
+	reportAt(start.Add(20 * time.Second))
+
+	values := gaugeValues(mock, name)
+	require.Len(t, values, 2)
+	assert.Equal(t, 0.0, values[0])
+	assert.Equal(t, 0.5, values[1])
+}
+
+func TestDerivedMetricsDisabled(t *testing.T) {
+	mock, rms := reportMetric(gcCyclesMetricName, metrics.KindUint64)
+	runtime.GC()
+	rms.report()
+	for _, call := range mock.GaugeCalls() {
+		assert.NotContains(t, call.Name, ".derived.")
+	}
+}
+
+func TestGCThrashingDetector(t *testing.T) {
+	t.Run("starts after the configured number of periods", func(t *testing.T) {
+		d := gcThrashingDetector{threshold: 0.25, periods: 3}
+		for i := 0; i < 2; i++ {
+			thrashing, changed := d.observe(0.5, 1)
+			assert.False(t, thrashing)
+			assert.False(t, changed)
+		}
+		thrashing, changed := d.observe(0.5, 1)
+		assert.True(t, thrashing)
+		assert.True(t, changed)
+
+		thrashing, changed = d.observe(0.5, 1)
+		assert.True(t, thrashing)
+		assert.False(t, changed)
+	})
+
+	t.Run("a period below the threshold restarts the count", func(t *testing.T) {
+		d := gcThrashingDetector{threshold: 0.25, periods: 2}
+		d.observe(0.5, 1)
+		d.observe(0.25, 1)
+		thrashing, _ := d.observe(0.5, 1)
+		assert.False(t, thrashing)
+		thrashing, _ = d.observe(0.5, 1)
+		assert.True(t, thrashing)
+	})
+
+	t.Run("clears once below the threshold", func(t *testing.T) {
+		d := gcThrashingDetector{threshold: 0.25, periods: 1}
+		d.observe(0.5, 1)
+		thrashing, changed := d.observe(0.1, 1)
+		assert.False(t, thrashing)
+		assert.True(t, changed)
+	})
+
+	t.Run("periods without cpu time don't count", func(t *testing.T) {
+		d := gcThrashingDetector{threshold: 0.25, periods: 1}
+		thrashing, changed := d.observe(0, 0)
+		assert.False(t, thrashing)
+		assert.False(t, changed)
+	})
+
+	t.Run("reset", func(t *testing.T) {
+		d := gcThrashingDetector{threshold: 0.25, periods: 2}
+		d.observe(0.5, 1)
+		d.observe(0.5, 1)
+		d.reset()
+		thrashing, _ := d.observe(0.5, 1)
+		assert.False(t, thrashing)
+	})
+}
+
+func TestGCThrashing(t *testing.T) {
+	const name = "runtime.go.metrics.derived.gc_thrashing"
+	descs := []metrics.Description{
+		metricDesc(gcCPUMetricName, metrics.KindFloat64),
+		metricDesc(totalCPUMetricName, metrics.KindFloat64),
+	}
+	mock := &statsdClientMock{}
+	rms := newRuntimeMetricStore(descs, mock, &Options{Logger: slog.Default(), DerivedMetrics: true, GCThrashingPeriods: 1})
+	rms.report()
+
+	values := gaugeValues(mock, name)
+	require.Len(t, values, 1)
+	assert.Contains(t, []float64{0, 1}, values[0])
+
+	t.Run("pausing resets the state", func(t *testing.T) {
+		rms.derived.gcThrashing.thrashing = true
+		rms.derived.gcThrashing.consecutive = 1
+		rms.setClient(nil)
+		rms.report()
+		assert.False(t, rms.derived.gcThrashing.thrashing)
+		assert.Zero(t, rms.derived.gcThrashing.consecutive)
+	})
+}
+
+func TestGCHeapUtilization(t *testing.T) {
+	const name = "runtime.go.metrics.gc_heap_utilization"
+
+	t.Run("should report live/goal when both are collected", func(t *testing.T) {
+		f := &fakeSampler{steps: []map[string]value{{
+			heapLiveMetricName: uint64Value(30),
+			heapGoalMetricName: uint64Value(40),
+		}}}
+		descs := []metrics.Description{
+			metricDesc(heapLiveMetricName, metrics.KindUint64),
+			metricDesc(heapGoalMetricName, metrics.KindUint64),
+		}
+		mock := &statsdClientMock{}
+		rms := newRuntimeMetricStoreWithSampler(descs, f, mock, &Options{Logger: slog.Default(), DerivedMetrics: true})
+		rms.report()
+		assert.Equal(t, []float64{0.75}, gaugeValues(mock, name))
+	})
+
+	t.Run("should not be reported without its inputs", func(t *testing.T) {
+		desc := metricDesc(heapGoalMetricName, metrics.KindUint64)
+		mock := &statsdClientMock{}
+		rms := newRuntimeMetricStore([]metrics.Description{desc}, mock, &Options{Logger: slog.Default(), DerivedMetrics: true})
+		rms.report()
+		assert.Empty(t, gaugeValues(mock, name))
+	})
+
+	t.Run("should be finite without a goal", func(t *testing.T) {
+		assert.Equal(t, 0.0, heapUtilization(10, 0))
+		assert.Equal(t, 0.5, heapUtilization(1, 2))
+	})
+}
+
+func TestHeapFragmentation(t *testing.T) {
+	descs := []metrics.Description{
+		metricDesc(heapFreeMetricName, metrics.KindUint64),
+		metricDesc(heapReleasedMetricName, metrics.KindUint64),
+		metricDesc(heapObjectsMetricName, metrics.KindUint64),
+	}
+
+	t.Run("should report free / (free + released + objects)", func(t *testing.T) {
+		f := &fakeSampler{steps: []map[string]value{{
+			heapFreeMetricName:     uint64Value(10),
+			heapReleasedMetricName: uint64Value(30),
+			heapObjectsMetricName:  uint64Value(60),
+		}}}
+		mock := &statsdClientMock{}
+		rms := newRuntimeMetricStoreWithSampler(descs, f, mock, &Options{Logger: slog.Default(), DerivedMetrics: true})
+		rms.report()
+		assert.Equal(t, []float64{0.1}, gaugeValues(mock, heapFragmentationMetricName))
+	})
+
+	t.Run("should be a fraction for the running process", func(t *testing.T) {
+		mock := &statsdClientMock{}
+		rms := newRuntimeMetricStore(descs, mock, &Options{Logger: slog.Default(), DerivedMetrics: true})
+		rms.report()
+		values := gaugeValues(mock, heapFragmentationMetricName)
+		require.Len(t, values, 1)
+		assert.False(t, math.IsNaN(values[0]) || math.IsInf(values[0], 0))
+		assert.GreaterOrEqual(t, values[0], 0.0)
+		assert.Less(t, values[0], 1.0)
+	})
+
+	t.Run("should not be reported without its inputs", func(t *testing.T) {
+		mock := &statsdClientMock{}
+		rms := newRuntimeMetricStore(descs[:2], mock, &Options{Logger: slog.Default(), DerivedMetrics: true})
+		rms.report()
+		assert.Empty(t, gaugeValues(mock, heapFragmentationMetricName))
+	})
+
+	t.Run("should be finite for an empty heap", func(t *testing.T) {
+		assert.Equal(t, 0.0, heapFragmentation(0, 0, 0))
+		assert.Equal(t, 0.5, heapFragmentation(1, 0, 1))
+	})
+}
+
+func TestGCCPUFraction(t *testing.T) {
+	descs := []metrics.Description{
+		metricDesc(gcCPUMetricName, metrics.KindFloat64),
+		metricDesc(totalCPUMetricName, metrics.KindFloat64),
+	}
+
+	t.Run("should be a fraction while the GC runs", func(t *testing.T) {
+		mock := &statsdClientMock{}
+		rms := newRuntimeMetricStore(descs, mock, &Options{Logger: slog.Default(), DerivedMetrics: true})
+		for i := 0; i < 3; i++ {
+			runtime.GC()
+		}
+		rms.report()
+		values := gaugeValues(mock, gcCPUFractionMetricName)
+		require.Len(t, values, 1)
+		assert.GreaterOrEqual(t, values[0], 0.0)
+		assert.LessOrEqual(t, values[0], 1.0)
+	})
+
+	t.Run("should use the deltas of the period", func(t *testing.T) {
+		f := &fakeSampler{steps: []map[string]value{
+			{gcCPUMetricName: float64Value(100), totalCPUMetricName: float64Value(1000)},
+			{gcCPUMetricName: float64Value(101), totalCPUMetricName: float64Value(1004)},
+			{gcCPUMetricName: float64Value(101), totalCPUMetricName: float64Value(1004)},
+		}}
+		mock := &statsdClientMock{}
+		rms := newRuntimeMetricStoreWithSampler(descs, f, mock, &Options{Logger: slog.Default(), DerivedMetrics: true})
+		rms.report()
+		rms.report()
+		assert.Equal(t, []float64{0.25, 0}, gaugeValues(mock, gcCPUFractionMetricName), "no CPU time used should report 0")
+	})
+
+	t.Run("should not be reported without its inputs", func(t *testing.T) {
+		mock := &statsdClientMock{}
+		rms := newRuntimeMetricStore(descs[:1], mock, &Options{Logger: slog.Default(), DerivedMetrics: true})
+		rms.report()
+		assert.Empty(t, gaugeValues(mock, gcCPUFractionMetricName))
+	})
+}
+
+func TestWallClockJump(t *testing.T) {
+	descs := []metrics.Description{
+		metricDesc(gcCyclesMetricName, metrics.KindUint64),
+		metricDesc("/gc/pauses:seconds", metrics.KindFloat64Histogram),
+	}
+	f := &fakeSampler{}
+	for i := uint64(0); i < 5; i++ {
+		f.steps = append(f.steps, map[string]value{
+			gcCyclesMetricName: uint64Value(10 * i),
+			"/gc/pauses:seconds": histogramValue(&metrics.Float64Histogram{
+				Counts:  []uint64{i},
+				Buckets: []float64{0, 1},
+			}),
+		})
+	}
+	var logs bytes.Buffer
+	mock := &statsdClientMock{}
+	start := time.Date(2024, 5, 1, 12, 0, 0, 0, time.UTC)
+	now := start
+	rms := newRuntimeMetricStoreWithSampler(descs, f, mock, &Options{
+		Logger:         slog.New(slog.NewTextHandler(&logs, nil)),
+		DerivedMetrics: true,
+		clock:          fakeNow(func() time.Time { return now }),
+	})
+
+	// reportAt reports at the given offset from start, and returns whether
+	// the delta-based series were submitted.
+	reportAt := func(offset time.Duration) (rate, distribution bool) {
+		mock.Reset()
+		now = start.Add(offset)
+		rms.report()
+		return len(gaugeValues(mock, gcFrequencyMetricName)) > 0, len(mock.DistributionCalls()) > 0
+	}
+
+	rate, distribution := reportAt(10 * time.Second)
+	assert.True(t, rate)
+	assert.True(t, distribution)
+
+	// NTP steps the clock 5s backwards.
+	rate, distribution = reportAt(5 * time.Second)
+	assert.False(t, rate, "rates shouldn't be reported over a negative interval")
+	assert.False(t, distribution)
+	assert.Equal(t, 1, strings.Count(logs.String(), "wall clock jumped backwards"))
+
+	rate, distribution = reportAt(15 * time.Second)
+	assert.True(t, rate)
+	assert.True(t, distribution)
+	assert.Equal(t, []float64{1}, gaugeValues(mock, gcFrequencyMetricName), "10 cycles over the 10s since the previous report")
+
+	reportAt(0)
+	assert.Equal(t, 1, strings.Count(logs.String(), "wall clock jumped backwards"), "jumps should only be logged once")
+}
+
+// fakeNow is a clock whose time is given by now, without tickers.
+type fakeNow func() time.Time
+
+func (f fakeNow) Now() time.Time { return f() }
+
+func (f fakeNow) NewTicker(time.Duration) ticker { panic("unexpected ticker") }
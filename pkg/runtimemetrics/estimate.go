@@ -0,0 +1,104 @@
+package runtimemetrics
+
+import (
+	"log/slog"
+	"runtime/metrics"
+	"slices"
+)
+
+// SeriesEstimate is the number of series reported by an emitter in a process,
+// see EstimateSeries.
+type SeriesEstimate struct {
+	// Gauges is the number of gauge series, i.e. of distinct name and tags
+	// pairs, e.g. a summary reported with PercentilesAsTags is one name but
+	// six series.
+	Gauges int
+	// Counts is the number of count series.
+	Counts int
+	// Distributions is the number of distribution metrics.
+	Distributions int
+	// Names are the sorted, distinct names of all these metrics.
+	Names []string
+}
+
+// EstimateSeries returns the series an emitter created with opts would
+// report, to estimate its custom metrics cost, without starting it. It
+// returns the errors NewEmitter would return for opts.
+//
+// The estimate is an upper bound: it includes the series that are only
+// reported once some condition is met, e.g. derived metrics that need a GC
+// cycle. The runtime.go.metrics.skipped_values self-metric, which is only
+// reported for absurd values, isn't included. Base tags and Options.Tags are
+// the same for all the series of a process, so they don't change the
+// estimate.
+func EstimateSeries(opts *Options) (SeriesEstimate, error) {
+	descs := metrics.All()
+	rms, err := newEstimateStore(descs, opts)
+	if err != nil {
+		return SeriesEstimate{}, err
+	}
+	defer rms.close()
+	return estimateSeries(rms.emittedSeries(descs)), nil
+}
+
+// ExpectedGaugeCount returns the number of gauges a report of an emitter
+// created with opts for descs submits when all of descs changed since the
+// previous report, e.g. to assert on the gauges of a report in tests. That
+// is one gauge per collected scalar metric, one per summary of each collected
+// histogram (avg, min, max, median, p95 and p99, unless overridden by
+// HistogramStatOverrides), and the gauges enabled by opts, e.g. BuildInfo,
+// GOMAXPROCSGauge, DerivedMetrics or MemStats. The metrics of descs filtered
+// out by opts don't count.
+//
+// Reports submit fewer gauges when cumulative metrics didn't change, when
+// histograms have fewer than MinHistogramSamples values, or for absurd
+// values. It returns 0 if NewEmitter would reject opts.
+func ExpectedGaugeCount(descs []metrics.Description, opts *Options) int {
+	rms, err := newEstimateStore(descs, opts)
+	if err != nil {
+		return 0
+	}
+	defer rms.close()
+	return estimateSeries(rms.emittedSeries(descs)).Gauges
+}
+
+// newEstimateStore returns a store for descs with opts that doesn't report
+// to any client, and the error NewEmitter would return for opts.
+func newEstimateStore(descs []metrics.Description, opts *Options) (*runtimeMetricStore, error) {
+	var o Options
+	if opts != nil {
+		o = *opts
+	}
+	if o.Logger == nil {
+		o.Logger = slog.Default()
+	}
+	if err := validateOptions(&o, descs); err != nil {
+		return nil, err
+	}
+	rms := newRuntimeMetricStore(descs, nil, &o)
+	if o.StrictTags {
+		if err := validateBaseTags(rms.baseTags); err != nil {
+			rms.close()
+			return nil, err
+		}
+	}
+	return rms, nil
+}
+
+func estimateSeries(series []series) SeriesEstimate {
+	var est SeriesEstimate
+	for _, s := range series {
+		switch s.kind {
+		case GaugeMetric:
+			est.Gauges++
+		case CountMetric:
+			est.Counts++
+		case DistributionMetric:
+			est.Distributions++
+		}
+		est.Names = append(est.Names, s.name)
+	}
+	slices.Sort(est.Names)
+	est.Names = slices.Compact(est.Names)
+	return est
+}
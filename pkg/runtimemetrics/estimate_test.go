@@ -0,0 +1,155 @@
+package runtimemetrics
+
+import (
+	"log/slog"
+	"runtime"
+	"runtime/metrics"
+	"slices"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// changingSampler returns a fakeSampler for which all the given metrics
+// change on every read.
+func changingSampler(descs []metrics.Description, reads int) *fakeSampler {
+	f := &fakeSampler{}
+	for i := 1; i <= reads; i++ {
+		step := map[string]value{}
+		for _, d := range descs {
+			switch d.Kind {
+			case metrics.KindUint64:
+				step[d.Name] = uint64Value(uint64(i))
+			case metrics.KindFloat64:
+				step[d.Name] = float64Value(float64(i))
+			case metrics.KindFloat64Histogram:
+				step[d.Name] = histogramValue(&metrics.Float64Histogram{
+					Counts:  []uint64{uint64(i), uint64(2 * i)},
+					Buckets: []float64{0, 1, 2},
+				})
+			}
+		}
+		f.steps = append(f.steps, step)
+	}
+	return f
+}
+
+func TestEstimateSeries(t *testing.T) {
+	// For runtime.go.metrics.memstats.seconds_since_last_gc.
+	runtime.GC()
+
+	for name, opts := range map[string]Options{
+		"default": {},
+		"tag-based modes": {
+			PercentilesAsTags:  true,
+			DistributionSuffix: ".distribution",
+			TaggedFamilies:     []string{"goroutines", "memory_classes", "cpu_classes"},
+		},
+		"additional metrics": {
+			DerivedMetrics:  true,
+			MemStats:        true,
+			EmitLegacyNames: true,
+		},
+	} {
+		t.Run(name, func(t *testing.T) {
+			opts.Logger = slog.Default()
+			descs := metrics.All()
+			mock := &statsdClientMock{}
+			rms := newRuntimeMetricStoreWithSampler(descs, changingSampler(descs, 2), mock, &opts)
+			rms.report()
+
+			// The series observed in a report where all metrics changed.
+			observed := map[string]bool{}
+			var gauges, distributions int
+			var names []string
+			for _, c := range mock.GaugeCalls() {
+				key := c.Name + "|" + strings.Join(c.Tags, ",")
+				if !observed[key] {
+					gauges++
+				}
+				observed[key] = true
+				names = append(names, c.Name)
+			}
+			for _, c := range mock.DistributionCalls() {
+				key := "distribution|" + c.Name
+				if !observed[key] {
+					distributions++
+				}
+				observed[key] = true
+				names = append(names, c.Name)
+			}
+			require.Empty(t, mock.CountCalls())
+			slices.Sort(names)
+			names = slices.Compact(names)
+
+			est, err := EstimateSeries(&opts)
+			require.NoError(t, err)
+			assert.Equal(t, gauges, est.Gauges)
+			assert.Equal(t, distributions, est.Distributions)
+			assert.Zero(t, est.Counts)
+			assert.Equal(t, names, est.Names)
+		})
+	}
+
+	t.Run("invalid options", func(t *testing.T) {
+		_, err := EstimateSeries(&Options{TaggedFamilies: []string{"nope"}})
+		assert.Error(t, err)
+	})
+
+	t.Run("nil options", func(t *testing.T) {
+		est, err := EstimateSeries(nil)
+		require.NoError(t, err)
+		// Each histogram is a distribution and six summary gauges with
+		// their own names.
+		assert.Positive(t, est.Distributions)
+		assert.Equal(t, len(est.Names)-est.Distributions, est.Gauges)
+	})
+}
+
+func TestExpectedGaugeCount(t *testing.T) {
+	// For runtime.go.metrics.memstats.seconds_since_last_gc.
+	runtime.GC()
+
+	for name, opts := range map[string]Options{
+		"default": {},
+		"tag-based modes": {
+			PercentilesAsTags: true,
+			TaggedFamilies:    []string{"goroutines", "memory_classes", "cpu_classes"},
+		},
+		"additional metrics": {
+			BuildInfo:          true,
+			GOMAXPROCSGauge:    true,
+			NumCPUGauge:        true,
+			GODEBUGActiveGauge: true,
+			DerivedMetrics:     true,
+			MemStats:           true,
+			EmitLegacyNames:    true,
+			HistogramCounts:    true,
+		},
+		"filtered":           {RequireMappedUnit: true},
+		"min histogram size": {MinHistogramSamples: 1},
+	} {
+		t.Run(name, func(t *testing.T) {
+			opts.Logger = slog.Default()
+			descs := metrics.All()
+			mock := &statsdClientMock{}
+			rms := newRuntimeMetricStoreWithSampler(descs, changingSampler(descs, 2), mock, &opts)
+			rms.report()
+			assert.Equal(t, len(mock.GaugeCalls()), ExpectedGaugeCount(descs, &opts))
+		})
+	}
+
+	t.Run("six summaries per histogram", func(t *testing.T) {
+		descs := []metrics.Description{
+			metricDesc("/gc/pauses:seconds", metrics.KindFloat64Histogram),
+			metricDesc("/gc/heap/live:bytes", metrics.KindUint64),
+		}
+		assert.Equal(t, 7, ExpectedGaugeCount(descs, nil))
+	})
+
+	t.Run("invalid options", func(t *testing.T) {
+		assert.Zero(t, ExpectedGaugeCount(metrics.All(), &Options{TaggedFamilies: []string{"nope"}}))
+	})
+}
@@ -0,0 +1,123 @@
+package runtimemetrics
+
+import (
+	"context"
+	"log/slog"
+	"runtime/metrics"
+	"strings"
+	"time"
+)
+
+// fallbackLogReports is the number of consecutive failed reports after which
+// the metrics are logged, see Options.FallbackToLog.
+const fallbackLogReports = 3
+
+// fallbackLogger forwards submissions to a statsd client, and logs the gauges
+// and counts of the reports once the client failed fallbackLogReports
+// consecutive reports, until a report succeeds again, see
+// Options.FallbackToLog.
+type fallbackLogger struct {
+	logger   *slog.Logger
+	baseTags []string
+
+	// failedReports is the number of consecutive failed reports, and
+	// logging is set while the metrics are logged.
+	failedReports int
+	logging       bool
+
+	// set by begin for the duration of a report
+	statsd    partialStatsdClientInterface
+	succeeded int
+	failed    int
+	lastErr   error
+	// attrs are the gauges and counts of the report, only recorded if it
+	// would be logged in case of failure, so that healthy reports don't
+	// allocate.
+	record bool
+	attrs  []slog.Attr
+}
+
+// begin starts a new report submitting to the given client.
+func (f *fallbackLogger) begin(statsd partialStatsdClientInterface) {
+	f.statsd = statsd
+	f.succeeded, f.failed, f.lastErr = 0, 0, nil
+	f.record = f.failedReports >= fallbackLogReports-1
+	f.attrs = f.attrs[:0]
+}
+
+// end completes the current report, logging its metrics if the client failed
+// it and enough reports before it.
+func (f *fallbackLogger) end() {
+	// A report fails if none of its submissions succeeded.
+	if f.failed == 0 || f.succeeded > 0 {
+		f.failedReports = 0
+		if f.logging {
+			f.logging = false
+			f.logger.Warn("runtimemetrics: statsd client recovered, no longer logging the metrics")
+		}
+		return
+	}
+	f.failedReports++
+	if f.failedReports < fallbackLogReports {
+		return
+	}
+	if !f.logging {
+		f.logging = true
+		f.logger.Warn("runtimemetrics: statsd client is failing persistently, logging the metrics until it recovers",
+			slog.Attr{Key: "failed_reports", Value: slog.IntValue(f.failedReports)},
+			slog.Attr{Key: "error", Value: slog.StringValue(f.lastErr.Error())},
+		)
+	}
+	attrs := append([]slog.Attr{{Key: "tags", Value: slog.StringValue(strings.Join(f.baseTags, ","))}}, f.attrs...)
+	f.logger.LogAttrs(context.Background(), slog.LevelInfo, "runtimemetrics: metrics not submitted to the statsd client", attrs...)
+}
+
+// observe counts the outcome of a submission.
+func (f *fallbackLogger) observe(err error) error {
+	if err != nil {
+		f.failed++
+		f.lastErr = err
+	} else {
+		f.succeeded++
+	}
+	return err
+}
+
+// key returns the key a metric is logged under: its name, followed by the
+// tags it has in addition to the base tags, e.g. for the members of tagged
+// families.
+func (f *fallbackLogger) key(name string, tags []string) string {
+	if len(tags) <= len(f.baseTags) {
+		return name
+	}
+	return name + "," + strings.Join(tags[len(f.baseTags):], ",")
+}
+
+func (f *fallbackLogger) GaugeWithTimestamp(name string, value float64, tags []string, rate float64, timestamp time.Time) error {
+	if f.record {
+		f.attrs = append(f.attrs, slog.Attr{Key: f.key(name, tags), Value: slog.Float64Value(value)})
+	}
+	return f.observe(f.statsd.GaugeWithTimestamp(name, value, tags, rate, timestamp))
+}
+
+func (f *fallbackLogger) CountWithTimestamp(name string, value int64, tags []string, rate float64, timestamp time.Time) error {
+	if f.record {
+		f.attrs = append(f.attrs, slog.Attr{Key: f.key(name, tags), Value: slog.Int64Value(value)})
+	}
+	return f.observe(f.statsd.CountWithTimestamp(name, value, tags, rate, timestamp))
+}
+
+// DistributionSamples implements partialStatsdClientInterface. The samples
+// aren't logged, the summaries of histograms are.
+func (f *fallbackLogger) DistributionSamples(name string, values []float64, tags []string, rate float64) error {
+	return f.observe(f.statsd.DistributionSamples(name, values, tags, rate))
+}
+
+// HistogramWithTimestamp implements histogramStatsdClient, if the statsd
+// client does. Histograms aren't logged.
+func (f *fallbackLogger) HistogramWithTimestamp(name string, h *metrics.Float64Histogram, tags []string, timestamp time.Time) error {
+	if c, ok := f.statsd.(histogramStatsdClient); ok {
+		return f.observe(c.HistogramWithTimestamp(name, h, tags, timestamp))
+	}
+	return nil
+}
@@ -0,0 +1,86 @@
+package runtimemetrics
+
+import (
+	"bytes"
+	"errors"
+	"log/slog"
+	"runtime/metrics"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestFallbackToLog(t *testing.T) {
+	descs := []metrics.Description{
+		metricDesc("/gc/heap/live:bytes", metrics.KindUint64),
+		fakeMetricDesc("/sched/goroutines/running:goroutines", metrics.KindUint64),
+		metricDesc("/gc/pauses:seconds", metrics.KindFloat64Histogram),
+	}
+	const logged = "metrics not submitted to the statsd client"
+
+	t.Run("should log the metrics once statsd failed persistently", func(t *testing.T) {
+		var logs bytes.Buffer
+		mock := &failingStatsdClientMock{err: errors.New("connection refused")}
+		mock.failing.Store(true)
+		rms := newRuntimeMetricStoreWithSampler(descs, changingSampler(descs, 7), mock, &Options{
+			Logger:         slog.New(slog.NewTextHandler(&logs, nil)),
+			FallbackToLog:  true,
+			TaggedFamilies: []string{"goroutines"},
+		})
+		for i := 0; i < fallbackLogReports-1; i++ {
+			rms.report()
+		}
+		assert.NotContains(t, logs.String(), logged, "should not log before the threshold")
+
+		rms.report()
+		assert.Contains(t, logs.String(), "statsd client is failing persistently")
+		assert.Contains(t, logs.String(), "error=\"connection refused\"")
+		assert.Contains(t, logs.String(), logged)
+		assert.Contains(t, logs.String(), "runtime.go.metrics.gc_heap_live.bytes=4")
+		assert.Contains(t, logs.String(), "runtime.go.metrics.gc_pauses.seconds.p99=")
+		assert.Contains(t, logs.String(), "runtime.go.metrics.sched_goroutines_by_state.goroutines,state:running=4", "the tags of tagged families should be logged")
+
+		rms.report()
+		assert.Equal(t, 2, strings.Count(logs.String(), logged), "should log every failed report")
+		assert.Equal(t, 1, strings.Count(logs.String(), "statsd client is failing persistently"), "should warn once")
+
+		mock.failing.Store(false)
+		rms.report()
+		assert.Contains(t, logs.String(), "statsd client recovered")
+		assert.Equal(t, 2, strings.Count(logs.String(), logged), "should stop logging once statsd recovers")
+		assert.NotEmpty(t, mock.GaugeCalls())
+
+		mock.failing.Store(true)
+		rms.report()
+		assert.Equal(t, 2, strings.Count(logs.String(), logged), "the failed reports should be counted again")
+	})
+
+	t.Run("should not log if statsd is healthy", func(t *testing.T) {
+		var logs bytes.Buffer
+		mock := &statsdClientMock{}
+		rms := newRuntimeMetricStoreWithSampler(descs, changingSampler(descs, 5), mock, &Options{
+			Logger:        slog.New(slog.NewTextHandler(&logs, nil)),
+			FallbackToLog: true,
+		})
+		for i := 0; i < fallbackLogReports+1; i++ {
+			rms.report()
+		}
+		assert.Empty(t, logs.String())
+		assert.Empty(t, rms.fallback.attrs, "healthy reports shouldn't record the metrics")
+	})
+
+	t.Run("should not log by default", func(t *testing.T) {
+		var logs bytes.Buffer
+		mock := &failingStatsdClientMock{err: errors.New("connection refused")}
+		mock.failing.Store(true)
+		rms := newRuntimeMetricStoreWithSampler(descs, changingSampler(descs, 5), mock, &Options{
+			Logger: slog.New(slog.NewTextHandler(&logs, nil)),
+		})
+		for i := 0; i < fallbackLogReports+1; i++ {
+			rms.report()
+		}
+		assert.Nil(t, rms.fallback)
+		assert.NotContains(t, logs.String(), logged)
+	})
+}
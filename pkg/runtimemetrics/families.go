@@ -0,0 +1,53 @@
+package runtimemetrics
+
+import (
+	"fmt"
+	"strings"
+)
+
+// metricFamily is a group of runtime metrics that can be reported under a
+// single Datadog name, discriminated by a tag, see Options.TaggedFamilies.
+type metricFamily struct {
+	// prefix is the runtime/metrics path prefix of the members.
+	prefix string
+	// unit is the unit shared by all members.
+	unit string
+	// tag is the key of the tag holding the rest of the member's path.
+	tag string
+	// ddMetricPath is the runtime/metrics style path the Datadog name of the
+	// family is derived from.
+	ddMetricPath string
+}
+
+// metricFamilies are the families supported by Options.TaggedFamilies.
+var metricFamilies = map[string]metricFamily{
+	"goroutines":     {prefix: "/sched/goroutines/", unit: "goroutines", tag: "state", ddMetricPath: "/sched/goroutines_by_state"},
+	"memory_classes": {prefix: "/memory/classes/", unit: "bytes", tag: "class", ddMetricPath: "/memory/classes_by_class"},
+	"cpu_classes":    {prefix: "/cpu/classes/", unit: "cpu-seconds", tag: "class", ddMetricPath: "/cpu/classes_by_class"},
+}
+
+// member returns the tag identifying the given runtime metric within the
+// family, or false if it isn't a member. Totals are not members, so that
+// summing the family doesn't count anything twice.
+func (f metricFamily) member(runtimeName string) (string, bool) {
+	path, unit, ok := strings.Cut(runtimeName, ":")
+	if !ok || unit != f.unit {
+		return "", false
+	}
+	rest, ok := strings.CutPrefix(path, f.prefix)
+	if !ok || rest == "total" || strings.HasSuffix(rest, "/total") {
+		return "", false
+	}
+	return f.tag + ":" + rest, true
+}
+
+// validateTaggedFamilies returns an error if families contains an unknown
+// family.
+func validateTaggedFamilies(families []string) error {
+	for _, name := range families {
+		if _, ok := metricFamilies[name]; !ok {
+			return fmt.Errorf("runtimemetrics: unknown metric family %q", name)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,90 @@
+package runtimemetrics
+
+import (
+	"log/slog"
+	"runtime/metrics"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestTaggedFamilies(t *testing.T) {
+	descs := []metrics.Description{
+		fakeMetricDesc("/sched/goroutines/runnable:goroutines", metrics.KindUint64),
+		fakeMetricDesc("/sched/goroutines/waiting:goroutines", metrics.KindUint64),
+		metricDesc("/sched/goroutines:goroutines", metrics.KindUint64),
+		metricDesc("/memory/classes/heap/free:bytes", metrics.KindUint64),
+		metricDesc("/memory/classes/total:bytes", metrics.KindUint64),
+	}
+	// gauges returns the tags of the reported gauges by name, minus the base
+	// tags.
+	gauges := func(mock *statsdClientMock, baseTags []string) map[string][][]string {
+		gauges := map[string][][]string{}
+		for _, call := range mock.GaugeCalls() {
+			gauges[call.Name] = append(gauges[call.Name], call.Tags[len(baseTags):])
+		}
+		return gauges
+	}
+
+	t.Run("default", func(t *testing.T) {
+		mock := &statsdClientMock{}
+		rms := newRuntimeMetricStoreWithSampler(descs, changingSampler(descs, 2), mock, &Options{Logger: slog.Default()})
+		rms.report()
+		got := gauges(mock, rms.baseTags)
+		assert.Len(t, got, len(descs))
+		assert.Contains(t, got, "runtime.go.metrics.sched_goroutines_runnable.goroutines")
+	})
+
+	t.Run("goroutines", func(t *testing.T) {
+		mock := &statsdClientMock{}
+		rms := newRuntimeMetricStoreWithSampler(descs, changingSampler(descs, 2), mock, &Options{Logger: slog.Default(), TaggedFamilies: []string{"goroutines"}})
+		rms.report()
+		got := gauges(mock, rms.baseTags)
+		assert.ElementsMatch(t, [][]string{{"state:runnable"}, {"state:waiting"}}, got["runtime.go.metrics.sched_goroutines_by_state.goroutines"])
+		assert.Equal(t, [][]string{{}}, got["runtime.go.metrics.sched_goroutines.goroutines"], "the total should keep its name")
+		assert.Contains(t, got, "runtime.go.metrics.memory_classes_heap_free.bytes")
+	})
+
+	t.Run("memory classes", func(t *testing.T) {
+		mock := &statsdClientMock{}
+		rms := newRuntimeMetricStoreWithSampler(descs, changingSampler(descs, 2), mock, &Options{Logger: slog.Default(), TaggedFamilies: []string{"memory_classes"}})
+		rms.report()
+		got := gauges(mock, rms.baseTags)
+		assert.Equal(t, [][]string{{"class:heap/free"}}, got["runtime.go.metrics.memory_classes_by_class.bytes"])
+		assert.Contains(t, got, "runtime.go.metrics.memory_classes_total.bytes")
+	})
+
+	t.Run("subsystem prefixes apply", func(t *testing.T) {
+		mock := &statsdClientMock{}
+		rms := newRuntimeMetricStoreWithSampler(descs, changingSampler(descs, 2), mock, &Options{
+			Logger:            slog.Default(),
+			TaggedFamilies:    []string{"goroutines"},
+			SubsystemPrefixes: map[string]string{"sched": "runtime.sched."},
+		})
+		rms.report()
+		assert.Contains(t, gauges(mock, rms.baseTags), "runtime.sched.sched_goroutines_by_state.goroutines")
+	})
+
+	t.Run("unknown family", func(t *testing.T) {
+		require.NoError(t, validateTaggedFamilies([]string{"goroutines", "memory_classes", "cpu_classes"}))
+		e, err := NewEmitter(&statsdClientMock{}, &Options{TaggedFamilies: []string{"nope"}})
+		assert.Error(t, err)
+		assert.Nil(t, e)
+	})
+}
+
+func TestMetricFamilyMember(t *testing.T) {
+	cpu := metricFamilies["cpu_classes"]
+	for name, want := range map[string]string{
+		"/cpu/classes/gc/mark/assist:cpu-seconds": "class:gc/mark/assist",
+		"/cpu/classes/user:cpu-seconds":           "class:user",
+		"/cpu/classes/gc/total:cpu-seconds":       "",
+		"/cpu/classes/total:cpu-seconds":          "",
+		"/gc/heap/goal:bytes":                     "",
+	} {
+		tag, ok := cpu.member(name)
+		assert.Equal(t, want, tag, name)
+		assert.Equal(t, want != "", ok, name)
+	}
+}
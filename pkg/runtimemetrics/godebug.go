@@ -0,0 +1,46 @@
+package runtimemetrics
+
+import (
+	"runtime/metrics"
+	"slices"
+	"strings"
+)
+
+// godebugActiveGaugeName is the gauge reporting the number of non-default
+// GODEBUG behavior events, see Options.GODEBUGActiveGauge.
+const godebugActiveGaugeName = "runtime.go.metrics.godebug_active"
+
+// godebugNonDefaultPrefix is the prefix of the runtime metrics counting the
+// events of non-default GODEBUG behaviors, e.g.
+// /godebug/non-default-behavior/http2client:events.
+const godebugNonDefaultPrefix = "/godebug/non-default-behavior/"
+
+// godebugMetrics returns the collected runtime metrics counting non-default
+// GODEBUG behavior events, sorted by name. It never returns nil.
+func godebugMetrics(store map[string]*runtimeMetric) []*runtimeMetric {
+	var names []string
+	for name := range store {
+		if strings.HasPrefix(name, godebugNonDefaultPrefix) {
+			names = append(names, name)
+		}
+	}
+	slices.Sort(names)
+	res := make([]*runtimeMetric, len(names))
+	for i, name := range names {
+		res[i] = store[name]
+	}
+	return res
+}
+
+// godebugActive returns the total number of non-default GODEBUG behavior
+// events recorded by ms.
+func godebugActive(ms []*runtimeMetric) float64 {
+	var sum float64
+	for _, rm := range ms {
+		if rm.currentValue.Kind() != metrics.KindUint64 {
+			continue
+		}
+		sum += float64(rm.currentValue.Uint64())
+	}
+	return sum
+}
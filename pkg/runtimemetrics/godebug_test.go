@@ -0,0 +1,56 @@
+package runtimemetrics
+
+import (
+	"log/slog"
+	"runtime/metrics"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestGODEBUGActiveGauge(t *testing.T) {
+	var descs []metrics.Description
+	for _, d := range metrics.All() {
+		if strings.HasPrefix(d.Name, godebugNonDefaultPrefix) {
+			descs = append(descs, d)
+		}
+	}
+	if len(descs) < 2 {
+		t.Skip("not enough godebug metrics in this Go version")
+	}
+	descs = descs[:2]
+	descs = append(descs, metricDesc("/gc/heap/live:bytes", metrics.KindUint64))
+
+	t.Run("should report the sum of the non-default behavior events", func(t *testing.T) {
+		f := &fakeSampler{steps: []map[string]value{
+			{descs[0].Name: uint64Value(0), descs[1].Name: uint64Value(0), descs[2].Name: uint64Value(1 << 20)},
+			{descs[0].Name: uint64Value(3), descs[1].Name: uint64Value(4), descs[2].Name: uint64Value(1 << 20)},
+			{descs[0].Name: uint64Value(5), descs[1].Name: uint64Value(4), descs[2].Name: uint64Value(1 << 20)},
+		}}
+		mock := &statsdClientMock{}
+		rms := newRuntimeMetricStoreWithSampler(descs, f, mock, &Options{Logger: slog.Default(), GODEBUGActiveGauge: true})
+		rms.report()
+		rms.report()
+		assert.Equal(t, []float64{7, 9}, gaugeValues(mock, godebugActiveGaugeName), "the live heap shouldn't be counted")
+
+		calls := mock.CallsWithSuffix(godebugActiveGaugeName).Gauges
+		require.NotEmpty(t, calls)
+		assert.Equal(t, rms.baseTags, calls[0].Tags)
+	})
+
+	t.Run("should report 0 if no godebug metric is collected", func(t *testing.T) {
+		mock := &statsdClientMock{}
+		rms := newRuntimeMetricStore(descs[2:], mock, &Options{Logger: slog.Default(), GODEBUGActiveGauge: true})
+		rms.report()
+		assert.Equal(t, []float64{0}, gaugeValues(mock, godebugActiveGaugeName))
+	})
+
+	t.Run("should not be reported by default", func(t *testing.T) {
+		mock := &statsdClientMock{}
+		rms := newRuntimeMetricStore(descs, mock, &Options{Logger: slog.Default()})
+		rms.report()
+		assert.Empty(t, gaugeValues(mock, godebugActiveGaugeName))
+	})
+}
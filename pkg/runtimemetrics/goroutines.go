@@ -0,0 +1,89 @@
+package runtimemetrics
+
+import (
+	"log/slog"
+	"runtime"
+	"sync"
+	"time"
+)
+
+// goroutineSamplesMetricName is the distribution of the goroutine counts
+// sampled between reports, see Options.GoroutineSamplePeriod.
+const goroutineSamplesMetricName = "runtime.go.metrics.sched_goroutines_sampled.goroutines"
+
+// maxGoroutineSamples is the number of goroutine counts retained between two
+// reports, see Options.GoroutineSamplePeriod.
+const maxGoroutineSamples = 1024
+
+// checkGoroutineSamplePeriod returns the goroutine sample period to use for
+// the requested period d and the reporting period: d raised so that the
+// samples of a reporting period fit in maxGoroutineSamples, with a warning.
+func checkGoroutineSamplePeriod(d, period time.Duration, logger *slog.Logger) time.Duration {
+	if d <= 0 {
+		return 0
+	}
+	if floor := period / maxGoroutineSamples; d < floor {
+		logger.Warn("runtimemetrics: goroutine sample period is too short for the reporting period, using the minimum sample period instead",
+			slog.Attr{Key: "goroutine_sample_period", Value: slog.DurationValue(d)},
+			slog.Attr{Key: "min_goroutine_sample_period", Value: slog.DurationValue(floor)},
+		)
+		return floor
+	}
+	return d
+}
+
+// goroutineSampler samples the number of goroutines from its own goroutine,
+// see Options.GoroutineSamplePeriod. The samples are retained until they are
+// drained by a report, at most maxGoroutineSamples of them.
+type goroutineSampler struct {
+	period time.Duration
+	// count is runtime.NumGoroutine, except in tests.
+	count func() int
+
+	mu      sync.Mutex
+	samples []float64
+}
+
+func newGoroutineSampler(period time.Duration) *goroutineSampler {
+	return &goroutineSampler{
+		period:  period,
+		count:   runtime.NumGoroutine,
+		samples: make([]float64, 0, maxGoroutineSamples),
+	}
+}
+
+// run samples the number of goroutines every period until stop is closed.
+func (g *goroutineSampler) run(c clock, stop <-chan struct{}) {
+	ticker := c.NewTicker(g.period)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-stop:
+			return
+		case <-ticker.C():
+			g.sample()
+		}
+	}
+}
+
+// sample records the current number of goroutines. It is dropped if
+// maxGoroutineSamples are already retained, e.g. when the reporting period
+// was lengthened with Emitter.SetPeriod.
+func (g *goroutineSampler) sample() {
+	n := g.count()
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	if len(g.samples) < cap(g.samples) {
+		g.samples = append(g.samples, float64(n))
+	}
+}
+
+// drain returns the samples recorded since the last call, and retains dst
+// to record the next ones. dst must have a capacity of maxGoroutineSamples,
+// so that the two buffers are swapped rather than allocated.
+func (g *goroutineSampler) drain(dst []float64) []float64 {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	g.samples, dst = dst[:0], g.samples
+	return dst
+}
@@ -0,0 +1,139 @@
+package runtimemetrics
+
+import (
+	"bytes"
+	"log/slog"
+	"runtime/metrics"
+	"slices"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// goroutineSampleValues returns the values of the goroutine sample
+// distributions submitted to mock, one slice per report.
+func goroutineSampleValues(mock *statsdClientMock) [][]float64 {
+	var res [][]float64
+	for _, c := range mock.DistributionCalls() {
+		if c.Name == goroutineSamplesMetricName {
+			res = append(res, c.Value)
+		}
+	}
+	return res
+}
+
+func TestGoroutineSamples(t *testing.T) {
+	descs := []metrics.Description{metricDesc("/sched/goroutines:goroutines", metrics.KindUint64)}
+
+	t.Run("should report the samples taken since the last report", func(t *testing.T) {
+		mock := &statsdClientMock{}
+		rms := newRuntimeMetricStoreWithSampler(descs, changingSampler(descs, 4), mock, &Options{Logger: slog.Default(), GoroutineSamplePeriod: time.Second})
+		counts := []int{3, 10, 5, 7}
+		rms.goroutines.count = func() int {
+			n := counts[0]
+			counts = counts[1:]
+			return n
+		}
+		for i := 0; i < 3; i++ {
+			rms.goroutines.sample()
+		}
+		rms.report()
+		rms.report()
+		rms.goroutines.sample()
+		rms.report()
+
+		assert.Equal(t, [][]float64{{3, 10, 5}, {7}}, goroutineSampleValues(mock), "reports without samples shouldn't submit any")
+		calls := mock.CallsWithSuffix(goroutineSamplesMetricName).Distributions
+		require.NotEmpty(t, calls)
+		assert.Equal(t, rms.baseTags, calls[0].Tags)
+	})
+
+	t.Run("should reflect the varying number of goroutines", func(t *testing.T) {
+		mock := &statsdClientMock{}
+		rms := newRuntimeMetricStoreWithSampler(descs, changingSampler(descs, 2), mock, &Options{Logger: slog.Default(), GoroutineSamplePeriod: time.Second})
+		stop := make(chan struct{})
+		var wg sync.WaitGroup
+		for i := 0; i < 3; i++ {
+			rms.goroutines.sample()
+			for j := 0; j < 10; j++ {
+				wg.Add(1)
+				go func() {
+					defer wg.Done()
+					<-stop
+				}()
+			}
+		}
+		rms.goroutines.sample()
+		close(stop)
+		wg.Wait()
+		rms.report()
+
+		values := goroutineSampleValues(mock)
+		require.Len(t, values, 1)
+		assert.Len(t, values[0], 4)
+		// Leave some slack for the goroutines of other tests.
+		assert.Greater(t, values[0][3]-values[0][0], 20.0, "the count should increase with each batch of goroutines")
+	})
+
+	t.Run("should retain a bounded number of samples", func(t *testing.T) {
+		mock := &statsdClientMock{}
+		rms := newRuntimeMetricStoreWithSampler(descs, changingSampler(descs, 3), mock, &Options{Logger: slog.Default(), GoroutineSamplePeriod: time.Second})
+		for i := 0; i < 2*maxGoroutineSamples; i++ {
+			rms.goroutines.sample()
+		}
+		rms.report()
+		rms.goroutines.sample()
+		rms.report()
+
+		values := goroutineSampleValues(mock)
+		require.Len(t, values, 2)
+		assert.Len(t, values[0], maxGoroutineSamples)
+		assert.Len(t, values[1], 1)
+		assert.Equal(t, 2*maxGoroutineSamples, cap(rms.goroutineSamples)+cap(rms.goroutines.samples), "the buffers should be swapped, not grown")
+	})
+
+	t.Run("should be sampled by the emitter between reports", func(t *testing.T) {
+		mock := &statsdClientMock{}
+		e, clock, reports := startFakeEmitter(t, mock, Options{Period: 10 * time.Second, GoroutineSamplePeriod: time.Second})
+		// Wait for the tickers of both the reports and the samples.
+		<-clock.tickerCreated
+		var count atomic.Int64
+		e.store().goroutines.count = func() int { return int(count.Add(1)) }
+
+		clock.Advance(10 * time.Second)
+		<-reports
+		clock.Advance(10 * time.Second)
+		<-reports
+
+		// The sample taken along with a report may land in the next one.
+		var all []float64
+		for _, v := range goroutineSampleValues(mock) {
+			all = append(all, v...)
+		}
+		assert.GreaterOrEqual(t, len(all), 10)
+		assert.True(t, slices.IsSorted(all), "the samples should be submitted in order")
+	})
+
+	t.Run("should not be reported by default", func(t *testing.T) {
+		mock := &statsdClientMock{}
+		rms := newRuntimeMetricStoreWithSampler(descs, changingSampler(descs, 2), mock, &Options{Logger: slog.Default()})
+		rms.report()
+		assert.Nil(t, rms.goroutines)
+		assert.Empty(t, goroutineSampleValues(mock))
+	})
+}
+
+func TestCheckGoroutineSamplePeriod(t *testing.T) {
+	var logs bytes.Buffer
+	logger := slog.New(slog.NewTextHandler(&logs, nil))
+	assert.Equal(t, time.Duration(0), checkGoroutineSamplePeriod(-time.Second, 10*time.Second, logger))
+	assert.Equal(t, time.Second, checkGoroutineSamplePeriod(time.Second, 10*time.Second, logger))
+	assert.Empty(t, logs.String())
+
+	assert.Equal(t, 10*time.Second/maxGoroutineSamples, checkGoroutineSamplePeriod(time.Microsecond, 10*time.Second, logger))
+	assert.Contains(t, logs.String(), "goroutine sample period is too short")
+}
@@ -1,6 +1,7 @@
 package runtimemetrics
 
 import (
+	"fmt"
 	"math"
 	"runtime/metrics"
 	"slices"
@@ -24,6 +25,90 @@ type histogramStats struct {
 	Max    float64 // aka P100
 }
 
+// histogramStatNames are the names of the histogramStats, in the order
+// returned by histogramStats.values.
+var histogramStatNames = [...]string{"avg", "min", "max", "median", "p95", "p99"}
+
+func (s *histogramStats) values() [len(histogramStatNames)]float64 {
+	return [...]float64{s.Avg, s.Min, s.Max, s.Median, s.P95, s.P99}
+}
+
+// allHistogramStats are the indexes of all the histogramStatNames, the
+// summaries reported by default.
+var allHistogramStats = []int{0, 1, 2, 3, 4, 5}
+
+// histogramStatIndexes returns the indexes in histogramStatNames of the
+// given summaries, in the order of histogramStatNames, see
+// Options.HistogramStatOverrides. Unknown summaries are ignored.
+func histogramStatIndexes(stats []string) []int {
+	res := []int{}
+	for i, stat := range histogramStatNames {
+		if slices.Contains(stats, stat) {
+			res = append(res, i)
+		}
+	}
+	return res
+}
+
+// validateHistogramStatOverrides returns an error if overrides, see
+// Options.HistogramStatOverrides, has unknown summaries or metrics of descs
+// that aren't histograms. Metrics that aren't in descs, e.g. of other Go
+// versions, are accepted.
+func validateHistogramStatOverrides(overrides map[string][]string, descs []metrics.Description) error {
+	for name, stats := range overrides {
+		for _, stat := range stats {
+			if !slices.Contains(histogramStatNames[:], stat) {
+				return fmt.Errorf("runtimemetrics: unknown histogram summary %q for %s", stat, name)
+			}
+		}
+		i := slices.IndexFunc(descs, func(d metrics.Description) bool { return d.Name == name })
+		if i >= 0 && descs[i].Kind != metrics.KindFloat64Histogram {
+			return fmt.Errorf("runtimemetrics: %s isn't a histogram, its summaries can't be overridden", name)
+		}
+	}
+	return nil
+}
+
+// PercentileInterpolation is how the percentiles of a histogram are
+// estimated within the bucket they fall in, see
+// Options.PercentileInterpolation.
+type PercentileInterpolation int
+
+const (
+	// LinearInterpolation assumes that the values of a bucket are spread
+	// evenly between its boundaries.
+	LinearInterpolation PercentileInterpolation = iota
+	// ExponentialInterpolation assumes that the values of a bucket are
+	// spread evenly on a logarithmic scale, matching the exponential
+	// buckets of most runtime/metrics histograms. Buckets with a
+	// non-positive boundary are interpolated linearly.
+	ExponentialInterpolation
+	// NoInterpolation estimates percentiles as the upper boundary of their
+	// bucket, i.e. an upper bound snapped to the bucket boundaries. The
+	// minimum is still the lower boundary of its bucket.
+	NoInterpolation
+)
+
+func validatePercentileInterpolation(i PercentileInterpolation) error {
+	if i < LinearInterpolation || i > NoInterpolation {
+		return fmt.Errorf("runtimemetrics: unsupported percentile interpolation %d", i)
+	}
+	return nil
+}
+
+// interpolate returns the value at the fraction frac of the bucket [start,
+// end], in [0, 1].
+func (i PercentileInterpolation) interpolate(start, end, frac float64) float64 {
+	switch {
+	case i == NoInterpolation && frac > 0:
+		return end
+	case i == ExponentialInterpolation && start > 0 && end > start:
+		return start * math.Pow(end/start, frac)
+	default:
+		return start + (end-start)*frac
+	}
+}
+
 type distributionSample struct {
 	Value float64
 	Rate  float64
@@ -58,16 +143,85 @@ func distributionSamplesFromHist(h *metrics.Float64Histogram, samples []distribu
 	return samples
 }
 
-func statsFromHist(h *metrics.Float64Histogram) *histogramStats {
-	p := percentiles(h, []float64{0, 0.5, 0.95, 0.99, 1})
-	return &histogramStats{
-		Avg:    avg(h),
-		Min:    p[0],
-		Median: p[1],
-		P95:    p[2],
-		P99:    p[3],
-		Max:    p[4],
+// statsPercentiles are the sorted percentiles of histogramStats: min,
+// median, p95, p99 and max.
+var statsPercentiles = [...]float64{0, 0.5, 0.95, 0.99, 1}
+
+// HistogramPercentiles returns the percentiles the emitter reports for each
+// histogram, as the median, p95 and p99 summaries, e.g. to compute the same
+// ones with SummarizeHistogram. The min and max summaries are the 0 and 1
+// percentiles.
+func HistogramPercentiles() []float64 {
+	return slices.Clone(statsPercentiles[1 : len(statsPercentiles)-1])
+}
+
+// HistogramSummaries returns the names of the summaries the emitter reports
+// as gauges for each histogram, e.g. "p99" for the
+// runtime.go.metrics.gc_pauses.seconds.p99 summary of /gc/pauses:seconds, see
+// DatadogSummaryMetricName and Options.HistogramStatOverrides.
+func HistogramSummaries() []string {
+	return slices.Clone(histogramStatNames[:])
+}
+
+func statsFromHist(h *metrics.Float64Histogram, interp PercentileInterpolation) histogramStats {
+	// Unlike summarize, this doesn't allocate, it is called for every
+	// histogram of every report.
+	var p [len(statsPercentiles)]float64
+	sortedPercentiles(h, statsPercentiles[:], p[:], interp)
+	s := histogramStats{Min: p[0], Median: p[1], P95: p[2], P99: p[3], Max: p[4]}
+	if sum, count := sumAndCount(h); count > 0 {
+		s.Avg = sum / float64(count)
 	}
+	return s
+}
+
+// HistogramSummary summarizes the values recorded by a histogram, see
+// SummarizeHistogram. Values are estimated from the bucket boundaries, and
+// are always finite: values in an infinite edge bucket are estimated to be
+// its finite boundary.
+type HistogramSummary struct {
+	Count uint64
+	Sum   float64
+	Avg   float64
+	Min   float64
+	Max   float64
+	// Percentiles are in the order they were requested in.
+	Percentiles []float64
+}
+
+// SummarizeHistogram summarizes the values recorded by cur since prev, using
+// the same logic as the histograms reported by the emitter. prev may be nil
+// to summarize all of cur, otherwise it must have the same buckets as cur,
+// which is the case for two reads of the same runtime/metrics histogram.
+//
+// It returns false if no value was recorded since prev. It panics if any of
+// the percentiles isn't in [0, 1].
+func SummarizeHistogram(prev, cur *metrics.Float64Histogram, percentiles []float64) (HistogramSummary, bool) {
+	h := cur
+	if prev != nil {
+		var equal bool
+		if h, equal = sub(cur, prev); equal {
+			return HistogramSummary{}, false
+		}
+	}
+	s := summarize(h, percentiles, LinearInterpolation)
+	return s, s.Count > 0
+}
+
+func summarize(h *metrics.Float64Histogram, ps []float64, interp PercentileInterpolation) HistogramSummary {
+	p := percentiles(h, append([]float64{0, 1}, ps...), interp)
+	sum, count := sumAndCount(h)
+	s := HistogramSummary{
+		Count:       count,
+		Sum:         sum,
+		Min:         p[0],
+		Max:         p[1],
+		Percentiles: p[2:],
+	}
+	if count > 0 {
+		s.Avg = sum / float64(count)
+	}
+	return s
 }
 
 // Return the difference between both histograms, and whether
@@ -75,25 +229,53 @@ func statsFromHist(h *metrics.Float64Histogram) *histogramStats {
 // We assume a and b always have the same lengths for `Counts` and
 // `Buckets` slices which is guaranteed by the runtime/metrics
 // package: https://go.dev/src/runtime/metrics/histogram.go
+// Otherwise, e.g. if b is the empty histogram of a missing previous value,
+// b is treated as empty. The result shares the buckets of a, which are
+// never modified.
 func sub(a, b *metrics.Float64Histogram) (*metrics.Float64Histogram, bool) {
+	res := &metrics.Float64Histogram{}
+	equal := subInto(res, a, b)
+	return res, equal
+}
+
+// subInto is sub, setting res to the difference, and reusing its counts.
+func subInto(res, a, b *metrics.Float64Histogram) bool {
 	equal := true
-	res := &metrics.Float64Histogram{
-		Counts:  make([]uint64, len(a.Counts)),
-		Buckets: make([]float64, len(a.Buckets)),
-	}
-	copy(res.Buckets, a.Buckets)
+	res.Counts = slices.Grow(res.Counts[:0], len(a.Counts))[:len(a.Counts)]
+	res.Buckets = a.Buckets
 	for i := range res.Counts {
-		count := a.Counts[i] - b.Counts[i]
+		count := a.Counts[i]
+		if len(b.Counts) == len(a.Counts) {
+			count -= b.Counts[i]
+		}
 		res.Counts[i] = count
 		if equal && count != 0 {
 			equal = false
 		}
 	}
-	return res, equal
+	return equal
 }
 
-func avg(h *metrics.Float64Histogram) float64 {
-	var total float64
+// histogramCount returns the number of values recorded by h.
+func histogramCount(h *metrics.Float64Histogram) uint64 {
+	var count uint64
+	for _, c := range h.Counts {
+		count += c
+	}
+	return count
+}
+
+// sumAndCount returns the sum of the values of the histogram and their
+// count, weighting the representative value of each bucket by its count.
+//
+// The runtime/metrics histograms use an exponential scale for most of their
+// range, and the midpoint of a wide exponential bucket overestimates where
+// its values lie. So unless the histogram is on a linear scale, the
+// representative value of a bucket with positive boundaries is the geometric
+// mean of its boundaries.
+func sumAndCount(h *metrics.Float64Histogram) (float64, uint64) {
+	linear := hasLinearBuckets(h.Buckets)
+	var total uint64
 	var cumulative float64
 	for i, count := range h.Counts {
 		start, end := h.Buckets[i], h.Buckets[i+1]
@@ -105,22 +287,52 @@ func avg(h *metrics.Float64Histogram) float64 {
 			end = start
 		}
 		if start == end && math.IsInf(start, 0) {
-			return 0
+			return 0, 0
 		}
-		cumulative += float64(count) * (float64(start+end) / 2)
-		total += float64(count)
+		if count == 0 {
+			continue
+		}
+		value := (start + end) / 2
+		if !linear && start > 0 {
+			value = math.Sqrt(start * end)
+		}
+		cumulative += float64(count) * value
+		total += count
 	}
-	if total == 0 {
-		return 0
+	return cumulative, total
+}
+
+// hasLinearBuckets returns true if all finite buckets have the same width.
+func hasLinearBuckets(buckets []float64) bool {
+	width := math.NaN()
+	for i := 0; i < len(buckets)-1; i++ {
+		start, end := buckets[i], buckets[i+1]
+		if math.IsInf(start, 0) || math.IsInf(end, 0) {
+			continue
+		}
+		if math.IsNaN(width) {
+			width = end - start
+			continue
+		}
+		if math.Abs((end-start)-width) > 1e-9*math.Abs(width) {
+			return false
+		}
 	}
-	return cumulative / total
+	return true
 }
 
 // This function takes a runtime/metrics histogram, and a slice of all
 // percentiles to compute for that histogram. It computes all percentiles
 // in a single pass and returns the results which is more efficient than
 // computing each percentile separately.
-func percentiles(h *metrics.Float64Histogram, pInput []float64) []float64 {
+//
+// Percentiles falling in an infinite edge bucket are clamped to its finite
+// boundary, so they stay graphable: e.g. the p99 of a histogram whose top
+// bucket is [4, +Inf) is at most 4. If all the values are in that bucket,
+// all the percentiles, as well as the average, are its finite boundary.
+//
+// Within a bucket, percentiles are estimated with the given interpolation.
+func percentiles(h *metrics.Float64Histogram, pInput []float64, interp PercentileInterpolation) []float64 {
 	p := make([]float64, len(pInput))
 	copy(p, pInput)
 	sort.Float64s(p)
@@ -130,6 +342,19 @@ func percentiles(h *metrics.Float64Histogram, pInput []float64) []float64 {
 	}
 
 	results := make([]float64, len(p))
+	sortedPercentiles(h, p, results, interp)
+
+	orderedResults := make([]float64, len(p))
+	for i := range orderedResults {
+		orderedResults[i] = results[slices.Index(p, pInput[i])]
+	}
+
+	return orderedResults
+}
+
+// sortedPercentiles is percentiles for sorted percentiles in [0, 1], setting
+// the results, which are zero initially, in the same order.
+func sortedPercentiles(h *metrics.Float64Histogram, p []float64, results []float64, interp PercentileInterpolation) {
 
 	var total float64 // total count across all buckets
 	for i := range h.Counts {
@@ -152,7 +377,7 @@ func percentiles(h *metrics.Float64Histogram, pInput []float64) []float64 {
 		}
 
 		if start == end && math.IsInf(start, 0) {
-			return results
+			return
 		}
 
 		// adds the counts of this bucket, to check whether the percentile is in this bucket
@@ -164,8 +389,8 @@ func percentiles(h *metrics.Float64Histogram, pInput []float64) []float64 {
 		for (cumulative > 0) && j < len(p) && (cumulative >= total*p[j]) {
 			// The target percentile is somewhere in the current bucket: [start, end]
 			// and corresponds to a count in: [cumulative-bucketCount, cumulative]
-			// We use linear interpolation to estimate the value of the percentile
-			// within the bucket.
+			// We use interpolation, linear by default, to estimate the value of
+			// the percentile within the bucket.
 			//
 			//                             bucketCount
 			//                  <--------------------------------->
@@ -178,18 +403,11 @@ func percentiles(h *metrics.Float64Histogram, pInput []float64) []float64 {
 			// buckets:       start            | percentile |     end
 			//
 			percentileCount := total*p[j] - (cumulative - bucketCount)
-			results[j] = start + (end-start)*(percentileCount/bucketCount) // percentile
+			results[j] = interp.interpolate(start, end, percentileCount/bucketCount) // percentile
 			// we can have multiple percentiles fall in the same bucket, so we check if the
 			// next percentile falls in this bucket
 			j++
 		}
 		i++
 	}
-
-	orderedResults := make([]float64, len(p))
-	for i := range orderedResults {
-		orderedResults[i] = results[slices.Index(p, pInput[i])]
-	}
-
-	return orderedResults
 }
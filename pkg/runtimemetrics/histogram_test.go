@@ -1,11 +1,15 @@
 package runtimemetrics
 
 import (
+	"log/slog"
 	"math"
+	"runtime"
 	"runtime/metrics"
+	"strings"
 	"testing"
 
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 )
 
 func TestHistogramToDistributionSamples(t *testing.T) {
@@ -67,7 +71,7 @@ func TestHistogramAvg(t *testing.T) {
 			Counts:  []uint64{1, 2, 3, 4, 5, 6, 7, 8, 9, 10},
 			Buckets: []float64{0, 10, 20, 30, 40, 50, 60, 70, 80, 90, 100},
 		}
-		a := avg(h)
+		a := statsFromHist(h, LinearInterpolation).Avg
 		assert.Equal(t, 65.0, a)
 	})
 
@@ -76,16 +80,36 @@ func TestHistogramAvg(t *testing.T) {
 			Counts:  []uint64{0, 1, 2, 3, 4, 5, 6, 7, 8, 9, 5},
 			Buckets: []float64{math.Inf(-1), 0, 10, 20, 30, 40, 50, 60, 70, 80, 90, math.Inf(+1)},
 		}
-		a := avg(h)
+		a := statsFromHist(h, LinearInterpolation).Avg
 		assert.Equal(t, 61.5, a)
 	})
 
+	t.Run("should use the geometric mean of exponential buckets", func(t *testing.T) {
+		h := &metrics.Float64Histogram{
+			Counts:  []uint64{4, 3, 2, 1},
+			Buckets: []float64{1, 2, 4, 8, 16},
+		}
+		// (4*sqrt(2) + 3*sqrt(8) + 2*sqrt(32) + 1*sqrt(128)) / 10
+		a := statsFromHist(h, LinearInterpolation).Avg
+		assert.InDelta(t, 3.676955, a, 1e-6)
+	})
+
+	t.Run("should use the midpoint of an exponential bucket starting at zero", func(t *testing.T) {
+		h := &metrics.Float64Histogram{
+			Counts:  []uint64{1, 0, 0, 1},
+			Buckets: []float64{0, 1, 2, 4, 8},
+		}
+		// (1*0.5 + 1*sqrt(32)) / 2
+		a := statsFromHist(h, LinearInterpolation).Avg
+		assert.InDelta(t, 3.078427, a, 1e-6)
+	})
+
 	t.Run("return 0 when there is a single -inf, +inf bucket", func(t *testing.T) {
 		h := &metrics.Float64Histogram{
 			Counts:  []uint64{10},
 			Buckets: []float64{math.Inf(-1), math.Inf(+1)},
 		}
-		a := avg(h)
+		a := statsFromHist(h, LinearInterpolation).Avg
 		assert.Equal(t, 0.0, a)
 	})
 
@@ -94,7 +118,7 @@ func TestHistogramAvg(t *testing.T) {
 			Counts:  []uint64{0, 0, 0},
 			Buckets: []float64{1, 2, 3, 4},
 		}
-		a := avg(h)
+		a := statsFromHist(h, LinearInterpolation).Avg
 		assert.Equal(t, 0.0, a)
 	})
 }
@@ -105,7 +129,7 @@ func TestHistogramPercentiles(t *testing.T) {
 			Counts:  []uint64{1, 2, 3, 4, 5, 6, 7, 8, 9, 10},
 			Buckets: []float64{0, 10, 20, 30, 40, 50, 60, 70, 80, 90, 100},
 		}
-		p := percentiles(h, []float64{0, 0.5, 0.95, 0.99, 1})
+		p := percentiles(h, []float64{0, 0.5, 0.95, 0.99, 1}, LinearInterpolation)
 		assert.InDeltaSlice(t, []float64{0, 69.2, 97.25, 99.45, 100}, p, 0.1)
 	})
 
@@ -114,7 +138,7 @@ func TestHistogramPercentiles(t *testing.T) {
 			Counts:  []uint64{1, 2, 3, 4, 5, 6, 7, 8, 9, 10},
 			Buckets: []float64{0, 10, 20, 30, 40, 50, 60, 70, 80, 90, 100},
 		}
-		p := percentiles(h, []float64{0.5, 0.95, 1, 0, 0.99})
+		p := percentiles(h, []float64{0.5, 0.95, 1, 0, 0.99}, LinearInterpolation)
 		assert.InDeltaSlice(t, []float64{69.2, 97.25, 100, 0, 99.45}, p, 0.1)
 	})
 
@@ -123,16 +147,34 @@ func TestHistogramPercentiles(t *testing.T) {
 			Counts:  []uint64{1, 2, 3, 4, 5, 6, 7, 8, 9, 10},
 			Buckets: []float64{math.Inf(-1), 10, 20, 30, 40, 50, 60, 70, 80, 90, math.Inf(+1)},
 		}
-		p := percentiles(h, []float64{0, 0.5, 0.95, 0.99, 1})
+		p := percentiles(h, []float64{0, 0.5, 0.95, 0.99, 1}, LinearInterpolation)
 		assert.InDeltaSlice(t, []float64{10, 69.2, 90, 90, 90}, p, 0.1)
 	})
 
+	t.Run("should clamp the percentiles in the overflow bucket to the largest finite boundary", func(t *testing.T) {
+		h := &metrics.Float64Histogram{
+			Counts:  []uint64{0, 90, 8, 2},
+			Buckets: []float64{0, 1, 2, 4, math.Inf(+1)},
+		}
+		p := percentiles(h, []float64{0.5, 0.99, 1}, LinearInterpolation)
+		assert.InDeltaSlice(t, []float64{1.56, 4, 4}, p, 0.01)
+	})
+
+	t.Run("should report the largest finite boundary when all values overflow", func(t *testing.T) {
+		h := &metrics.Float64Histogram{
+			Counts:  []uint64{0, 0, 5},
+			Buckets: []float64{0, 1, 2, math.Inf(+1)},
+		}
+		s := statsFromHist(h, LinearInterpolation)
+		assert.Equal(t, histogramStats{Avg: 2, Min: 2, Median: 2, P95: 2, P99: 2, Max: 2}, s)
+	})
+
 	t.Run("should panic when given <0 percentiles to compute", func(t *testing.T) {
 		h := &metrics.Float64Histogram{
 			Counts:  []uint64{1, 2, 3, 4, 5, 6, 7, 8, 9, 10},
 			Buckets: []float64{0, 10, 20, 30, 40, 50, 60, 70, 80, 90, 100},
 		}
-		assert.Panics(t, func() { percentiles(h, []float64{-1}) })
+		assert.Panics(t, func() { percentiles(h, []float64{-1}, LinearInterpolation) })
 	})
 
 	t.Run("should panic when given >1 percentiles to compute", func(t *testing.T) {
@@ -140,7 +182,7 @@ func TestHistogramPercentiles(t *testing.T) {
 			Counts:  []uint64{1, 2, 3, 4, 5, 6, 7, 8, 9, 10},
 			Buckets: []float64{0, 10, 20, 30, 40, 50, 60, 70, 80, 90, 100},
 		}
-		assert.Panics(t, func() { percentiles(h, []float64{25}) })
+		assert.Panics(t, func() { percentiles(h, []float64{25}, LinearInterpolation) })
 	})
 
 	t.Run("return 0 when there is a single -inf, +inf bucket", func(t *testing.T) {
@@ -148,7 +190,7 @@ func TestHistogramPercentiles(t *testing.T) {
 			Counts:  []uint64{10},
 			Buckets: []float64{math.Inf(-1), math.Inf(+1)},
 		}
-		a := percentiles(h, []float64{0, 0.5, 0.95, 0.99, 1})
+		a := percentiles(h, []float64{0, 0.5, 0.95, 0.99, 1}, LinearInterpolation)
 		assert.Equal(t, []float64{0, 0, 0, 0, 0}, a)
 	})
 
@@ -157,7 +199,299 @@ func TestHistogramPercentiles(t *testing.T) {
 			Counts:  []uint64{0, 0, 0},
 			Buckets: []float64{1, 2, 3, 4},
 		}
-		a := percentiles(h, []float64{0, 0.5, 0.95, 0.99, 1})
+		a := percentiles(h, []float64{0, 0.5, 0.95, 0.99, 1}, LinearInterpolation)
 		assert.Equal(t, []float64{0, 0, 0, 0, 0}, a)
 	})
 }
+
+func TestPercentileInterpolation(t *testing.T) {
+	// Exponential buckets, the median is half way through [2, 4].
+	h := &metrics.Float64Histogram{
+		Counts:  []uint64{10, 20, 10, 0},
+		Buckets: []float64{1, 2, 4, 8, 16},
+	}
+	for _, test := range []struct {
+		name   string
+		interp PercentileInterpolation
+		median float64
+	}{
+		{"linear", LinearInterpolation, 3},
+		{"exponential", ExponentialInterpolation, 2 * math.Sqrt2},
+		{"none", NoInterpolation, 4},
+	} {
+		t.Run(test.name, func(t *testing.T) {
+			p := percentiles(h, []float64{0, 0.5, 1}, test.interp)
+			assert.InDelta(t, test.median, p[1], 1e-9)
+			assert.GreaterOrEqual(t, p[1], 2.0, "the median should lie within its bucket")
+			assert.LessOrEqual(t, p[1], 4.0, "the median should lie within its bucket")
+			assert.Equal(t, []float64{1, 8}, []float64{p[0], p[2]}, "the min and max should be the boundaries")
+		})
+	}
+
+	t.Run("interpolated percentiles should be within the snapped ones", func(t *testing.T) {
+		h := &metrics.Float64Histogram{
+			Counts:  []uint64{1, 2, 3, 4, 5, 6, 7, 8, 9, 10},
+			Buckets: []float64{1, 2, 4, 8, 16, 32, 64, 128, 256, 512, 1024},
+		}
+		ps := []float64{0.5, 0.95, 0.99}
+		snapped := percentiles(h, ps, NoInterpolation)
+		for _, interp := range []PercentileInterpolation{LinearInterpolation, ExponentialInterpolation} {
+			for i, p := range percentiles(h, ps, interp) {
+				assert.LessOrEqual(t, p, snapped[i])
+				assert.Greater(t, p, snapped[i]/2, "should be within the bucket below the snapped value")
+			}
+		}
+	})
+
+	t.Run("exponential interpolation should be linear for non-positive buckets", func(t *testing.T) {
+		h := &metrics.Float64Histogram{Counts: []uint64{2}, Buckets: []float64{0, 10}}
+		assert.Equal(t, percentiles(h, []float64{0.5}, LinearInterpolation), percentiles(h, []float64{0.5}, ExponentialInterpolation))
+	})
+
+	t.Run("should be applied to the reported summaries", func(t *testing.T) {
+		desc := metricDesc("/gc/pauses:seconds", metrics.KindFloat64Histogram)
+		f := &fakeSampler{steps: []map[string]value{
+			{desc.Name: histogramValue(&metrics.Float64Histogram{Counts: []uint64{0, 0, 0, 0}, Buckets: h.Buckets})},
+			{desc.Name: histogramValue(h)},
+		}}
+		mock := &statsdClientMock{}
+		rms := newRuntimeMetricStoreWithSampler([]metrics.Description{desc}, f, mock, &Options{Logger: slog.Default(), PercentileInterpolation: NoInterpolation})
+		rms.report()
+		assert.Equal(t, []float64{4}, gaugeValues(mock, "runtime.go.metrics.gc_pauses.seconds.median"))
+	})
+
+	t.Run("unsupported values should be rejected", func(t *testing.T) {
+		_, err := NewEmitter(&statsdClientMock{}, &Options{PercentileInterpolation: NoInterpolation + 1})
+		assert.Error(t, err)
+	})
+}
+
+func TestSummarizeHistogram(t *testing.T) {
+	prev := &metrics.Float64Histogram{
+		Counts:  []uint64{1, 0, 0, 0},
+		Buckets: []float64{0, 10, 20, 30, 40},
+	}
+	cur := &metrics.Float64Histogram{
+		Counts:  []uint64{1, 2, 0, 2},
+		Buckets: []float64{0, 10, 20, 30, 40},
+	}
+
+	t.Run("should summarize the values recorded since prev", func(t *testing.T) {
+		s, changed := SummarizeHistogram(prev, cur, []float64{0.5, 0.75})
+		assert.True(t, changed)
+		assert.Equal(t, HistogramSummary{
+			Count:       4,
+			Sum:         100,
+			Avg:         25,
+			Min:         10,
+			Max:         40,
+			Percentiles: []float64{20, 35},
+		}, s)
+	})
+
+	t.Run("should summarize all values without prev", func(t *testing.T) {
+		s, changed := SummarizeHistogram(nil, cur, nil)
+		assert.True(t, changed)
+		assert.Equal(t, uint64(5), s.Count)
+		assert.Equal(t, 0.0, s.Min)
+		assert.Empty(t, s.Percentiles)
+	})
+
+	t.Run("should report unchanged histograms", func(t *testing.T) {
+		_, changed := SummarizeHistogram(cur, cur, []float64{0.5})
+		assert.False(t, changed)
+
+		_, changed = SummarizeHistogram(nil, &metrics.Float64Histogram{Counts: []uint64{0}, Buckets: []float64{0, 1}}, nil)
+		assert.False(t, changed)
+	})
+
+	t.Run("should panic on invalid percentiles", func(t *testing.T) {
+		assert.Panics(t, func() { SummarizeHistogram(nil, cur, []float64{1.5}) })
+	})
+
+	t.Run("should match the reported summaries with HistogramPercentiles", func(t *testing.T) {
+		s, changed := SummarizeHistogram(prev, cur, HistogramPercentiles())
+		require.True(t, changed)
+		delta, _ := sub(cur, prev)
+		stats := statsFromHist(delta, LinearInterpolation)
+		assert.Equal(t, []float64{stats.Median, stats.P95, stats.P99}, s.Percentiles)
+	})
+}
+
+func TestExportedHistogramPercentiles(t *testing.T) {
+	assert.Equal(t, []float64{0.5, 0.95, 0.99}, HistogramPercentiles())
+	HistogramPercentiles()[0] = 0.9
+	assert.Equal(t, 0.5, HistogramPercentiles()[0], "callers shouldn't be able to change the percentiles")
+}
+
+func TestHistogramSummaries(t *testing.T) {
+	assert.Equal(t, []string{"avg", "min", "max", "median", "p95", "p99"}, HistogramSummaries())
+	HistogramSummaries()[0] = "p90"
+	assert.Equal(t, "avg", HistogramSummaries()[0], "callers shouldn't be able to change the summaries")
+}
+
+func TestMinHistogramSamples(t *testing.T) {
+	hist := func(counts ...uint64) value {
+		return histogramValue(&metrics.Float64Histogram{Counts: counts, Buckets: []float64{0, 1, 2}})
+	}
+	f := &fakeSampler{steps: []map[string]value{
+		{"/gc/pauses:seconds": hist(0, 0)},
+		{"/gc/pauses:seconds": hist(1, 1)},
+		{"/gc/pauses:seconds": hist(5, 2)},
+	}}
+	desc := metricDesc("/gc/pauses:seconds", metrics.KindFloat64Histogram)
+	mock := &statsdClientMock{}
+	rms := newRuntimeMetricStoreWithSampler([]metrics.Description{desc}, f, mock, &Options{Logger: slog.Default(), MinHistogramSamples: 5})
+
+	rms.report()
+	assert.Empty(t, mock.GaugeCalls(), "summaries of 2 values should be suppressed")
+	assert.NotEmpty(t, mock.DistributionCalls(), "distribution samples should be reported")
+
+	rms.report()
+	assert.Len(t, mock.GaugeCalls(), len(histogramStatNames), "summaries of 5 values should be reported")
+}
+
+func TestHistogramStatOverrides(t *testing.T) {
+	descs := []metrics.Description{
+		metricDesc("/gc/pauses:seconds", metrics.KindFloat64Histogram),
+		metricDesc("/sched/latencies:seconds", metrics.KindFloat64Histogram),
+		metricDesc("/gc/heap/allocs-by-size:bytes", metrics.KindFloat64Histogram),
+	}
+	// summaries returns the suffixes of the summaries reported for each
+	// Datadog metric name.
+	summaries := func(mock *statsdClientMock) map[string][]string {
+		res := map[string][]string{}
+		for _, c := range mock.GaugeCalls() {
+			i := strings.LastIndex(c.Name, ".")
+			res[c.Name[:i]] = append(res[c.Name[:i]], c.Name[i+1:])
+		}
+		return res
+	}
+
+	t.Run("should only report the given summaries", func(t *testing.T) {
+		mock := &statsdClientMock{}
+		rms := newRuntimeMetricStoreWithSampler(descs, changingSampler(descs, 2), mock, &Options{
+			Logger: slog.Default(),
+			HistogramStatOverrides: map[string][]string{
+				"/gc/pauses:seconds":       {"p99", "max"},
+				"/sched/latencies:seconds": {"max"},
+			},
+		})
+		rms.report()
+		assert.Equal(t, map[string][]string{
+			"runtime.go.metrics.gc_pauses.seconds":            {"max", "p99"},
+			"runtime.go.metrics.sched_latencies.seconds":      {"max"},
+			"runtime.go.metrics.gc_heap_allocs_by_size.bytes": {"avg", "min", "max", "median", "p95", "p99"},
+		}, summaries(mock))
+		assert.Len(t, mock.DistributionCalls(), 2*len(descs), "distributions should be reported")
+		assert.Equal(t, ExpectedGaugeCount(descs, &Options{
+			HistogramStatOverrides: map[string][]string{
+				"/gc/pauses:seconds":       {"p99", "max"},
+				"/sched/latencies:seconds": {"max"},
+			},
+		}), len(mock.GaugeCalls()))
+	})
+
+	t.Run("should only report the distribution without summaries", func(t *testing.T) {
+		mock := &statsdClientMock{}
+		rms := newRuntimeMetricStoreWithSampler(descs[:1], changingSampler(descs[:1], 2), mock, &Options{
+			Logger:                 slog.Default(),
+			HistogramStatOverrides: map[string][]string{"/gc/pauses:seconds": {}},
+		})
+		rms.report()
+		assert.Empty(t, mock.GaugeCalls())
+		assert.NotEmpty(t, mock.DistributionCalls())
+	})
+
+	t.Run("should tag the given summaries with PercentilesAsTags", func(t *testing.T) {
+		mock := &statsdClientMock{}
+		rms := newRuntimeMetricStoreWithSampler(descs[:1], changingSampler(descs[:1], 2), mock, &Options{
+			Logger:                 slog.Default(),
+			PercentilesAsTags:      true,
+			HistogramStatOverrides: map[string][]string{"/gc/pauses:seconds": {"p99"}},
+		})
+		rms.report()
+		calls := mock.GaugeCalls()
+		require.Len(t, calls, 1)
+		assert.Equal(t, "runtime.go.metrics.gc_pauses.seconds.summary", calls[0].Name)
+		assert.Contains(t, calls[0].Tags, "stat:p99")
+	})
+
+	t.Run("should reject unknown summaries", func(t *testing.T) {
+		_, err := NewEmitter(&statsdClientMock{}, &Options{
+			HistogramStatOverrides: map[string][]string{"/gc/pauses:seconds": {"p50"}},
+		})
+		assert.ErrorContains(t, err, `unknown histogram summary "p50"`)
+	})
+
+	t.Run("should reject metrics that aren't histograms", func(t *testing.T) {
+		_, err := NewEmitter(&statsdClientMock{}, &Options{
+			HistogramStatOverrides: map[string][]string{"/gc/heap/live:bytes": {"max"}},
+		})
+		assert.ErrorContains(t, err, "isn't a histogram")
+	})
+}
+
+func TestHistogramCounts(t *testing.T) {
+	t.Run("should report the number of values recorded since the last report", func(t *testing.T) {
+		hist := func(counts ...uint64) value {
+			return histogramValue(&metrics.Float64Histogram{Counts: counts, Buckets: []float64{0, 1, 2}})
+		}
+		f := &fakeSampler{steps: []map[string]value{
+			{"/gc/pauses:seconds": hist(1, 0)},
+			{"/gc/pauses:seconds": hist(2, 3)},
+			{"/gc/pauses:seconds": hist(2, 3)},
+			{"/gc/pauses:seconds": hist(4, 4)},
+		}}
+		desc := metricDesc("/gc/pauses:seconds", metrics.KindFloat64Histogram)
+		mock := &statsdClientMock{}
+		rms := newRuntimeMetricStoreWithSampler([]metrics.Description{desc}, f, mock, &Options{Logger: slog.Default(), HistogramCounts: true})
+		rms.report()
+		rms.report()
+		rms.report()
+
+		counts := mock.CountCalls()
+		require.Len(t, counts, 2, "unchanged histograms aren't reported")
+		assert.Equal(t, "runtime.go.metrics.gc_pauses.seconds.count", counts[0].Name)
+		assert.Equal(t, int64(4), counts[0].Value)
+		assert.Equal(t, int64(3), counts[1].Value)
+		assert.Equal(t, rms.baseTags, counts[0].Tags)
+	})
+
+	t.Run("should count the GC pauses", func(t *testing.T) {
+		descs := []metrics.Description{
+			metricDesc("/gc/pauses:seconds", metrics.KindFloat64Histogram),
+			metricDesc(gcCyclesMetricName, metrics.KindUint64),
+		}
+		mock := &statsdClientMock{}
+		rms := newRuntimeMetricStore(descs, mock, &Options{Logger: slog.Default(), HistogramCounts: true})
+		const n = 5
+		for i := 0; i < n; i++ {
+			runtime.GC()
+		}
+		rms.report()
+
+		cycles := rms.metrics[gcCyclesMetricName]
+		gcs := cycles.currentValue.Uint64() - cycles.previousValue.Uint64()
+		assert.GreaterOrEqual(t, gcs, uint64(n))
+		counts := mock.CountCalls()
+		require.Len(t, counts, 1)
+		// Each GC cycle stops the world twice: for sweep termination and
+		// mark termination.
+		assert.Equal(t, int64(2*gcs), counts[0].Value)
+	})
+
+	t.Run("should be part of the emitted series", func(t *testing.T) {
+		est, err := EstimateSeries(&Options{HistogramCounts: true})
+		require.NoError(t, err)
+		assert.Contains(t, est.Names, "runtime.go.metrics.gc_pauses.seconds.count")
+	})
+
+	t.Run("should be disabled by default", func(t *testing.T) {
+		mock := &statsdClientMock{}
+		rms := newRuntimeMetricStore(metrics.All(), mock, &Options{Logger: slog.Default()})
+		runtime.GC()
+		rms.report()
+		assert.Empty(t, mock.CallsWithSuffix(".count").Counts)
+	})
+}
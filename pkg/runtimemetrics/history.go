@@ -0,0 +1,86 @@
+package runtimemetrics
+
+import (
+	"runtime/metrics"
+	"slices"
+	"sync"
+	"time"
+)
+
+// Report is a report retained by Options.HistorySize, see Emitter.History.
+type Report struct {
+	ReportStats
+	// Samples are the gauges and counts submitted by the report, in
+	// submission order. Distribution samples aren't retained, only the
+	// summaries of histograms.
+	Samples []MetricEvent
+}
+
+// reportHistory is a ring buffer of the last reports.
+type reportHistory struct {
+	mu      sync.Mutex
+	reports []Report
+	// next is the index of reports overwritten by the next add once the
+	// buffer is full.
+	next int
+}
+
+func newReportHistory(size int) *reportHistory {
+	return &reportHistory{reports: make([]Report, 0, size)}
+}
+
+// add retains r, evicting the oldest report if the buffer is full.
+func (h *reportHistory) add(r Report) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	if len(h.reports) < cap(h.reports) {
+		h.reports = append(h.reports, r)
+		return
+	}
+	h.reports[h.next] = r
+	h.next = (h.next + 1) % len(h.reports)
+}
+
+// get returns copies of the retained reports, oldest first.
+func (h *reportHistory) get() []Report {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	res := make([]Report, 0, len(h.reports))
+	for i := range h.reports {
+		r := h.reports[(h.next+i)%len(h.reports)]
+		r.Tags = slices.Clone(r.Tags)
+		r.Samples = slices.Clone(r.Samples)
+		res = append(res, r)
+	}
+	return res
+}
+
+// historyRecorder forwards submissions to a statsd client, recording the
+// gauges and counts, see Options.HistorySize.
+type historyRecorder struct {
+	statsd  partialStatsdClientInterface
+	samples []MetricEvent
+}
+
+func (r *historyRecorder) GaugeWithTimestamp(name string, value float64, tags []string, rate float64, timestamp time.Time) error {
+	r.samples = append(r.samples, MetricEvent{Kind: GaugeMetric, Name: name, Value: value, Tags: slices.Clone(tags), Rate: rate, Timestamp: timestamp})
+	return r.statsd.GaugeWithTimestamp(name, value, tags, rate, timestamp)
+}
+
+func (r *historyRecorder) CountWithTimestamp(name string, value int64, tags []string, rate float64, timestamp time.Time) error {
+	r.samples = append(r.samples, MetricEvent{Kind: CountMetric, Name: name, Value: float64(value), Tags: slices.Clone(tags), Rate: rate, Timestamp: timestamp})
+	return r.statsd.CountWithTimestamp(name, value, tags, rate, timestamp)
+}
+
+func (r *historyRecorder) DistributionSamples(name string, values []float64, tags []string, rate float64) error {
+	return r.statsd.DistributionSamples(name, values, tags, rate)
+}
+
+// HistogramWithTimestamp implements histogramStatsdClient, if the statsd
+// client does. Histograms aren't retained.
+func (r *historyRecorder) HistogramWithTimestamp(name string, h *metrics.Float64Histogram, tags []string, timestamp time.Time) error {
+	if c, ok := r.statsd.(histogramStatsdClient); ok {
+		return c.HistogramWithTimestamp(name, h, tags, timestamp)
+	}
+	return nil
+}
@@ -0,0 +1,86 @@
+package runtimemetrics
+
+import (
+	"log/slog"
+	"runtime/metrics"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestHistory(t *testing.T) {
+	newEmitter := func(opts Options, steps ...map[string]value) (*Emitter, *statsdClientMock) {
+		opts.Logger = slog.Default()
+		mock := &statsdClientMock{}
+		descs := []metrics.Description{
+			metricDesc("/gc/heap/live:bytes", metrics.KindUint64),
+			metricDesc("/gc/pauses:seconds", metrics.KindFloat64Histogram),
+		}
+		rms := newRuntimeMetricStoreWithSampler(descs, &fakeSampler{steps: steps}, mock, &opts)
+		// Don't start the reporting goroutine, tests call report directly.
+		return &Emitter{rms: rms}, mock
+	}
+	step := func(live uint64, pauses ...uint64) map[string]value {
+		return map[string]value{
+			"/gc/heap/live:bytes": uint64Value(live),
+			"/gc/pauses:seconds": histogramValue(&metrics.Float64Histogram{
+				Counts:  pauses,
+				Buckets: []float64{0, 1, 2, 3},
+			}),
+		}
+	}
+
+	t.Run("should be disabled by default", func(t *testing.T) {
+		e, _ := newEmitter(Options{}, step(1, 0, 0, 0), step(2, 1, 0, 0))
+		e.store().report()
+		assert.Nil(t, e.History())
+	})
+
+	t.Run("should retain the last reports", func(t *testing.T) {
+		e, _ := newEmitter(Options{HistorySize: 2}, step(0, 0, 0, 0), step(1, 1, 0, 0), step(2, 2, 0, 0), step(3, 3, 0, 0))
+		for i := 0; i < 3; i++ {
+			e.store().report()
+		}
+		history := e.History()
+		require.Len(t, history, 2)
+		for i, value := range []float64{2, 3} {
+			var live []MetricEvent
+			for _, s := range history[i].Samples {
+				assert.NotEqual(t, DistributionMetric, s.Kind, "distribution samples shouldn't be retained")
+				if s.Name == "runtime.go.metrics.gc_heap_live.bytes" {
+					live = append(live, s)
+				}
+			}
+			require.Len(t, live, 1)
+			assert.Equal(t, value, live[0].Value)
+			assert.Equal(t, e.store().baseTags, history[i].Tags)
+		}
+		assert.True(t, history[0].Timestamp.Before(history[1].Timestamp) || history[0].Timestamp.Equal(history[1].Timestamp))
+
+		history[1].Samples[0].Name = "modified"
+		assert.NotEqual(t, "modified", e.History()[1].Samples[0].Name, "the history should be copied")
+	})
+
+	t.Run("should not race with reports", func(t *testing.T) {
+		steps := make([]map[string]value, 100)
+		for i := range steps {
+			steps[i] = step(uint64(i), uint64(i), 0, 0)
+		}
+		e, _ := newEmitter(Options{HistorySize: 3}, steps...)
+		var wg sync.WaitGroup
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := 0; i < 50; i++ {
+				e.store().report()
+			}
+		}()
+		for i := 0; i < 50; i++ {
+			assert.LessOrEqual(t, len(e.History()), 3)
+		}
+		wg.Wait()
+		assert.Len(t, e.History(), 3)
+	})
+}
@@ -0,0 +1,93 @@
+package runtimemetrics
+
+import (
+	"fmt"
+	"io"
+	"math"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// InfluxWriter is a statsd client replacement that writes metrics to an
+// io.Writer using the InfluxDB line protocol, e.g. for ingestion by Telegraf.
+//
+// Every metric is written as a field of the same measurement, named after the
+// Datadog metric name without the "runtime.go.metrics." prefix. Tags are
+// written as InfluxDB tags, e.g. "gogc:100" becomes "gogc=100". For example:
+//
+//	go_runtime,gogc=100,gomaxprocs=8 gc_pauses.seconds.p99=0.000123 1700000000000000000
+//
+// Distribution samples have no equivalent in the line protocol and are
+// dropped, histograms are written through their summary fields (.avg, .p99,
+// ...) only.
+type InfluxWriter struct {
+	mu          sync.Mutex
+	w           io.Writer
+	measurement string
+	buf         []byte
+}
+
+// NewInfluxWriter returns an InfluxWriter writing to w under the given
+// measurement name. It can be passed to NewEmitter instead of a statsd client.
+func NewInfluxWriter(w io.Writer, measurement string) *InfluxWriter {
+	return &InfluxWriter{w: w, measurement: measurement}
+}
+
+// GaugeWithTimestamp writes the gauge as a float field.
+func (iw *InfluxWriter) GaugeWithTimestamp(name string, value float64, tags []string, _ float64, timestamp time.Time) error {
+	if math.IsNaN(value) || math.IsInf(value, 0) {
+		return fmt.Errorf("influx line protocol does not support value %v for metric %s", value, name)
+	}
+	return iw.writeLine(name, strconv.FormatFloat(value, 'f', -1, 64), tags, timestamp)
+}
+
+// CountWithTimestamp writes the count as an integer field.
+func (iw *InfluxWriter) CountWithTimestamp(name string, value int64, tags []string, _ float64, timestamp time.Time) error {
+	return iw.writeLine(name, strconv.FormatInt(value, 10)+"i", tags, timestamp)
+}
+
+// DistributionSamples drops the samples, see InfluxWriter.
+func (iw *InfluxWriter) DistributionSamples(string, []float64, []string, float64) error {
+	return nil
+}
+
+func (iw *InfluxWriter) writeLine(name, value string, tags []string, timestamp time.Time) error {
+	iw.mu.Lock()
+	defer iw.mu.Unlock()
+
+	b := iw.buf[:0]
+	b = append(b, influxMeasurementEscaper.Replace(iw.measurement)...)
+	for _, tag := range tags {
+		k, v, ok := strings.Cut(tag, ":")
+		if !ok {
+			// The line protocol has no notion of valueless tags.
+			v = "true"
+		}
+		if k == "" || v == "" {
+			continue
+		}
+		b = append(b, ',')
+		b = append(b, influxKeyEscaper.Replace(k)...)
+		b = append(b, '=')
+		b = append(b, influxKeyEscaper.Replace(v)...)
+	}
+	b = append(b, ' ')
+	b = append(b, influxKeyEscaper.Replace(strings.TrimPrefix(name, datadogMetricPrefix))...)
+	b = append(b, '=')
+	b = append(b, value...)
+	b = append(b, ' ')
+	b = strconv.AppendInt(b, timestamp.UnixNano(), 10)
+	b = append(b, '\n')
+	iw.buf = b
+
+	_, err := iw.w.Write(b)
+	return err
+}
+
+var (
+	// see https://docs.influxdata.com/influxdb/v2/reference/syntax/line-protocol/#special-characters
+	influxMeasurementEscaper = strings.NewReplacer(`,`, `\,`, ` `, `\ `)
+	influxKeyEscaper         = strings.NewReplacer(`,`, `\,`, `=`, `\=`, ` `, `\ `)
+)
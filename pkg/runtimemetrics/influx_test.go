@@ -0,0 +1,57 @@
+package runtimemetrics
+
+import (
+	"bytes"
+	"log/slog"
+	"math"
+	"runtime"
+	"runtime/metrics"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestInfluxWriter(t *testing.T) {
+	ts := time.Unix(1700000000, 123)
+
+	t.Run("should write a gauge with tags", func(t *testing.T) {
+		var buf bytes.Buffer
+		w := NewInfluxWriter(&buf, "go_runtime")
+		err := w.GaugeWithTimestamp("runtime.go.metrics.gc_heap_goal.bytes", 4194304, []string{"gogc:100", "gomemlimit:8 GiB", "env:a=b,c"}, 1, ts)
+		require.NoError(t, err)
+		assert.Equal(t, `go_runtime,gogc=100,gomemlimit=8\ GiB,env=a\=b\,c gc_heap_goal.bytes=4194304 1700000000000000123`+"\n", buf.String())
+	})
+
+	t.Run("should write a count as an integer field", func(t *testing.T) {
+		var buf bytes.Buffer
+		w := NewInfluxWriter(&buf, "go runtime")
+		err := w.CountWithTimestamp("runtime.go.metrics.skipped_values", 3, nil, 1, ts)
+		require.NoError(t, err)
+		assert.Equal(t, `go\ runtime skipped_values=3i 1700000000000000123`+"\n", buf.String())
+	})
+
+	t.Run("should reject values not supported by the line protocol", func(t *testing.T) {
+		var buf bytes.Buffer
+		w := NewInfluxWriter(&buf, "go_runtime")
+		assert.Error(t, w.GaugeWithTimestamp("runtime.go.metrics.foo", math.Inf(1), nil, 1, ts))
+		assert.Empty(t, buf.String())
+	})
+
+	t.Run("should write histogram summaries from a report", func(t *testing.T) {
+		var buf bytes.Buffer
+		desc := metricDesc("/gc/pauses:seconds", metrics.KindFloat64Histogram)
+		rms := newRuntimeMetricStore([]metrics.Description{desc}, NewInfluxWriter(&buf, "go_runtime"), &Options{Logger: slog.Default()})
+		runtime.GC()
+		rms.report()
+
+		lines := strings.Split(strings.TrimSpace(buf.String()), "\n")
+		require.Len(t, lines, 6)
+		for _, line := range lines {
+			assert.True(t, strings.HasPrefix(line, "go_runtime,gogc="), line)
+			assert.Contains(t, line, " gc_pauses.seconds.")
+		}
+	})
+}
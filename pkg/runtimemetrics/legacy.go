@@ -0,0 +1,205 @@
+package runtimemetrics
+
+import (
+	"log/slog"
+	"math"
+	"runtime/metrics"
+	"slices"
+	"time"
+)
+
+// LegacyMetric describes how a runtime metric reported by dd-trace-go v1,
+// based on runtime.ReadMemStats and debug.ReadGCStats, is computed from
+// runtime/metrics when Options.EmitLegacyNames is set.
+//
+// The runtime/metrics equivalents are documented in [1]. They are close, but
+// not always identical to their runtime.MemStats counterparts, e.g. the
+// pause quantiles are computed over the pauses of the reporting period rather
+// than over the last 256 pauses.
+//
+// [1] https://pkg.go.dev/runtime/metrics#hdr-Supported_metrics
+type LegacyMetric struct {
+	// Name is the dd-trace-go v1 metric name.
+	Name string
+	// RuntimeMetrics are the runtime/metrics whose values are summed up to
+	// compute the legacy value.
+	RuntimeMetrics []string
+	// Quantile is the quantile reported for legacy metrics computed from a
+	// runtime/metrics histogram, in which case RuntimeMetrics contains a
+	// single histogram.
+	Quantile float64
+	// Scale multiplies the runtime/metrics value, e.g. to convert seconds to
+	// nanoseconds. Zero means 1.
+	Scale float64
+}
+
+// legacyMetrics is the mapping table of the dd-trace-go v1 runtime metrics.
+//
+// The following v1 metrics have no runtime/metrics equivalent and are not
+// emitted in legacy form: runtime.go.num_cpu, runtime.go.mem_stats.lookups,
+// runtime.go.mem_stats.last_gc, runtime.go.mem_stats.pause_total_ns and
+// runtime.go.mem_stats.gc_cpu_fraction.
+var legacyMetrics = []LegacyMetric{
+	{Name: "runtime.go.num_goroutine", RuntimeMetrics: []string{"/sched/goroutines:goroutines"}},
+	{Name: "runtime.go.num_cgo_call", RuntimeMetrics: []string{"/cgo/go-to-c-calls:calls"}},
+	{Name: "runtime.go.mem_stats.alloc", RuntimeMetrics: []string{"/memory/classes/heap/objects:bytes"}},
+	{Name: "runtime.go.mem_stats.total_alloc", RuntimeMetrics: []string{"/gc/heap/allocs:bytes"}},
+	{Name: "runtime.go.mem_stats.sys", RuntimeMetrics: []string{"/memory/classes/total:bytes"}},
+	{Name: "runtime.go.mem_stats.mallocs", RuntimeMetrics: []string{"/gc/heap/allocs:objects"}},
+	{Name: "runtime.go.mem_stats.frees", RuntimeMetrics: []string{"/gc/heap/frees:objects"}},
+	{Name: "runtime.go.mem_stats.heap_alloc", RuntimeMetrics: []string{"/memory/classes/heap/objects:bytes"}},
+	{Name: "runtime.go.mem_stats.heap_sys", RuntimeMetrics: []string{
+		"/memory/classes/heap/objects:bytes",
+		"/memory/classes/heap/unused:bytes",
+		"/memory/classes/heap/free:bytes",
+		"/memory/classes/heap/released:bytes",
+	}},
+	{Name: "runtime.go.mem_stats.heap_idle", RuntimeMetrics: []string{
+		"/memory/classes/heap/free:bytes",
+		"/memory/classes/heap/released:bytes",
+	}},
+	{Name: "runtime.go.mem_stats.heap_inuse", RuntimeMetrics: []string{
+		"/memory/classes/heap/objects:bytes",
+		"/memory/classes/heap/unused:bytes",
+	}},
+	{Name: "runtime.go.mem_stats.heap_released", RuntimeMetrics: []string{"/memory/classes/heap/released:bytes"}},
+	{Name: "runtime.go.mem_stats.heap_objects", RuntimeMetrics: []string{"/gc/heap/objects:objects"}},
+	{Name: "runtime.go.mem_stats.stack_inuse", RuntimeMetrics: []string{"/memory/classes/heap/stacks:bytes"}},
+	{Name: "runtime.go.mem_stats.stack_sys", RuntimeMetrics: []string{
+		"/memory/classes/heap/stacks:bytes",
+		"/memory/classes/os-stacks:bytes",
+	}},
+	{Name: "runtime.go.mem_stats.m_span_inuse", RuntimeMetrics: []string{"/memory/classes/metadata/mspan/inuse:bytes"}},
+	{Name: "runtime.go.mem_stats.m_span_sys", RuntimeMetrics: []string{
+		"/memory/classes/metadata/mspan/inuse:bytes",
+		"/memory/classes/metadata/mspan/free:bytes",
+	}},
+	{Name: "runtime.go.mem_stats.m_cache_inuse", RuntimeMetrics: []string{"/memory/classes/metadata/mcache/inuse:bytes"}},
+	{Name: "runtime.go.mem_stats.m_cache_sys", RuntimeMetrics: []string{
+		"/memory/classes/metadata/mcache/inuse:bytes",
+		"/memory/classes/metadata/mcache/free:bytes",
+	}},
+	{Name: "runtime.go.mem_stats.buck_hash_sys", RuntimeMetrics: []string{"/memory/classes/profiling/buckets:bytes"}},
+	{Name: "runtime.go.mem_stats.gc_sys", RuntimeMetrics: []string{"/memory/classes/metadata/other:bytes"}},
+	{Name: "runtime.go.mem_stats.other_sys", RuntimeMetrics: []string{"/memory/classes/other:bytes"}},
+	{Name: "runtime.go.mem_stats.next_gc", RuntimeMetrics: []string{"/gc/heap/goal:bytes"}},
+	{Name: "runtime.go.mem_stats.num_gc", RuntimeMetrics: []string{"/gc/cycles/total:gc-cycles"}},
+	{Name: "runtime.go.mem_stats.num_forced_gc", RuntimeMetrics: []string{"/gc/cycles/forced:gc-cycles"}},
+	{Name: "runtime.go.gc_stats.pause_quantiles.min", RuntimeMetrics: []string{"/gc/pauses:seconds"}, Quantile: 0, Scale: 1e9},
+	{Name: "runtime.go.gc_stats.pause_quantiles.25p", RuntimeMetrics: []string{"/gc/pauses:seconds"}, Quantile: 0.25, Scale: 1e9},
+	{Name: "runtime.go.gc_stats.pause_quantiles.50p", RuntimeMetrics: []string{"/gc/pauses:seconds"}, Quantile: 0.5, Scale: 1e9},
+	{Name: "runtime.go.gc_stats.pause_quantiles.75p", RuntimeMetrics: []string{"/gc/pauses:seconds"}, Quantile: 0.75, Scale: 1e9},
+	{Name: "runtime.go.gc_stats.pause_quantiles.max", RuntimeMetrics: []string{"/gc/pauses:seconds"}, Quantile: 1, Scale: 1e9},
+}
+
+// LegacyMetrics returns a copy of the mapping table used to compute the
+// dd-trace-go v1 runtime metrics, see Options.EmitLegacyNames.
+func LegacyMetrics() []LegacyMetric {
+	res := make([]LegacyMetric, len(legacyMetrics))
+	for i, m := range legacyMetrics {
+		m.RuntimeMetrics = slices.Clone(m.RuntimeMetrics)
+		res[i] = m
+	}
+	return res
+}
+
+// resolvedLegacyMetric is a LegacyMetric whose runtime metrics are all
+// collected by the store.
+type resolvedLegacyMetric struct {
+	LegacyMetric
+	inputs []*runtimeMetric
+}
+
+// resolveLegacyMetrics returns the legacy metrics that can be computed from
+// the metrics of the store.
+func resolveLegacyMetrics(store map[string]*runtimeMetric) []resolvedLegacyMetric {
+	var res []resolvedLegacyMetric
+outer:
+	for _, m := range legacyMetrics {
+		inputs := make([]*runtimeMetric, 0, len(m.RuntimeMetrics))
+		for _, name := range m.RuntimeMetrics {
+			rm, ok := store[name]
+			if !ok {
+				continue outer
+			}
+			inputs = append(inputs, rm)
+		}
+		if m.Scale == 0 {
+			m.Scale = 1
+		}
+		res = append(res, resolvedLegacyMetric{LegacyMetric: m, inputs: inputs})
+	}
+	return res
+}
+
+// value returns the legacy value, and false if it can't be computed for the
+// current report. The deltas of cumulative histograms are computed into
+// delta, a buffer of the store, and their quantiles with interp, see
+// Options.PercentileInterpolation.
+func (m *resolvedLegacyMetric) value(delta *metrics.Float64Histogram, interp PercentileInterpolation) (float64, bool) {
+	var sum float64
+	for _, rm := range m.inputs {
+		switch rm.currentValue.Kind() {
+		case metrics.KindUint64:
+			v := rm.currentValue.Uint64()
+			if v > math.MaxUint64/2 {
+				// see the skipped_values handling in report
+				return 0, false
+			}
+			sum += float64(v)
+		case metrics.KindFloat64:
+			sum += rm.currentValue.Float64()
+		case metrics.KindFloat64Histogram:
+			h := rm.currentValue.Float64Histogram()
+			if rm.cumulative {
+				if subInto(delta, h, rm.previousHistogram()) {
+					return 0, false
+				}
+				h = delta
+			}
+			p, q := [1]float64{m.Quantile}, [1]float64{}
+			sortedPercentiles(h, p[:], q[:], interp)
+			sum += q[0]
+		default:
+			return 0, false
+		}
+	}
+	return sum * m.Scale, true
+}
+
+// fromDeltas returns true if the legacy value is computed from the deltas of
+// cumulative histograms, see Options.WarmupPeriods.
+func (m *resolvedLegacyMetric) fromDeltas() bool {
+	for _, rm := range m.inputs {
+		if rm.cumulative && rm.currentValue.Kind() == metrics.KindFloat64Histogram {
+			return true
+		}
+	}
+	return false
+}
+
+// dualEmitLogInterval is the interval at which the DualEmitUntil countdown
+// is logged.
+const dualEmitLogInterval = 7 * 24 * time.Hour
+
+// dualEmitting returns false once the Options.DualEmitUntil deadline has been
+// reached, logging a countdown warning weekly before that.
+func (rms *runtimeMetricStore) dualEmitting(now time.Time) bool {
+	if rms.dualEmitUntil.IsZero() {
+		return true
+	}
+	if !now.Before(rms.dualEmitUntil) {
+		rms.logger.Warn("runtimemetrics: dual emission deadline reached, legacy metric names are no longer reported",
+			slog.Attr{Key: "deadline", Value: slog.TimeValue(rms.dualEmitUntil)},
+		)
+		return false
+	}
+	if rms.lastDualEmitLog.IsZero() || now.Sub(rms.lastDualEmitLog) >= dualEmitLogInterval {
+		rms.lastDualEmitLog = now
+		rms.logger.Warn("runtimemetrics: legacy metric names are reported alongside runtime.go.metrics.* until the deadline, migrate your dashboards and monitors",
+			slog.Attr{Key: "deadline", Value: slog.TimeValue(rms.dualEmitUntil)},
+			slog.Attr{Key: "days_left", Value: slog.IntValue(int(rms.dualEmitUntil.Sub(now).Hours() / 24))},
+		)
+	}
+	return true
+}
@@ -0,0 +1,154 @@
+package runtimemetrics
+
+import (
+	"bytes"
+	"log/slog"
+	"runtime"
+	"runtime/metrics"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestLegacyMetrics(t *testing.T) {
+	byName := map[string]LegacyMetric{}
+	for _, m := range LegacyMetrics() {
+		byName[m.Name] = m
+	}
+
+	t.Run("should pin a few mappings", func(t *testing.T) {
+		assert.Equal(t, []string{"/memory/classes/heap/objects:bytes"}, byName["runtime.go.mem_stats.heap_alloc"].RuntimeMetrics)
+		assert.Equal(t, []string{"/sched/goroutines:goroutines"}, byName["runtime.go.num_goroutine"].RuntimeMetrics)
+		assert.Equal(t, []string{"/gc/heap/goal:bytes"}, byName["runtime.go.mem_stats.next_gc"].RuntimeMetrics)
+		assert.Equal(t, []string{"/memory/classes/heap/free:bytes", "/memory/classes/heap/released:bytes"}, byName["runtime.go.mem_stats.heap_idle"].RuntimeMetrics)
+		p75 := byName["runtime.go.gc_stats.pause_quantiles.75p"]
+		assert.Equal(t, []string{"/gc/pauses:seconds"}, p75.RuntimeMetrics)
+		assert.Equal(t, 0.75, p75.Quantile)
+		assert.Equal(t, 1e9, p75.Scale)
+
+		for _, gap := range []string{"runtime.go.num_cpu", "runtime.go.mem_stats.lookups", "runtime.go.mem_stats.gc_cpu_fraction"} {
+			assert.NotContains(t, byName, gap)
+		}
+	})
+
+	t.Run("should only reference existing runtime metrics", func(t *testing.T) {
+		all := map[string]bool{}
+		for _, d := range metrics.All() {
+			all[d.Name] = true
+		}
+		for _, m := range LegacyMetrics() {
+			assert.True(t, strings.HasPrefix(m.Name, "runtime.go."), m.Name)
+			for _, name := range m.RuntimeMetrics {
+				assert.True(t, all[name], "%s: unknown runtime metric %s", m.Name, name)
+			}
+		}
+	})
+
+	t.Run("should return a copy", func(t *testing.T) {
+		LegacyMetrics()[0].RuntimeMetrics[0] = "mutated"
+		assert.NotEqual(t, "mutated", LegacyMetrics()[0].RuntimeMetrics[0])
+	})
+}
+
+func TestEmitLegacyNames(t *testing.T) {
+	t.Run("should not emit legacy names by default", func(t *testing.T) {
+		mock := &statsdClientMock{}
+		rms := newRuntimeMetricStore(metrics.All(), mock, &Options{Logger: slog.Default()})
+		rms.report()
+		for _, call := range mock.GaugeCalls() {
+			assert.True(t, strings.HasPrefix(call.Name, "runtime.go.metrics."), call.Name)
+		}
+	})
+
+	t.Run("should emit legacy names alongside the runtime metrics", func(t *testing.T) {
+		mock := &statsdClientMock{}
+		rms := newRuntimeMetricStore(metrics.All(), mock, &Options{Logger: slog.Default(), EmitLegacyNames: true})
+		runtime.GC()
+		rms.report()
+
+		gauges := map[string]float64{}
+		for _, call := range mock.GaugeCalls() {
+			gauges[call.Name] = call.Value
+		}
+		assert.Positive(t, gauges["runtime.go.num_goroutine"])
+		assert.Positive(t, gauges["runtime.go.mem_stats.heap_alloc"])
+		assert.Equal(t, gauges["runtime.go.metrics.memory_classes_heap_objects.bytes"], gauges["runtime.go.mem_stats.heap_alloc"])
+		assert.GreaterOrEqual(t, gauges["runtime.go.mem_stats.num_gc"], 1.0)
+		require.Contains(t, gauges, "runtime.go.gc_stats.pause_quantiles.max")
+		assert.InDelta(t, gauges["runtime.go.metrics.gc_pauses.seconds.max"]*1e9, gauges["runtime.go.gc_stats.pause_quantiles.max"], 1)
+		assert.NotContains(t, gauges, "runtime.go.num_cpu")
+	})
+
+	t.Run("should use the percentile interpolation of the store", func(t *testing.T) {
+		mock := &statsdClientMock{}
+		desc := metricDesc("/gc/pauses:seconds", metrics.KindFloat64Histogram)
+		buckets := []float64{0, 1e-6, 1e-3, 1}
+		sampler := &fakeSampler{steps: []map[string]value{
+			{desc.Name: histogramValue(&metrics.Float64Histogram{Counts: []uint64{0, 0, 0}, Buckets: buckets})},
+			{desc.Name: histogramValue(&metrics.Float64Histogram{Counts: []uint64{0, 4, 0}, Buckets: buckets})},
+		}}
+		rms := newRuntimeMetricStoreWithSampler([]metrics.Description{desc}, sampler, mock, &Options{
+			Logger:                  slog.Default(),
+			EmitLegacyNames:         true,
+			PercentileInterpolation: NoInterpolation,
+		})
+		rms.report()
+
+		gauges := map[string]float64{}
+		for _, call := range mock.GaugeCalls() {
+			gauges[call.Name] = call.Value
+		}
+		require.Contains(t, gauges, "runtime.go.gc_stats.pause_quantiles.50p")
+		assert.Equal(t, 1e-3*1e9, gauges["runtime.go.gc_stats.pause_quantiles.50p"])
+		assert.Equal(t, gauges["runtime.go.metrics.gc_pauses.seconds.median"]*1e9, gauges["runtime.go.gc_stats.pause_quantiles.50p"])
+	})
+
+	t.Run("should skip legacy metrics whose inputs are not collected", func(t *testing.T) {
+		mock := &statsdClientMock{}
+		desc := metricDesc("/sched/goroutines:goroutines", metrics.KindUint64)
+		rms := newRuntimeMetricStore([]metrics.Description{desc}, mock, &Options{Logger: slog.Default(), EmitLegacyNames: true})
+		rms.report()
+		require.Len(t, mock.GaugeCalls(), 2)
+		assert.Equal(t, "runtime.go.num_goroutine", mock.GaugeCalls()[1].Name)
+	})
+}
+
+func TestDualEmitUntil(t *testing.T) {
+	deadline := time.Date(2024, 6, 1, 0, 0, 0, 0, time.UTC)
+	var logs bytes.Buffer
+	logger := slog.New(slog.NewTextHandler(&logs, nil))
+	mock := &statsdClientMock{}
+	desc := metricDesc("/sched/goroutines:goroutines", metrics.KindUint64)
+	rms := newRuntimeMetricStore([]metrics.Description{desc}, mock, &Options{Logger: logger, DualEmitUntil: deadline})
+
+	// reportAt reports at the given time and returns the emitted names.
+	reportAt := func(now time.Time) []string {
+		rms.now = func() time.Time { return now }
+		mock.Reset()
+		rms.report()
+		var names []string
+		for _, call := range mock.GaugeCalls() {
+			names = append(names, call.Name)
+		}
+		return names
+	}
+	v1, v2 := "runtime.go.num_goroutine", "runtime.go.metrics.sched_goroutines.goroutines"
+
+	assert.Equal(t, []string{v2, v1}, reportAt(deadline.Add(-8*24*time.Hour)))
+	assert.Equal(t, 1, strings.Count(logs.String(), "days_left=8"))
+	assert.Equal(t, []string{v2, v1}, reportAt(deadline.Add(-7*24*time.Hour)))
+	assert.Equal(t, 1, strings.Count(logs.String(), "days_left="), "countdown is logged weekly")
+	assert.Equal(t, []string{v2, v1}, reportAt(deadline.Add(-24*time.Hour)))
+	assert.Equal(t, 1, strings.Count(logs.String(), "days_left=1"))
+
+	// cutover: a report before the deadline emits both, one after only v2
+	assert.Equal(t, []string{v2, v1}, reportAt(deadline.Add(-time.Second)))
+	assert.Equal(t, []string{v2}, reportAt(deadline.Add(time.Second)))
+	assert.Contains(t, logs.String(), "dual emission deadline reached")
+	logs.Reset()
+	assert.Equal(t, []string{v2}, reportAt(deadline.Add(30*24*time.Hour)))
+	assert.Empty(t, logs.String())
+}
@@ -0,0 +1,47 @@
+package runtimemetrics
+
+import (
+	"runtime"
+	"time"
+)
+
+// DefaultMemStatsPeriod is the default value of Options.MemStatsPeriod.
+const DefaultMemStatsPeriod = time.Minute
+
+// memStatsMetricNames are the names of the gauges reported by
+// memStatsCollector.
+var memStatsMetricNames = []string{
+	"runtime.go.metrics.memstats.mallocs",
+	"runtime.go.metrics.memstats.frees",
+	"runtime.go.metrics.memstats.num_forced_gc",
+	"runtime.go.metrics.memstats.gc_cpu_fraction",
+	"runtime.go.metrics.memstats.seconds_since_last_gc",
+}
+
+// memStatsCollector reports a curated set of runtime.MemStats fields that
+// have no direct runtime/metrics equivalent, see Options.MemStats.
+type memStatsCollector struct {
+	period   time.Duration
+	lastRead time.Time
+	ms       runtime.MemStats
+}
+
+// report reads the memstats if the period elapsed since the last read, and
+// submits them with the given timestamp and tags.
+func (c *memStatsCollector) report(statsd partialStatsdClientInterface, tags []string, timestamp time.Time) {
+	if !c.lastRead.IsZero() && timestamp.Sub(c.lastRead) < c.period {
+		return
+	}
+	c.lastRead = timestamp
+	// Note: This stops the world, see Options.MemStats.
+	runtime.ReadMemStats(&c.ms)
+
+	statsd.GaugeWithTimestamp("runtime.go.metrics.memstats.mallocs", float64(c.ms.Mallocs), tags, 1, timestamp)
+	statsd.GaugeWithTimestamp("runtime.go.metrics.memstats.frees", float64(c.ms.Frees), tags, 1, timestamp)
+	statsd.GaugeWithTimestamp("runtime.go.metrics.memstats.num_forced_gc", float64(c.ms.NumForcedGC), tags, 1, timestamp)
+	statsd.GaugeWithTimestamp("runtime.go.metrics.memstats.gc_cpu_fraction", c.ms.GCCPUFraction, tags, 1, timestamp)
+	if c.ms.LastGC != 0 {
+		sinceLastGC := timestamp.Sub(time.Unix(0, int64(c.ms.LastGC))).Seconds()
+		statsd.GaugeWithTimestamp("runtime.go.metrics.memstats.seconds_since_last_gc", sinceLastGC, tags, 1, timestamp)
+	}
+}
@@ -0,0 +1,49 @@
+package runtimemetrics
+
+import (
+	"log/slog"
+	"runtime"
+	"runtime/metrics"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestMemStats(t *testing.T) {
+	mock := &statsdClientMock{}
+	rms := newRuntimeMetricStore([]metrics.Description{}, mock, &Options{
+		Logger:         slog.Default(),
+		Tags:           []string{"service:foo"},
+		MemStats:       true,
+		MemStatsPeriod: time.Minute,
+	})
+	start := time.Now()
+	reportAt := func(now time.Time) map[string]float64 {
+		rms.now = func() time.Time { return now }
+		mock.Reset()
+		rms.report()
+		gauges := map[string]float64{}
+		for _, call := range mock.GaugeCalls() {
+			require.True(t, strings.HasPrefix(call.Name, "runtime.go.metrics.memstats."), call.Name)
+			require.Contains(t, call.Tags, "service:foo")
+			gauges[call.Name] = call.Value
+		}
+		return gauges
+	}
+
+	runtime.GC()
+	gauges := reportAt(start)
+	assert.Positive(t, gauges["runtime.go.metrics.memstats.mallocs"])
+	assert.Positive(t, gauges["runtime.go.metrics.memstats.frees"])
+	assert.GreaterOrEqual(t, gauges["runtime.go.metrics.memstats.num_forced_gc"], 1.0)
+	assert.Contains(t, gauges, "runtime.go.metrics.memstats.gc_cpu_fraction")
+	assert.InDelta(t, 0, gauges["runtime.go.metrics.memstats.seconds_since_last_gc"], 5)
+
+	assert.Empty(t, reportAt(start.Add(30*time.Second)), "memstats are read at a reduced cadence")
+	gauges = reportAt(start.Add(time.Minute))
+	assert.Len(t, gauges, 5)
+	assert.InDelta(t, 60, gauges["runtime.go.metrics.memstats.seconds_since_last_gc"], 5)
+}
@@ -0,0 +1,41 @@
+package runtimemetrics
+
+import "slices"
+
+//go:generate go run ../../tools/metricmetadata -format go -o metadata_generated.go
+
+// MetricMetadata describes a metric reported with the default options and
+// Options.DerivedMetrics, see Metadata.
+type MetricMetadata struct {
+	// Name is the Datadog name of the metric.
+	Name string
+	// RuntimeName is the runtime/metrics name the metric is reported from,
+	// or "" for derived metrics.
+	RuntimeName string
+	// Type is the Datadog type of the metric: gauge or distribution.
+	Type string
+	// Unit and PerUnit are the Datadog units of the metric, e.g. byte and
+	// second for bytes per second. Both may be empty.
+	Unit    string
+	PerUnit string
+	// Description is a single-line description of at most 400 characters.
+	Description string
+	// Orientation is -1 for metrics where lower values are better, such as
+	// pauses and latencies, 1 for metrics where higher values are better, and
+	// 0 otherwise.
+	Orientation int
+	// ShortName is a human-readable name of the metric.
+	ShortName string
+}
+
+// Metadata returns the metadata of the metrics reported by the library,
+// generated from the runtime metrics of MetadataGoVersion and compiled into
+// the package, so it can't drift from the code it describes. The rows are
+// sorted by runtime metric name, with the summaries of histograms following
+// their distribution, and the derived metrics last.
+//
+// Metrics that the running Go version doesn't provide are included, see
+// SupportedMetrics for those actually reported.
+func Metadata() []MetricMetadata {
+	return slices.Clone(generatedMetadata)
+}
@@ -0,0 +1,170 @@
+// Code generated by go run ./tools/metricmetadata -format go. DO NOT EDIT.
+
+package runtimemetrics
+
+// MetadataGoVersion is the Go version Metadata was generated with.
+const MetadataGoVersion = "go1.27"
+
+var generatedMetadata = []MetricMetadata{
+	{Name: "runtime.go.metrics.cgo_go_to_c_calls.calls", RuntimeName: "/cgo/go-to-c-calls:calls", Type: "gauge", Unit: "", PerUnit: "", Description: "Count of calls made from Go to C by the current process.", Orientation: 0, ShortName: "cgo go to c calls"},
+	{Name: "runtime.go.metrics.cpu_classes_gc_mark_assist.cpu_seconds", RuntimeName: "/cpu/classes/gc/mark/assist:cpu-seconds", Type: "gauge", Unit: "", PerUnit: "", Description: "Estimated total CPU time goroutines spent performing GC tasks to assist the GC and prevent it from falling behind the application. This metric is an overestimate, and not directly comparable to system CPU time measurements. Compare only with other /cpu/classes metrics.", Orientation: -1, ShortName: "cpu classes gc mark assist"},
+	{Name: "runtime.go.metrics.cpu_classes_gc_mark_dedicated.cpu_seconds", RuntimeName: "/cpu/classes/gc/mark/dedicated:cpu-seconds", Type: "gauge", Unit: "", PerUnit: "", Description: "Estimated total CPU time spent performing GC tasks on processors (as defined by GOMAXPROCS) dedicated to those tasks. This metric is an overestimate, and not directly comparable to system CPU time measurements. Compare only with other /cpu/classes metrics.", Orientation: -1, ShortName: "cpu classes gc mark dedicated"},
+	{Name: "runtime.go.metrics.cpu_classes_gc_mark_idle.cpu_seconds", RuntimeName: "/cpu/classes/gc/mark/idle:cpu-seconds", Type: "gauge", Unit: "", PerUnit: "", Description: "Estimated total CPU time spent performing GC tasks on spare CPU resources that the Go scheduler could not otherwise find a use for. This should be subtracted from the total GC CPU time to obtain a measure of compulsory GC CPU time. This metric is an overestimate, and not directly comparable to system CPU time measurements. Compare only with other /cpu/classes metrics.", Orientation: 0, ShortName: "cpu classes gc mark idle"},
+	{Name: "runtime.go.metrics.cpu_classes_gc_pause.cpu_seconds", RuntimeName: "/cpu/classes/gc/pause:cpu-seconds", Type: "gauge", Unit: "", PerUnit: "", Description: "Estimated total CPU time spent with the application paused by the GC. Even if only one thread is running during the pause, this is computed as GOMAXPROCS times the pause latency because nothing else can be executing. This is the exact sum of samples in /sched/pauses/total/gc:seconds if each sample is multiplied by GOMAXPROCS at the time it is taken. This metric is an overestimate, and not direc...", Orientation: -1, ShortName: "cpu classes gc pause"},
+	{Name: "runtime.go.metrics.cpu_classes_gc_total.cpu_seconds", RuntimeName: "/cpu/classes/gc/total:cpu-seconds", Type: "gauge", Unit: "", PerUnit: "", Description: "Estimated total CPU time spent performing GC tasks. This metric is an overestimate, and not directly comparable to system CPU time measurements. Compare only with other /cpu/classes metrics. Sum of all metrics in /cpu/classes/gc.", Orientation: -1, ShortName: "cpu classes gc total"},
+	{Name: "runtime.go.metrics.cpu_classes_idle.cpu_seconds", RuntimeName: "/cpu/classes/idle:cpu-seconds", Type: "gauge", Unit: "", PerUnit: "", Description: "Estimated total available CPU time not spent executing any Go or Go runtime code. In other words, the part of /cpu/classes/total:cpu-seconds that was unused. This metric is an overestimate, and not directly comparable to system CPU time measurements. Compare only with other /cpu/classes metrics.", Orientation: 1, ShortName: "cpu classes idle"},
+	{Name: "runtime.go.metrics.cpu_classes_scavenge_assist.cpu_seconds", RuntimeName: "/cpu/classes/scavenge/assist:cpu-seconds", Type: "gauge", Unit: "", PerUnit: "", Description: "Estimated total CPU time spent returning unused memory to the underlying platform in response eagerly to memory pressure. This metric is an overestimate, and not directly comparable to system CPU time measurements. Compare only with other /cpu/classes metrics.", Orientation: -1, ShortName: "cpu classes scavenge assist"},
+	{Name: "runtime.go.metrics.cpu_classes_scavenge_background.cpu_seconds", RuntimeName: "/cpu/classes/scavenge/background:cpu-seconds", Type: "gauge", Unit: "", PerUnit: "", Description: "Estimated total CPU time spent performing background tasks to return unused memory to the underlying platform. This metric is an overestimate, and not directly comparable to system CPU time measurements. Compare only with other /cpu/classes metrics.", Orientation: 0, ShortName: "cpu classes scavenge background"},
+	{Name: "runtime.go.metrics.cpu_classes_scavenge_total.cpu_seconds", RuntimeName: "/cpu/classes/scavenge/total:cpu-seconds", Type: "gauge", Unit: "", PerUnit: "", Description: "Estimated total CPU time spent performing tasks that return unused memory to the underlying platform. This metric is an overestimate, and not directly comparable to system CPU time measurements. Compare only with other /cpu/classes metrics. Sum of all metrics in /cpu/classes/scavenge.", Orientation: 0, ShortName: "cpu classes scavenge total"},
+	{Name: "runtime.go.metrics.cpu_classes_total.cpu_seconds", RuntimeName: "/cpu/classes/total:cpu-seconds", Type: "gauge", Unit: "", PerUnit: "", Description: "Estimated total available CPU time for user Go code or the Go runtime, as defined by GOMAXPROCS. In other words, GOMAXPROCS integrated over the wall-clock duration this process has been executing for. This metric is an overestimate, and not directly comparable to system CPU time measurements. Compare only with other /cpu/classes metrics. Sum of all metrics in /cpu/classes.", Orientation: 0, ShortName: "cpu classes total"},
+	{Name: "runtime.go.metrics.cpu_classes_user.cpu_seconds", RuntimeName: "/cpu/classes/user:cpu-seconds", Type: "gauge", Unit: "", PerUnit: "", Description: "Estimated total CPU time spent running user Go code. This may also include some small amount of time spent in the Go runtime. This metric is an overestimate, and not directly comparable to system CPU time measurements. Compare only with other /cpu/classes metrics.", Orientation: 0, ShortName: "cpu classes user"},
+	{Name: "runtime.go.metrics.gc_cleanups_executed.cleanups", RuntimeName: "/gc/cleanups/executed:cleanups", Type: "gauge", Unit: "", PerUnit: "", Description: "Approximate total count of cleanup functions (created by runtime.AddCleanup) executed by the runtime. Subtract /gc/cleanups/queued:cleanups to approximate cleanup queue length. Useful for detecting slow cleanups holding up the queue.", Orientation: 0, ShortName: "gc cleanups executed"},
+	{Name: "runtime.go.metrics.gc_cleanups_queued.cleanups", RuntimeName: "/gc/cleanups/queued:cleanups", Type: "gauge", Unit: "", PerUnit: "", Description: "Approximate total count of cleanup functions (created by runtime.AddCleanup) queued by the runtime for execution. Subtract from /gc/cleanups/executed:cleanups to approximate cleanup queue length. Useful for detecting slow cleanups holding up the queue.", Orientation: 0, ShortName: "gc cleanups queued"},
+	{Name: "runtime.go.metrics.gc_cycles_automatic.gc_cycles", RuntimeName: "/gc/cycles/automatic:gc-cycles", Type: "gauge", Unit: "", PerUnit: "", Description: "Count of completed GC cycles generated by the Go runtime.", Orientation: 0, ShortName: "gc cycles automatic"},
+	{Name: "runtime.go.metrics.gc_cycles_forced.gc_cycles", RuntimeName: "/gc/cycles/forced:gc-cycles", Type: "gauge", Unit: "", PerUnit: "", Description: "Count of completed GC cycles forced by the application.", Orientation: 0, ShortName: "gc cycles forced"},
+	{Name: "runtime.go.metrics.gc_cycles_total.gc_cycles", RuntimeName: "/gc/cycles/total:gc-cycles", Type: "gauge", Unit: "", PerUnit: "", Description: "Count of all completed GC cycles.", Orientation: 0, ShortName: "gc cycles total"},
+	{Name: "runtime.go.metrics.gc_finalizers_executed.finalizers", RuntimeName: "/gc/finalizers/executed:finalizers", Type: "gauge", Unit: "", PerUnit: "", Description: "Total count of finalizer functions (created by runtime.SetFinalizer) executed by the runtime. Subtract /gc/finalizers/queued:finalizers to approximate finalizer queue length. Useful for detecting finalizers overwhelming the queue, either by being too slow, or by there being too many of them.", Orientation: 0, ShortName: "gc finalizers executed"},
+	{Name: "runtime.go.metrics.gc_finalizers_queued.finalizers", RuntimeName: "/gc/finalizers/queued:finalizers", Type: "gauge", Unit: "", PerUnit: "", Description: "Total count of finalizer functions (created by runtime.SetFinalizer) and queued by the runtime for execution. Subtract from /gc/finalizers/executed:finalizers to approximate finalizer queue length. Useful for detecting slow finalizers holding up the queue.", Orientation: 0, ShortName: "gc finalizers queued"},
+	{Name: "runtime.go.metrics.gc_gogc.percent", RuntimeName: "/gc/gogc:percent", Type: "gauge", Unit: "percent", PerUnit: "", Description: "Heap size target percentage configured by the user, otherwise 100. This value is set by the GOGC environment variable, and the runtime/debug.SetGCPercent function.", Orientation: 0, ShortName: "gc gogc"},
+	{Name: "runtime.go.metrics.gc_gomemlimit.bytes", RuntimeName: "/gc/gomemlimit:bytes", Type: "gauge", Unit: "byte", PerUnit: "", Description: "Go runtime memory limit configured by the user, otherwise math.MaxInt64. This value is set by the GOMEMLIMIT environment variable, and the runtime/debug.SetMemoryLimit function.", Orientation: 0, ShortName: "gc gomemlimit"},
+	{Name: "runtime.go.metrics.gc_heap_allocs_by_size.bytes", RuntimeName: "/gc/heap/allocs-by-size:bytes", Type: "distribution", Unit: "byte", PerUnit: "", Description: "Distribution of heap allocations by approximate size. Bucket counts increase monotonically. Note that this does not include tiny objects as defined by /gc/heap/tiny/allocs:objects, only tiny blocks.", Orientation: 0, ShortName: "gc heap allocs by size"},
+	{Name: "runtime.go.metrics.gc_heap_allocs_by_size.bytes.avg", RuntimeName: "/gc/heap/allocs-by-size:bytes", Type: "gauge", Unit: "byte", PerUnit: "", Description: "avg of: Distribution of heap allocations by approximate size. Bucket counts increase monotonically. Note that this does not include tiny objects as defined by /gc/heap/tiny/allocs:objects, only tiny blocks.", Orientation: 0, ShortName: "gc heap allocs by size avg"},
+	{Name: "runtime.go.metrics.gc_heap_allocs_by_size.bytes.min", RuntimeName: "/gc/heap/allocs-by-size:bytes", Type: "gauge", Unit: "byte", PerUnit: "", Description: "min of: Distribution of heap allocations by approximate size. Bucket counts increase monotonically. Note that this does not include tiny objects as defined by /gc/heap/tiny/allocs:objects, only tiny blocks.", Orientation: 0, ShortName: "gc heap allocs by size min"},
+	{Name: "runtime.go.metrics.gc_heap_allocs_by_size.bytes.max", RuntimeName: "/gc/heap/allocs-by-size:bytes", Type: "gauge", Unit: "byte", PerUnit: "", Description: "max of: Distribution of heap allocations by approximate size. Bucket counts increase monotonically. Note that this does not include tiny objects as defined by /gc/heap/tiny/allocs:objects, only tiny blocks.", Orientation: 0, ShortName: "gc heap allocs by size max"},
+	{Name: "runtime.go.metrics.gc_heap_allocs_by_size.bytes.median", RuntimeName: "/gc/heap/allocs-by-size:bytes", Type: "gauge", Unit: "byte", PerUnit: "", Description: "median of: Distribution of heap allocations by approximate size. Bucket counts increase monotonically. Note that this does not include tiny objects as defined by /gc/heap/tiny/allocs:objects, only tiny blocks.", Orientation: 0, ShortName: "gc heap allocs by size median"},
+	{Name: "runtime.go.metrics.gc_heap_allocs_by_size.bytes.p95", RuntimeName: "/gc/heap/allocs-by-size:bytes", Type: "gauge", Unit: "byte", PerUnit: "", Description: "p95 of: Distribution of heap allocations by approximate size. Bucket counts increase monotonically. Note that this does not include tiny objects as defined by /gc/heap/tiny/allocs:objects, only tiny blocks.", Orientation: 0, ShortName: "gc heap allocs by size p95"},
+	{Name: "runtime.go.metrics.gc_heap_allocs_by_size.bytes.p99", RuntimeName: "/gc/heap/allocs-by-size:bytes", Type: "gauge", Unit: "byte", PerUnit: "", Description: "p99 of: Distribution of heap allocations by approximate size. Bucket counts increase monotonically. Note that this does not include tiny objects as defined by /gc/heap/tiny/allocs:objects, only tiny blocks.", Orientation: 0, ShortName: "gc heap allocs by size p99"},
+	{Name: "runtime.go.metrics.gc_heap_allocs.bytes", RuntimeName: "/gc/heap/allocs:bytes", Type: "gauge", Unit: "byte", PerUnit: "", Description: "Cumulative sum of memory allocated to the heap by the application.", Orientation: 0, ShortName: "gc heap allocs bytes"},
+	{Name: "runtime.go.metrics.gc_heap_allocs.objects", RuntimeName: "/gc/heap/allocs:objects", Type: "gauge", Unit: "object", PerUnit: "", Description: "Cumulative count of heap allocations triggered by the application. Note that this does not include tiny objects as defined by /gc/heap/tiny/allocs:objects, only tiny blocks.", Orientation: 0, ShortName: "gc heap allocs objects"},
+	{Name: "runtime.go.metrics.gc_heap_frees_by_size.bytes", RuntimeName: "/gc/heap/frees-by-size:bytes", Type: "distribution", Unit: "byte", PerUnit: "", Description: "Distribution of freed heap allocations by approximate size. Bucket counts increase monotonically. Note that this does not include tiny objects as defined by /gc/heap/tiny/allocs:objects, only tiny blocks.", Orientation: 0, ShortName: "gc heap frees by size"},
+	{Name: "runtime.go.metrics.gc_heap_frees_by_size.bytes.avg", RuntimeName: "/gc/heap/frees-by-size:bytes", Type: "gauge", Unit: "byte", PerUnit: "", Description: "avg of: Distribution of freed heap allocations by approximate size. Bucket counts increase monotonically. Note that this does not include tiny objects as defined by /gc/heap/tiny/allocs:objects, only tiny blocks.", Orientation: 0, ShortName: "gc heap frees by size avg"},
+	{Name: "runtime.go.metrics.gc_heap_frees_by_size.bytes.min", RuntimeName: "/gc/heap/frees-by-size:bytes", Type: "gauge", Unit: "byte", PerUnit: "", Description: "min of: Distribution of freed heap allocations by approximate size. Bucket counts increase monotonically. Note that this does not include tiny objects as defined by /gc/heap/tiny/allocs:objects, only tiny blocks.", Orientation: 0, ShortName: "gc heap frees by size min"},
+	{Name: "runtime.go.metrics.gc_heap_frees_by_size.bytes.max", RuntimeName: "/gc/heap/frees-by-size:bytes", Type: "gauge", Unit: "byte", PerUnit: "", Description: "max of: Distribution of freed heap allocations by approximate size. Bucket counts increase monotonically. Note that this does not include tiny objects as defined by /gc/heap/tiny/allocs:objects, only tiny blocks.", Orientation: 0, ShortName: "gc heap frees by size max"},
+	{Name: "runtime.go.metrics.gc_heap_frees_by_size.bytes.median", RuntimeName: "/gc/heap/frees-by-size:bytes", Type: "gauge", Unit: "byte", PerUnit: "", Description: "median of: Distribution of freed heap allocations by approximate size. Bucket counts increase monotonically. Note that this does not include tiny objects as defined by /gc/heap/tiny/allocs:objects, only tiny blocks.", Orientation: 0, ShortName: "gc heap frees by size median"},
+	{Name: "runtime.go.metrics.gc_heap_frees_by_size.bytes.p95", RuntimeName: "/gc/heap/frees-by-size:bytes", Type: "gauge", Unit: "byte", PerUnit: "", Description: "p95 of: Distribution of freed heap allocations by approximate size. Bucket counts increase monotonically. Note that this does not include tiny objects as defined by /gc/heap/tiny/allocs:objects, only tiny blocks.", Orientation: 0, ShortName: "gc heap frees by size p95"},
+	{Name: "runtime.go.metrics.gc_heap_frees_by_size.bytes.p99", RuntimeName: "/gc/heap/frees-by-size:bytes", Type: "gauge", Unit: "byte", PerUnit: "", Description: "p99 of: Distribution of freed heap allocations by approximate size. Bucket counts increase monotonically. Note that this does not include tiny objects as defined by /gc/heap/tiny/allocs:objects, only tiny blocks.", Orientation: 0, ShortName: "gc heap frees by size p99"},
+	{Name: "runtime.go.metrics.gc_heap_frees.bytes", RuntimeName: "/gc/heap/frees:bytes", Type: "gauge", Unit: "byte", PerUnit: "", Description: "Cumulative sum of heap memory freed by the garbage collector.", Orientation: 0, ShortName: "gc heap frees bytes"},
+	{Name: "runtime.go.metrics.gc_heap_frees.objects", RuntimeName: "/gc/heap/frees:objects", Type: "gauge", Unit: "object", PerUnit: "", Description: "Cumulative count of heap allocations whose storage was freed by the garbage collector. Note that this does not include tiny objects as defined by /gc/heap/tiny/allocs:objects, only tiny blocks.", Orientation: 0, ShortName: "gc heap frees objects"},
+	{Name: "runtime.go.metrics.gc_heap_goal.bytes", RuntimeName: "/gc/heap/goal:bytes", Type: "gauge", Unit: "byte", PerUnit: "", Description: "Heap size target for the end of the GC cycle.", Orientation: 0, ShortName: "gc heap goal"},
+	{Name: "runtime.go.metrics.gc_heap_live.bytes", RuntimeName: "/gc/heap/live:bytes", Type: "gauge", Unit: "byte", PerUnit: "", Description: "Heap memory occupied by live objects that were marked by the previous GC.", Orientation: 0, ShortName: "gc heap live"},
+	{Name: "runtime.go.metrics.gc_heap_objects.objects", RuntimeName: "/gc/heap/objects:objects", Type: "gauge", Unit: "object", PerUnit: "", Description: "Number of objects, live or unswept, occupying heap memory.", Orientation: 0, ShortName: "gc heap objects"},
+	{Name: "runtime.go.metrics.gc_heap_tiny_allocs.objects", RuntimeName: "/gc/heap/tiny/allocs:objects", Type: "gauge", Unit: "object", PerUnit: "", Description: "Count of small allocations that are packed together into blocks. These allocations are counted separately from other allocations because each individual allocation is not tracked by the runtime, only their block. Each block is already accounted for in allocs-by-size and frees-by-size.", Orientation: 0, ShortName: "gc heap tiny allocs"},
+	{Name: "runtime.go.metrics.gc_limiter_last_enabled.gc_cycle", RuntimeName: "/gc/limiter/last-enabled:gc-cycle", Type: "gauge", Unit: "", PerUnit: "", Description: "GC cycle the last time the GC CPU limiter was enabled. This metric is useful for diagnosing the root cause of an out-of-memory error, because the limiter trades memory for CPU time when the GC's CPU time gets too high. This is most likely to occur with use of SetMemoryLimit. The first GC cycle is cycle 1, so a value of 0 indicates that it was never enabled.", Orientation: 0, ShortName: "gc limiter last enabled"},
+	{Name: "runtime.go.metrics.gc_pauses.seconds", RuntimeName: "/gc/pauses:seconds", Type: "distribution", Unit: "second", PerUnit: "", Description: "Deprecated. Prefer the identical /sched/pauses/total/gc:seconds.", Orientation: -1, ShortName: "gc pauses"},
+	{Name: "runtime.go.metrics.gc_pauses.seconds.avg", RuntimeName: "/gc/pauses:seconds", Type: "gauge", Unit: "second", PerUnit: "", Description: "avg of: Deprecated. Prefer the identical /sched/pauses/total/gc:seconds.", Orientation: -1, ShortName: "gc pauses avg"},
+	{Name: "runtime.go.metrics.gc_pauses.seconds.min", RuntimeName: "/gc/pauses:seconds", Type: "gauge", Unit: "second", PerUnit: "", Description: "min of: Deprecated. Prefer the identical /sched/pauses/total/gc:seconds.", Orientation: -1, ShortName: "gc pauses min"},
+	{Name: "runtime.go.metrics.gc_pauses.seconds.max", RuntimeName: "/gc/pauses:seconds", Type: "gauge", Unit: "second", PerUnit: "", Description: "max of: Deprecated. Prefer the identical /sched/pauses/total/gc:seconds.", Orientation: -1, ShortName: "gc pauses max"},
+	{Name: "runtime.go.metrics.gc_pauses.seconds.median", RuntimeName: "/gc/pauses:seconds", Type: "gauge", Unit: "second", PerUnit: "", Description: "median of: Deprecated. Prefer the identical /sched/pauses/total/gc:seconds.", Orientation: -1, ShortName: "gc pauses median"},
+	{Name: "runtime.go.metrics.gc_pauses.seconds.p95", RuntimeName: "/gc/pauses:seconds", Type: "gauge", Unit: "second", PerUnit: "", Description: "p95 of: Deprecated. Prefer the identical /sched/pauses/total/gc:seconds.", Orientation: -1, ShortName: "gc pauses p95"},
+	{Name: "runtime.go.metrics.gc_pauses.seconds.p99", RuntimeName: "/gc/pauses:seconds", Type: "gauge", Unit: "second", PerUnit: "", Description: "p99 of: Deprecated. Prefer the identical /sched/pauses/total/gc:seconds.", Orientation: -1, ShortName: "gc pauses p99"},
+	{Name: "runtime.go.metrics.gc_scan_globals.bytes", RuntimeName: "/gc/scan/globals:bytes", Type: "gauge", Unit: "byte", PerUnit: "", Description: "The total amount of global variable space that is scannable.", Orientation: 0, ShortName: "gc scan globals"},
+	{Name: "runtime.go.metrics.gc_scan_heap.bytes", RuntimeName: "/gc/scan/heap:bytes", Type: "gauge", Unit: "byte", PerUnit: "", Description: "The total amount of heap space that is scannable.", Orientation: 0, ShortName: "gc scan heap"},
+	{Name: "runtime.go.metrics.gc_scan_stack.bytes", RuntimeName: "/gc/scan/stack:bytes", Type: "gauge", Unit: "byte", PerUnit: "", Description: "The number of bytes of stack that were scanned last GC cycle.", Orientation: 0, ShortName: "gc scan stack"},
+	{Name: "runtime.go.metrics.gc_scan_total.bytes", RuntimeName: "/gc/scan/total:bytes", Type: "gauge", Unit: "byte", PerUnit: "", Description: "The total amount space that is scannable. Sum of all metrics in /gc/scan.", Orientation: 0, ShortName: "gc scan total"},
+	{Name: "runtime.go.metrics.gc_stack_starting_size.bytes", RuntimeName: "/gc/stack/starting-size:bytes", Type: "gauge", Unit: "byte", PerUnit: "", Description: "The stack size of new goroutines.", Orientation: 0, ShortName: "gc stack starting size"},
+	{Name: "runtime.go.metrics.godebug_non_default_behavior_allowmultiplevcs.events", RuntimeName: "/godebug/non-default-behavior/allowmultiplevcs:events", Type: "gauge", Unit: "event", PerUnit: "", Description: "The number of non-default behaviors executed by the cmd/go package due to a non-default GODEBUG=allowmultiplevcs=... setting.", Orientation: 0, ShortName: "godebug non default behavior allowmultiplevcs"},
+	{Name: "runtime.go.metrics.godebug_non_default_behavior_containermaxprocs.events", RuntimeName: "/godebug/non-default-behavior/containermaxprocs:events", Type: "gauge", Unit: "event", PerUnit: "", Description: "The number of non-default behaviors executed by the runtime package due to a non-default GODEBUG=containermaxprocs=... setting.", Orientation: 0, ShortName: "godebug non default behavior containermaxprocs"},
+	{Name: "runtime.go.metrics.godebug_non_default_behavior_cryptocustomrand.events", RuntimeName: "/godebug/non-default-behavior/cryptocustomrand:events", Type: "gauge", Unit: "event", PerUnit: "", Description: "The number of non-default behaviors executed by the crypto package due to a non-default GODEBUG=cryptocustomrand=... setting.", Orientation: 0, ShortName: "godebug non default behavior cryptocustomrand"},
+	{Name: "runtime.go.metrics.godebug_non_default_behavior_embedfollowsymlinks.events", RuntimeName: "/godebug/non-default-behavior/embedfollowsymlinks:events", Type: "gauge", Unit: "event", PerUnit: "", Description: "The number of non-default behaviors executed by the cmd/go package due to a non-default GODEBUG=embedfollowsymlinks=... setting.", Orientation: 0, ShortName: "godebug non default behavior embedfollowsymlinks"},
+	{Name: "runtime.go.metrics.godebug_non_default_behavior_execerrdot.events", RuntimeName: "/godebug/non-default-behavior/execerrdot:events", Type: "gauge", Unit: "event", PerUnit: "", Description: "The number of non-default behaviors executed by the os/exec package due to a non-default GODEBUG=execerrdot=... setting.", Orientation: 0, ShortName: "godebug non default behavior execerrdot"},
+	{Name: "runtime.go.metrics.godebug_non_default_behavior_fips140ems.events", RuntimeName: "/godebug/non-default-behavior/fips140ems:events", Type: "gauge", Unit: "event", PerUnit: "", Description: "The number of non-default behaviors executed by the crypto/tls package due to a non-default GODEBUG=fips140ems=... setting.", Orientation: 0, ShortName: "godebug non default behavior fips140ems"},
+	{Name: "runtime.go.metrics.godebug_non_default_behavior_gocachehash.events", RuntimeName: "/godebug/non-default-behavior/gocachehash:events", Type: "gauge", Unit: "event", PerUnit: "", Description: "The number of non-default behaviors executed by the cmd/go package due to a non-default GODEBUG=gocachehash=... setting.", Orientation: 0, ShortName: "godebug non default behavior gocachehash"},
+	{Name: "runtime.go.metrics.godebug_non_default_behavior_gocachetest.events", RuntimeName: "/godebug/non-default-behavior/gocachetest:events", Type: "gauge", Unit: "event", PerUnit: "", Description: "The number of non-default behaviors executed by the cmd/go package due to a non-default GODEBUG=gocachetest=... setting.", Orientation: 0, ShortName: "godebug non default behavior gocachetest"},
+	{Name: "runtime.go.metrics.godebug_non_default_behavior_gocacheverify.events", RuntimeName: "/godebug/non-default-behavior/gocacheverify:events", Type: "gauge", Unit: "event", PerUnit: "", Description: "The number of non-default behaviors executed by the cmd/go package due to a non-default GODEBUG=gocacheverify=... setting.", Orientation: 0, ShortName: "godebug non default behavior gocacheverify"},
+	{Name: "runtime.go.metrics.godebug_non_default_behavior_gotestjsonbuildtext.events", RuntimeName: "/godebug/non-default-behavior/gotestjsonbuildtext:events", Type: "gauge", Unit: "event", PerUnit: "", Description: "The number of non-default behaviors executed by the cmd/go package due to a non-default GODEBUG=gotestjsonbuildtext=... setting.", Orientation: 0, ShortName: "godebug non default behavior gotestjsonbuildtext"},
+	{Name: "runtime.go.metrics.godebug_non_default_behavior_htmlmetacontenturlescape.events", RuntimeName: "/godebug/non-default-behavior/htmlmetacontenturlescape:events", Type: "gauge", Unit: "event", PerUnit: "", Description: "The number of non-default behaviors executed by the html/template package due to a non-default GODEBUG=htmlmetacontenturlescape=... setting.", Orientation: 0, ShortName: "godebug non default behavior htmlmetacontenturlescape"},
+	{Name: "runtime.go.metrics.godebug_non_default_behavior_http2client.events", RuntimeName: "/godebug/non-default-behavior/http2client:events", Type: "gauge", Unit: "event", PerUnit: "", Description: "The number of non-default behaviors executed by the net/http package due to a non-default GODEBUG=http2client=... setting.", Orientation: 0, ShortName: "godebug non default behavior http2client"},
+	{Name: "runtime.go.metrics.godebug_non_default_behavior_http2server.events", RuntimeName: "/godebug/non-default-behavior/http2server:events", Type: "gauge", Unit: "event", PerUnit: "", Description: "The number of non-default behaviors executed by the net/http package due to a non-default GODEBUG=http2server=... setting.", Orientation: 0, ShortName: "godebug non default behavior http2server"},
+	{Name: "runtime.go.metrics.godebug_non_default_behavior_httpcookiemaxnum.events", RuntimeName: "/godebug/non-default-behavior/httpcookiemaxnum:events", Type: "gauge", Unit: "event", PerUnit: "", Description: "The number of non-default behaviors executed by the net/http package due to a non-default GODEBUG=httpcookiemaxnum=... setting.", Orientation: 0, ShortName: "godebug non default behavior httpcookiemaxnum"},
+	{Name: "runtime.go.metrics.godebug_non_default_behavior_httplaxcontentlength.events", RuntimeName: "/godebug/non-default-behavior/httplaxcontentlength:events", Type: "gauge", Unit: "event", PerUnit: "", Description: "The number of non-default behaviors executed by the net/http package due to a non-default GODEBUG=httplaxcontentlength=... setting.", Orientation: 0, ShortName: "godebug non default behavior httplaxcontentlength"},
+	{Name: "runtime.go.metrics.godebug_non_default_behavior_httpmuxgo121.events", RuntimeName: "/godebug/non-default-behavior/httpmuxgo121:events", Type: "gauge", Unit: "event", PerUnit: "", Description: "The number of non-default behaviors executed by the net/http package due to a non-default GODEBUG=httpmuxgo121=... setting.", Orientation: 0, ShortName: "godebug non default behavior httpmuxgo121"},
+	{Name: "runtime.go.metrics.godebug_non_default_behavior_httpservecontentkeepheaders.events", RuntimeName: "/godebug/non-default-behavior/httpservecontentkeepheaders:events", Type: "gauge", Unit: "event", PerUnit: "", Description: "The number of non-default behaviors executed by the net/http package due to a non-default GODEBUG=httpservecontentkeepheaders=... setting.", Orientation: 0, ShortName: "godebug non default behavior httpservecontentkeepheaders"},
+	{Name: "runtime.go.metrics.godebug_non_default_behavior_installgoroot.events", RuntimeName: "/godebug/non-default-behavior/installgoroot:events", Type: "gauge", Unit: "event", PerUnit: "", Description: "The number of non-default behaviors executed by the go/build package due to a non-default GODEBUG=installgoroot=... setting.", Orientation: 0, ShortName: "godebug non default behavior installgoroot"},
+	{Name: "runtime.go.metrics.godebug_non_default_behavior_multipartmaxheaders.events", RuntimeName: "/godebug/non-default-behavior/multipartmaxheaders:events", Type: "gauge", Unit: "event", PerUnit: "", Description: "The number of non-default behaviors executed by the mime/multipart package due to a non-default GODEBUG=multipartmaxheaders=... setting.", Orientation: 0, ShortName: "godebug non default behavior multipartmaxheaders"},
+	{Name: "runtime.go.metrics.godebug_non_default_behavior_multipartmaxparts.events", RuntimeName: "/godebug/non-default-behavior/multipartmaxparts:events", Type: "gauge", Unit: "event", PerUnit: "", Description: "The number of non-default behaviors executed by the mime/multipart package due to a non-default GODEBUG=multipartmaxparts=... setting.", Orientation: 0, ShortName: "godebug non default behavior multipartmaxparts"},
+	{Name: "runtime.go.metrics.godebug_non_default_behavior_multipathtcp.events", RuntimeName: "/godebug/non-default-behavior/multipathtcp:events", Type: "gauge", Unit: "event", PerUnit: "", Description: "The number of non-default behaviors executed by the net package due to a non-default GODEBUG=multipathtcp=... setting.", Orientation: 0, ShortName: "godebug non default behavior multipathtcp"},
+	{Name: "runtime.go.metrics.godebug_non_default_behavior_netedns0.events", RuntimeName: "/godebug/non-default-behavior/netedns0:events", Type: "gauge", Unit: "event", PerUnit: "", Description: "The number of non-default behaviors executed by the net package due to a non-default GODEBUG=netedns0=... setting.", Orientation: 0, ShortName: "godebug non default behavior netedns0"},
+	{Name: "runtime.go.metrics.godebug_non_default_behavior_panicnil.events", RuntimeName: "/godebug/non-default-behavior/panicnil:events", Type: "gauge", Unit: "event", PerUnit: "", Description: "The number of non-default behaviors executed by the runtime package due to a non-default GODEBUG=panicnil=... setting.", Orientation: 0, ShortName: "godebug non default behavior panicnil"},
+	{Name: "runtime.go.metrics.godebug_non_default_behavior_randautoseed.events", RuntimeName: "/godebug/non-default-behavior/randautoseed:events", Type: "gauge", Unit: "event", PerUnit: "", Description: "The number of non-default behaviors executed by the math/rand package due to a non-default GODEBUG=randautoseed=... setting.", Orientation: 0, ShortName: "godebug non default behavior randautoseed"},
+	{Name: "runtime.go.metrics.godebug_non_default_behavior_randseednop.events", RuntimeName: "/godebug/non-default-behavior/randseednop:events", Type: "gauge", Unit: "event", PerUnit: "", Description: "The number of non-default behaviors executed by the math/rand package due to a non-default GODEBUG=randseednop=... setting.", Orientation: 0, ShortName: "godebug non default behavior randseednop"},
+	{Name: "runtime.go.metrics.godebug_non_default_behavior_rsa1024min.events", RuntimeName: "/godebug/non-default-behavior/rsa1024min:events", Type: "gauge", Unit: "event", PerUnit: "", Description: "The number of non-default behaviors executed by the crypto/rsa package due to a non-default GODEBUG=rsa1024min=... setting.", Orientation: 0, ShortName: "godebug non default behavior rsa1024min"},
+	{Name: "runtime.go.metrics.godebug_non_default_behavior_tarinsecurepath.events", RuntimeName: "/godebug/non-default-behavior/tarinsecurepath:events", Type: "gauge", Unit: "event", PerUnit: "", Description: "The number of non-default behaviors executed by the archive/tar package due to a non-default GODEBUG=tarinsecurepath=... setting.", Orientation: 0, ShortName: "godebug non default behavior tarinsecurepath"},
+	{Name: "runtime.go.metrics.godebug_non_default_behavior_tlsmaxrsasize.events", RuntimeName: "/godebug/non-default-behavior/tlsmaxrsasize:events", Type: "gauge", Unit: "event", PerUnit: "", Description: "The number of non-default behaviors executed by the crypto/tls package due to a non-default GODEBUG=tlsmaxrsasize=... setting.", Orientation: 0, ShortName: "godebug non default behavior tlsmaxrsasize"},
+	{Name: "runtime.go.metrics.godebug_non_default_behavior_tlssha1.events", RuntimeName: "/godebug/non-default-behavior/tlssha1:events", Type: "gauge", Unit: "event", PerUnit: "", Description: "The number of non-default behaviors executed by the crypto/tls package due to a non-default GODEBUG=tlssha1=... setting.", Orientation: 0, ShortName: "godebug non default behavior tlssha1"},
+	{Name: "runtime.go.metrics.godebug_non_default_behavior_updatemaxprocs.events", RuntimeName: "/godebug/non-default-behavior/updatemaxprocs:events", Type: "gauge", Unit: "event", PerUnit: "", Description: "The number of non-default behaviors executed by the runtime package due to a non-default GODEBUG=updatemaxprocs=... setting.", Orientation: 0, ShortName: "godebug non default behavior updatemaxprocs"},
+	{Name: "runtime.go.metrics.godebug_non_default_behavior_urlmaxqueryparams.events", RuntimeName: "/godebug/non-default-behavior/urlmaxqueryparams:events", Type: "gauge", Unit: "event", PerUnit: "", Description: "The number of non-default behaviors executed by the net/url package due to a non-default GODEBUG=urlmaxqueryparams=... setting.", Orientation: 0, ShortName: "godebug non default behavior urlmaxqueryparams"},
+	{Name: "runtime.go.metrics.godebug_non_default_behavior_urlstrictcolons.events", RuntimeName: "/godebug/non-default-behavior/urlstrictcolons:events", Type: "gauge", Unit: "event", PerUnit: "", Description: "The number of non-default behaviors executed by the net/url package due to a non-default GODEBUG=urlstrictcolons=... setting.", Orientation: 0, ShortName: "godebug non default behavior urlstrictcolons"},
+	{Name: "runtime.go.metrics.godebug_non_default_behavior_winreadlinkvolume.events", RuntimeName: "/godebug/non-default-behavior/winreadlinkvolume:events", Type: "gauge", Unit: "event", PerUnit: "", Description: "The number of non-default behaviors executed by the os package due to a non-default GODEBUG=winreadlinkvolume=... setting.", Orientation: 0, ShortName: "godebug non default behavior winreadlinkvolume"},
+	{Name: "runtime.go.metrics.godebug_non_default_behavior_winsymlink.events", RuntimeName: "/godebug/non-default-behavior/winsymlink:events", Type: "gauge", Unit: "event", PerUnit: "", Description: "The number of non-default behaviors executed by the os package due to a non-default GODEBUG=winsymlink=... setting.", Orientation: 0, ShortName: "godebug non default behavior winsymlink"},
+	{Name: "runtime.go.metrics.godebug_non_default_behavior_x509negativeserial.events", RuntimeName: "/godebug/non-default-behavior/x509negativeserial:events", Type: "gauge", Unit: "event", PerUnit: "", Description: "The number of non-default behaviors executed by the crypto/x509 package due to a non-default GODEBUG=x509negativeserial=... setting.", Orientation: 0, ShortName: "godebug non default behavior x509negativeserial"},
+	{Name: "runtime.go.metrics.godebug_non_default_behavior_x509rsacrt.events", RuntimeName: "/godebug/non-default-behavior/x509rsacrt:events", Type: "gauge", Unit: "event", PerUnit: "", Description: "The number of non-default behaviors executed by the crypto/x509 package due to a non-default GODEBUG=x509rsacrt=... setting.", Orientation: 0, ShortName: "godebug non default behavior x509rsacrt"},
+	{Name: "runtime.go.metrics.godebug_non_default_behavior_x509sha256skid.events", RuntimeName: "/godebug/non-default-behavior/x509sha256skid:events", Type: "gauge", Unit: "event", PerUnit: "", Description: "The number of non-default behaviors executed by the crypto/x509 package due to a non-default GODEBUG=x509sha256skid=... setting.", Orientation: 0, ShortName: "godebug non default behavior x509sha256skid"},
+	{Name: "runtime.go.metrics.godebug_non_default_behavior_x509sslcertoverrideplatform.events", RuntimeName: "/godebug/non-default-behavior/x509sslcertoverrideplatform:events", Type: "gauge", Unit: "event", PerUnit: "", Description: "The number of non-default behaviors executed by the crypto/x509 package due to a non-default GODEBUG=x509sslcertoverrideplatform=... setting.", Orientation: 0, ShortName: "godebug non default behavior x509sslcertoverrideplatform"},
+	{Name: "runtime.go.metrics.godebug_non_default_behavior_x509usefallbackroots.events", RuntimeName: "/godebug/non-default-behavior/x509usefallbackroots:events", Type: "gauge", Unit: "event", PerUnit: "", Description: "The number of non-default behaviors executed by the crypto/x509 package due to a non-default GODEBUG=x509usefallbackroots=... setting.", Orientation: 0, ShortName: "godebug non default behavior x509usefallbackroots"},
+	{Name: "runtime.go.metrics.godebug_non_default_behavior_x509usepolicies.events", RuntimeName: "/godebug/non-default-behavior/x509usepolicies:events", Type: "gauge", Unit: "event", PerUnit: "", Description: "The number of non-default behaviors executed by the crypto/x509 package due to a non-default GODEBUG=x509usepolicies=... setting.", Orientation: 0, ShortName: "godebug non default behavior x509usepolicies"},
+	{Name: "runtime.go.metrics.godebug_non_default_behavior_zipinsecurepath.events", RuntimeName: "/godebug/non-default-behavior/zipinsecurepath:events", Type: "gauge", Unit: "event", PerUnit: "", Description: "The number of non-default behaviors executed by the archive/zip package due to a non-default GODEBUG=zipinsecurepath=... setting.", Orientation: 0, ShortName: "godebug non default behavior zipinsecurepath"},
+	{Name: "runtime.go.metrics.memory_classes_heap_free.bytes", RuntimeName: "/memory/classes/heap/free:bytes", Type: "gauge", Unit: "byte", PerUnit: "", Description: "Memory that is completely free and eligible to be returned to the underlying system, but has not been. This metric is the runtime's estimate of free address space that is backed by physical memory.", Orientation: 0, ShortName: "memory classes heap free"},
+	{Name: "runtime.go.metrics.memory_classes_heap_objects.bytes", RuntimeName: "/memory/classes/heap/objects:bytes", Type: "gauge", Unit: "byte", PerUnit: "", Description: "Memory occupied by live objects and dead objects that have not yet been marked free by the garbage collector.", Orientation: 0, ShortName: "memory classes heap objects"},
+	{Name: "runtime.go.metrics.memory_classes_heap_released.bytes", RuntimeName: "/memory/classes/heap/released:bytes", Type: "gauge", Unit: "byte", PerUnit: "", Description: "Memory that is completely free and has been returned to the underlying system. This metric is the runtime's estimate of free address space that is still mapped into the process, but is not backed by physical memory.", Orientation: 0, ShortName: "memory classes heap released"},
+	{Name: "runtime.go.metrics.memory_classes_heap_stacks.bytes", RuntimeName: "/memory/classes/heap/stacks:bytes", Type: "gauge", Unit: "byte", PerUnit: "", Description: "Memory allocated from the heap that is reserved for stack space, whether or not it is currently in-use. Currently, this represents all stack memory for goroutines. It also includes all OS thread stacks in non-cgo programs. Note that stacks may be allocated differently in the future, and this may change.", Orientation: 0, ShortName: "memory classes heap stacks"},
+	{Name: "runtime.go.metrics.memory_classes_heap_unused.bytes", RuntimeName: "/memory/classes/heap/unused:bytes", Type: "gauge", Unit: "byte", PerUnit: "", Description: "Memory that is reserved for heap objects but is not currently used to hold heap objects.", Orientation: -1, ShortName: "memory classes heap unused"},
+	{Name: "runtime.go.metrics.memory_classes_metadata_mcache_free.bytes", RuntimeName: "/memory/classes/metadata/mcache/free:bytes", Type: "gauge", Unit: "byte", PerUnit: "", Description: "Memory that is reserved for runtime mcache structures, but not in-use.", Orientation: 0, ShortName: "memory classes metadata mcache free"},
+	{Name: "runtime.go.metrics.memory_classes_metadata_mcache_inuse.bytes", RuntimeName: "/memory/classes/metadata/mcache/inuse:bytes", Type: "gauge", Unit: "byte", PerUnit: "", Description: "Memory that is occupied by runtime mcache structures that are currently being used.", Orientation: 0, ShortName: "memory classes metadata mcache inuse"},
+	{Name: "runtime.go.metrics.memory_classes_metadata_mspan_free.bytes", RuntimeName: "/memory/classes/metadata/mspan/free:bytes", Type: "gauge", Unit: "byte", PerUnit: "", Description: "Memory that is reserved for runtime mspan structures, but not in-use.", Orientation: 0, ShortName: "memory classes metadata mspan free"},
+	{Name: "runtime.go.metrics.memory_classes_metadata_mspan_inuse.bytes", RuntimeName: "/memory/classes/metadata/mspan/inuse:bytes", Type: "gauge", Unit: "byte", PerUnit: "", Description: "Memory that is occupied by runtime mspan structures that are currently being used.", Orientation: 0, ShortName: "memory classes metadata mspan inuse"},
+	{Name: "runtime.go.metrics.memory_classes_metadata_other.bytes", RuntimeName: "/memory/classes/metadata/other:bytes", Type: "gauge", Unit: "byte", PerUnit: "", Description: "Memory that is reserved for or used to hold runtime metadata.", Orientation: 0, ShortName: "memory classes metadata other"},
+	{Name: "runtime.go.metrics.memory_classes_os_stacks.bytes", RuntimeName: "/memory/classes/os-stacks:bytes", Type: "gauge", Unit: "byte", PerUnit: "", Description: "Stack memory allocated by the underlying operating system. In non-cgo programs this metric is currently zero. This may change in the future.In cgo programs this metric includes OS thread stacks allocated directly from the OS. Currently, this only accounts for one stack in c-shared and c-archive build modes, and other sources of stacks from the OS are not measured. This too may change in the fut...", Orientation: 0, ShortName: "memory classes os stacks"},
+	{Name: "runtime.go.metrics.memory_classes_other.bytes", RuntimeName: "/memory/classes/other:bytes", Type: "gauge", Unit: "byte", PerUnit: "", Description: "Memory used by execution trace buffers, structures for debugging the runtime, finalizer and profiler specials, and more.", Orientation: 0, ShortName: "memory classes other"},
+	{Name: "runtime.go.metrics.memory_classes_profiling_buckets.bytes", RuntimeName: "/memory/classes/profiling/buckets:bytes", Type: "gauge", Unit: "byte", PerUnit: "", Description: "Memory that is used by the stack trace hash map used for profiling.", Orientation: 0, ShortName: "memory classes profiling buckets"},
+	{Name: "runtime.go.metrics.memory_classes_total.bytes", RuntimeName: "/memory/classes/total:bytes", Type: "gauge", Unit: "byte", PerUnit: "", Description: "All memory mapped by the Go runtime into the current process as read-write. Note that this does not include memory mapped by code called via cgo or via the syscall package. Sum of all metrics in /memory/classes.", Orientation: 0, ShortName: "memory classes total"},
+	{Name: "runtime.go.metrics.sched_gomaxprocs.threads", RuntimeName: "/sched/gomaxprocs:threads", Type: "gauge", Unit: "thread", PerUnit: "", Description: "The current runtime.GOMAXPROCS setting, or the number of operating system threads that can execute user-level Go code simultaneously.", Orientation: 0, ShortName: "sched gomaxprocs"},
+	{Name: "runtime.go.metrics.sched_goroutines_created.goroutines", RuntimeName: "/sched/goroutines-created:goroutines", Type: "gauge", Unit: "", PerUnit: "", Description: "Count of goroutines created since program start.", Orientation: 0, ShortName: "sched goroutines created"},
+	{Name: "runtime.go.metrics.sched_goroutines_not_in_go.goroutines", RuntimeName: "/sched/goroutines/not-in-go:goroutines", Type: "gauge", Unit: "", PerUnit: "", Description: "Approximate count of goroutines running or blocked in a system call or cgo call. Not guaranteed to add up to /sched/goroutines:goroutines with other goroutine metrics.", Orientation: 0, ShortName: "sched goroutines not in go"},
+	{Name: "runtime.go.metrics.sched_goroutines_runnable.goroutines", RuntimeName: "/sched/goroutines/runnable:goroutines", Type: "gauge", Unit: "", PerUnit: "", Description: "Approximate count of goroutines ready to execute, but not executing. Not guaranteed to add up to /sched/goroutines:goroutines with other goroutine metrics.", Orientation: 0, ShortName: "sched goroutines runnable"},
+	{Name: "runtime.go.metrics.sched_goroutines_running.goroutines", RuntimeName: "/sched/goroutines/running:goroutines", Type: "gauge", Unit: "", PerUnit: "", Description: "Approximate count of goroutines executing. Always less than or equal to /sched/gomaxprocs:threads. Not guaranteed to add up to /sched/goroutines:goroutines with other goroutine metrics.", Orientation: 0, ShortName: "sched goroutines running"},
+	{Name: "runtime.go.metrics.sched_goroutines_waiting.goroutines", RuntimeName: "/sched/goroutines/waiting:goroutines", Type: "gauge", Unit: "", PerUnit: "", Description: "Approximate count of goroutines waiting on a resource (I/O or sync primitives). Not guaranteed to add up to /sched/goroutines:goroutines with other goroutine metrics.", Orientation: 0, ShortName: "sched goroutines waiting"},
+	{Name: "runtime.go.metrics.sched_goroutines.goroutines", RuntimeName: "/sched/goroutines:goroutines", Type: "gauge", Unit: "", PerUnit: "", Description: "Count of live goroutines.", Orientation: 0, ShortName: "sched goroutines"},
+	{Name: "runtime.go.metrics.sched_latencies.seconds", RuntimeName: "/sched/latencies:seconds", Type: "distribution", Unit: "second", PerUnit: "", Description: "Distribution of the time goroutines have spent in the scheduler in a runnable state before actually running. Bucket counts increase monotonically.", Orientation: -1, ShortName: "sched latencies"},
+	{Name: "runtime.go.metrics.sched_latencies.seconds.avg", RuntimeName: "/sched/latencies:seconds", Type: "gauge", Unit: "second", PerUnit: "", Description: "avg of: Distribution of the time goroutines have spent in the scheduler in a runnable state before actually running. Bucket counts increase monotonically.", Orientation: -1, ShortName: "sched latencies avg"},
+	{Name: "runtime.go.metrics.sched_latencies.seconds.min", RuntimeName: "/sched/latencies:seconds", Type: "gauge", Unit: "second", PerUnit: "", Description: "min of: Distribution of the time goroutines have spent in the scheduler in a runnable state before actually running. Bucket counts increase monotonically.", Orientation: -1, ShortName: "sched latencies min"},
+	{Name: "runtime.go.metrics.sched_latencies.seconds.max", RuntimeName: "/sched/latencies:seconds", Type: "gauge", Unit: "second", PerUnit: "", Description: "max of: Distribution of the time goroutines have spent in the scheduler in a runnable state before actually running. Bucket counts increase monotonically.", Orientation: -1, ShortName: "sched latencies max"},
+	{Name: "runtime.go.metrics.sched_latencies.seconds.median", RuntimeName: "/sched/latencies:seconds", Type: "gauge", Unit: "second", PerUnit: "", Description: "median of: Distribution of the time goroutines have spent in the scheduler in a runnable state before actually running. Bucket counts increase monotonically.", Orientation: -1, ShortName: "sched latencies median"},
+	{Name: "runtime.go.metrics.sched_latencies.seconds.p95", RuntimeName: "/sched/latencies:seconds", Type: "gauge", Unit: "second", PerUnit: "", Description: "p95 of: Distribution of the time goroutines have spent in the scheduler in a runnable state before actually running. Bucket counts increase monotonically.", Orientation: -1, ShortName: "sched latencies p95"},
+	{Name: "runtime.go.metrics.sched_latencies.seconds.p99", RuntimeName: "/sched/latencies:seconds", Type: "gauge", Unit: "second", PerUnit: "", Description: "p99 of: Distribution of the time goroutines have spent in the scheduler in a runnable state before actually running. Bucket counts increase monotonically.", Orientation: -1, ShortName: "sched latencies p99"},
+	{Name: "runtime.go.metrics.sched_pauses_stopping_gc.seconds", RuntimeName: "/sched/pauses/stopping/gc:seconds", Type: "distribution", Unit: "second", PerUnit: "", Description: "Distribution of individual GC-related stop-the-world stopping latencies. This is the time it takes from deciding to stop the world until all Ps are stopped. This is a subset of the total GC-related stop-the-world time (/sched/pauses/total/gc:seconds). During this time, some threads may be executing. Bucket counts increase monotonically.", Orientation: -1, ShortName: "sched pauses stopping gc"},
+	{Name: "runtime.go.metrics.sched_pauses_stopping_gc.seconds.avg", RuntimeName: "/sched/pauses/stopping/gc:seconds", Type: "gauge", Unit: "second", PerUnit: "", Description: "avg of: Distribution of individual GC-related stop-the-world stopping latencies. This is the time it takes from deciding to stop the world until all Ps are stopped. This is a subset of the total GC-related stop-the-world time (/sched/pauses/total/gc:seconds). During this time, some threads may be executing. Bucket counts increase monotonically.", Orientation: -1, ShortName: "sched pauses stopping gc avg"},
+	{Name: "runtime.go.metrics.sched_pauses_stopping_gc.seconds.min", RuntimeName: "/sched/pauses/stopping/gc:seconds", Type: "gauge", Unit: "second", PerUnit: "", Description: "min of: Distribution of individual GC-related stop-the-world stopping latencies. This is the time it takes from deciding to stop the world until all Ps are stopped. This is a subset of the total GC-related stop-the-world time (/sched/pauses/total/gc:seconds). During this time, some threads may be executing. Bucket counts increase monotonically.", Orientation: -1, ShortName: "sched pauses stopping gc min"},
+	{Name: "runtime.go.metrics.sched_pauses_stopping_gc.seconds.max", RuntimeName: "/sched/pauses/stopping/gc:seconds", Type: "gauge", Unit: "second", PerUnit: "", Description: "max of: Distribution of individual GC-related stop-the-world stopping latencies. This is the time it takes from deciding to stop the world until all Ps are stopped. This is a subset of the total GC-related stop-the-world time (/sched/pauses/total/gc:seconds). During this time, some threads may be executing. Bucket counts increase monotonically.", Orientation: -1, ShortName: "sched pauses stopping gc max"},
+	{Name: "runtime.go.metrics.sched_pauses_stopping_gc.seconds.median", RuntimeName: "/sched/pauses/stopping/gc:seconds", Type: "gauge", Unit: "second", PerUnit: "", Description: "median of: Distribution of individual GC-related stop-the-world stopping latencies. This is the time it takes from deciding to stop the world until all Ps are stopped. This is a subset of the total GC-related stop-the-world time (/sched/pauses/total/gc:seconds). During this time, some threads may be executing. Bucket counts increase monotonically.", Orientation: -1, ShortName: "sched pauses stopping gc median"},
+	{Name: "runtime.go.metrics.sched_pauses_stopping_gc.seconds.p95", RuntimeName: "/sched/pauses/stopping/gc:seconds", Type: "gauge", Unit: "second", PerUnit: "", Description: "p95 of: Distribution of individual GC-related stop-the-world stopping latencies. This is the time it takes from deciding to stop the world until all Ps are stopped. This is a subset of the total GC-related stop-the-world time (/sched/pauses/total/gc:seconds). During this time, some threads may be executing. Bucket counts increase monotonically.", Orientation: -1, ShortName: "sched pauses stopping gc p95"},
+	{Name: "runtime.go.metrics.sched_pauses_stopping_gc.seconds.p99", RuntimeName: "/sched/pauses/stopping/gc:seconds", Type: "gauge", Unit: "second", PerUnit: "", Description: "p99 of: Distribution of individual GC-related stop-the-world stopping latencies. This is the time it takes from deciding to stop the world until all Ps are stopped. This is a subset of the total GC-related stop-the-world time (/sched/pauses/total/gc:seconds). During this time, some threads may be executing. Bucket counts increase monotonically.", Orientation: -1, ShortName: "sched pauses stopping gc p99"},
+	{Name: "runtime.go.metrics.sched_pauses_stopping_other.seconds", RuntimeName: "/sched/pauses/stopping/other:seconds", Type: "distribution", Unit: "second", PerUnit: "", Description: "Distribution of individual non-GC-related stop-the-world stopping latencies. This is the time it takes from deciding to stop the world until all Ps are stopped. This is a subset of the total non-GC-related stop-the-world time (/sched/pauses/total/other:seconds). During this time, some threads may be executing. Bucket counts increase monotonically.", Orientation: -1, ShortName: "sched pauses stopping other"},
+	{Name: "runtime.go.metrics.sched_pauses_stopping_other.seconds.avg", RuntimeName: "/sched/pauses/stopping/other:seconds", Type: "gauge", Unit: "second", PerUnit: "", Description: "avg of: Distribution of individual non-GC-related stop-the-world stopping latencies. This is the time it takes from deciding to stop the world until all Ps are stopped. This is a subset of the total non-GC-related stop-the-world time (/sched/pauses/total/other:seconds). During this time, some threads may be executing. Bucket counts increase monotonically.", Orientation: -1, ShortName: "sched pauses stopping other avg"},
+	{Name: "runtime.go.metrics.sched_pauses_stopping_other.seconds.min", RuntimeName: "/sched/pauses/stopping/other:seconds", Type: "gauge", Unit: "second", PerUnit: "", Description: "min of: Distribution of individual non-GC-related stop-the-world stopping latencies. This is the time it takes from deciding to stop the world until all Ps are stopped. This is a subset of the total non-GC-related stop-the-world time (/sched/pauses/total/other:seconds). During this time, some threads may be executing. Bucket counts increase monotonically.", Orientation: -1, ShortName: "sched pauses stopping other min"},
+	{Name: "runtime.go.metrics.sched_pauses_stopping_other.seconds.max", RuntimeName: "/sched/pauses/stopping/other:seconds", Type: "gauge", Unit: "second", PerUnit: "", Description: "max of: Distribution of individual non-GC-related stop-the-world stopping latencies. This is the time it takes from deciding to stop the world until all Ps are stopped. This is a subset of the total non-GC-related stop-the-world time (/sched/pauses/total/other:seconds). During this time, some threads may be executing. Bucket counts increase monotonically.", Orientation: -1, ShortName: "sched pauses stopping other max"},
+	{Name: "runtime.go.metrics.sched_pauses_stopping_other.seconds.median", RuntimeName: "/sched/pauses/stopping/other:seconds", Type: "gauge", Unit: "second", PerUnit: "", Description: "median of: Distribution of individual non-GC-related stop-the-world stopping latencies. This is the time it takes from deciding to stop the world until all Ps are stopped. This is a subset of the total non-GC-related stop-the-world time (/sched/pauses/total/other:seconds). During this time, some threads may be executing. Bucket counts increase monotonically.", Orientation: -1, ShortName: "sched pauses stopping other median"},
+	{Name: "runtime.go.metrics.sched_pauses_stopping_other.seconds.p95", RuntimeName: "/sched/pauses/stopping/other:seconds", Type: "gauge", Unit: "second", PerUnit: "", Description: "p95 of: Distribution of individual non-GC-related stop-the-world stopping latencies. This is the time it takes from deciding to stop the world until all Ps are stopped. This is a subset of the total non-GC-related stop-the-world time (/sched/pauses/total/other:seconds). During this time, some threads may be executing. Bucket counts increase monotonically.", Orientation: -1, ShortName: "sched pauses stopping other p95"},
+	{Name: "runtime.go.metrics.sched_pauses_stopping_other.seconds.p99", RuntimeName: "/sched/pauses/stopping/other:seconds", Type: "gauge", Unit: "second", PerUnit: "", Description: "p99 of: Distribution of individual non-GC-related stop-the-world stopping latencies. This is the time it takes from deciding to stop the world until all Ps are stopped. This is a subset of the total non-GC-related stop-the-world time (/sched/pauses/total/other:seconds). During this time, some threads may be executing. Bucket counts increase monotonically.", Orientation: -1, ShortName: "sched pauses stopping other p99"},
+	{Name: "runtime.go.metrics.sched_pauses_total_gc.seconds", RuntimeName: "/sched/pauses/total/gc:seconds", Type: "distribution", Unit: "second", PerUnit: "", Description: "Distribution of individual GC-related stop-the-world pause latencies. This is the time from deciding to stop the world until the world is started again. Some of this time is spent getting all threads to stop (this is measured directly in /sched/pauses/stopping/gc:seconds), during which some threads may still be running. Bucket counts increase monotonically.", Orientation: -1, ShortName: "sched pauses total gc"},
+	{Name: "runtime.go.metrics.sched_pauses_total_gc.seconds.avg", RuntimeName: "/sched/pauses/total/gc:seconds", Type: "gauge", Unit: "second", PerUnit: "", Description: "avg of: Distribution of individual GC-related stop-the-world pause latencies. This is the time from deciding to stop the world until the world is started again. Some of this time is spent getting all threads to stop (this is measured directly in /sched/pauses/stopping/gc:seconds), during which some threads may still be running. Bucket counts increase monotonically.", Orientation: -1, ShortName: "sched pauses total gc avg"},
+	{Name: "runtime.go.metrics.sched_pauses_total_gc.seconds.min", RuntimeName: "/sched/pauses/total/gc:seconds", Type: "gauge", Unit: "second", PerUnit: "", Description: "min of: Distribution of individual GC-related stop-the-world pause latencies. This is the time from deciding to stop the world until the world is started again. Some of this time is spent getting all threads to stop (this is measured directly in /sched/pauses/stopping/gc:seconds), during which some threads may still be running. Bucket counts increase monotonically.", Orientation: -1, ShortName: "sched pauses total gc min"},
+	{Name: "runtime.go.metrics.sched_pauses_total_gc.seconds.max", RuntimeName: "/sched/pauses/total/gc:seconds", Type: "gauge", Unit: "second", PerUnit: "", Description: "max of: Distribution of individual GC-related stop-the-world pause latencies. This is the time from deciding to stop the world until the world is started again. Some of this time is spent getting all threads to stop (this is measured directly in /sched/pauses/stopping/gc:seconds), during which some threads may still be running. Bucket counts increase monotonically.", Orientation: -1, ShortName: "sched pauses total gc max"},
+	{Name: "runtime.go.metrics.sched_pauses_total_gc.seconds.median", RuntimeName: "/sched/pauses/total/gc:seconds", Type: "gauge", Unit: "second", PerUnit: "", Description: "median of: Distribution of individual GC-related stop-the-world pause latencies. This is the time from deciding to stop the world until the world is started again. Some of this time is spent getting all threads to stop (this is measured directly in /sched/pauses/stopping/gc:seconds), during which some threads may still be running. Bucket counts increase monotonically.", Orientation: -1, ShortName: "sched pauses total gc median"},
+	{Name: "runtime.go.metrics.sched_pauses_total_gc.seconds.p95", RuntimeName: "/sched/pauses/total/gc:seconds", Type: "gauge", Unit: "second", PerUnit: "", Description: "p95 of: Distribution of individual GC-related stop-the-world pause latencies. This is the time from deciding to stop the world until the world is started again. Some of this time is spent getting all threads to stop (this is measured directly in /sched/pauses/stopping/gc:seconds), during which some threads may still be running. Bucket counts increase monotonically.", Orientation: -1, ShortName: "sched pauses total gc p95"},
+	{Name: "runtime.go.metrics.sched_pauses_total_gc.seconds.p99", RuntimeName: "/sched/pauses/total/gc:seconds", Type: "gauge", Unit: "second", PerUnit: "", Description: "p99 of: Distribution of individual GC-related stop-the-world pause latencies. This is the time from deciding to stop the world until the world is started again. Some of this time is spent getting all threads to stop (this is measured directly in /sched/pauses/stopping/gc:seconds), during which some threads may still be running. Bucket counts increase monotonically.", Orientation: -1, ShortName: "sched pauses total gc p99"},
+	{Name: "runtime.go.metrics.sched_pauses_total_other.seconds", RuntimeName: "/sched/pauses/total/other:seconds", Type: "distribution", Unit: "second", PerUnit: "", Description: "Distribution of individual non-GC-related stop-the-world pause latencies. This is the time from deciding to stop the world until the world is started again. Some of this time is spent getting all threads to stop (measured directly in /sched/pauses/stopping/other:seconds). Bucket counts increase monotonically.", Orientation: -1, ShortName: "sched pauses total other"},
+	{Name: "runtime.go.metrics.sched_pauses_total_other.seconds.avg", RuntimeName: "/sched/pauses/total/other:seconds", Type: "gauge", Unit: "second", PerUnit: "", Description: "avg of: Distribution of individual non-GC-related stop-the-world pause latencies. This is the time from deciding to stop the world until the world is started again. Some of this time is spent getting all threads to stop (measured directly in /sched/pauses/stopping/other:seconds). Bucket counts increase monotonically.", Orientation: -1, ShortName: "sched pauses total other avg"},
+	{Name: "runtime.go.metrics.sched_pauses_total_other.seconds.min", RuntimeName: "/sched/pauses/total/other:seconds", Type: "gauge", Unit: "second", PerUnit: "", Description: "min of: Distribution of individual non-GC-related stop-the-world pause latencies. This is the time from deciding to stop the world until the world is started again. Some of this time is spent getting all threads to stop (measured directly in /sched/pauses/stopping/other:seconds). Bucket counts increase monotonically.", Orientation: -1, ShortName: "sched pauses total other min"},
+	{Name: "runtime.go.metrics.sched_pauses_total_other.seconds.max", RuntimeName: "/sched/pauses/total/other:seconds", Type: "gauge", Unit: "second", PerUnit: "", Description: "max of: Distribution of individual non-GC-related stop-the-world pause latencies. This is the time from deciding to stop the world until the world is started again. Some of this time is spent getting all threads to stop (measured directly in /sched/pauses/stopping/other:seconds). Bucket counts increase monotonically.", Orientation: -1, ShortName: "sched pauses total other max"},
+	{Name: "runtime.go.metrics.sched_pauses_total_other.seconds.median", RuntimeName: "/sched/pauses/total/other:seconds", Type: "gauge", Unit: "second", PerUnit: "", Description: "median of: Distribution of individual non-GC-related stop-the-world pause latencies. This is the time from deciding to stop the world until the world is started again. Some of this time is spent getting all threads to stop (measured directly in /sched/pauses/stopping/other:seconds). Bucket counts increase monotonically.", Orientation: -1, ShortName: "sched pauses total other median"},
+	{Name: "runtime.go.metrics.sched_pauses_total_other.seconds.p95", RuntimeName: "/sched/pauses/total/other:seconds", Type: "gauge", Unit: "second", PerUnit: "", Description: "p95 of: Distribution of individual non-GC-related stop-the-world pause latencies. This is the time from deciding to stop the world until the world is started again. Some of this time is spent getting all threads to stop (measured directly in /sched/pauses/stopping/other:seconds). Bucket counts increase monotonically.", Orientation: -1, ShortName: "sched pauses total other p95"},
+	{Name: "runtime.go.metrics.sched_pauses_total_other.seconds.p99", RuntimeName: "/sched/pauses/total/other:seconds", Type: "gauge", Unit: "second", PerUnit: "", Description: "p99 of: Distribution of individual non-GC-related stop-the-world pause latencies. This is the time from deciding to stop the world until the world is started again. Some of this time is spent getting all threads to stop (measured directly in /sched/pauses/stopping/other:seconds). Bucket counts increase monotonically.", Orientation: -1, ShortName: "sched pauses total other p99"},
+	{Name: "runtime.go.metrics.sched_threads_total.threads", RuntimeName: "/sched/threads/total:threads", Type: "gauge", Unit: "thread", PerUnit: "", Description: "The current count of live threads that are owned by the Go runtime.", Orientation: 0, ShortName: "sched threads total"},
+	{Name: "runtime.go.metrics.sync_mutex_wait_total.seconds", RuntimeName: "/sync/mutex/wait/total:seconds", Type: "gauge", Unit: "second", PerUnit: "", Description: "Approximate cumulative time goroutines have spent blocked on a sync.Mutex, sync.RWMutex, or runtime-internal lock. This metric is useful for identifying global changes in lock contention. Collect a mutex or block profile using the runtime/pprof package for more detailed contention data.", Orientation: -1, ShortName: "sync mutex wait total"},
+	{Name: "runtime.go.metrics.derived.seconds_since_gc.seconds", RuntimeName: "", Type: "gauge", Unit: "second", PerUnit: "", Description: "Time since the last report that observed a GC cycle. It isn't reported until a GC cycle has been observed.", Orientation: 0, ShortName: "derived seconds since gc seconds"},
+	{Name: "runtime.go.metrics.gc_frequency", RuntimeName: "", Type: "gauge", Unit: "", PerUnit: "second", Description: "Number of GC cycles per second over the last reporting period.", Orientation: -1, ShortName: "gc frequency"},
+	{Name: "runtime.go.metrics.derived.gc_thrashing", RuntimeName: "", Type: "gauge", Unit: "", PerUnit: "", Description: "1 once the fraction of CPU time used by the GC exceeded the thrashing threshold for several consecutive periods, 0 otherwise.", Orientation: -1, ShortName: "derived gc thrashing"},
+	{Name: "runtime.go.metrics.gc_heap_utilization", RuntimeName: "", Type: "gauge", Unit: "fraction", PerUnit: "", Description: "Ratio of the live heap to the heap goal, i.e. how close the heap is to triggering the next GC cycle.", Orientation: 0, ShortName: "gc heap utilization"},
+	{Name: "runtime.go.metrics.heap_fragmentation", RuntimeName: "", Type: "gauge", Unit: "fraction", PerUnit: "", Description: "Fraction of the heap that is free but not released to the OS: free / (free + released + objects).", Orientation: -1, ShortName: "heap fragmentation"},
+	{Name: "runtime.go.metrics.gc_cpu_fraction", RuntimeName: "", Type: "gauge", Unit: "fraction", PerUnit: "", Description: "Fraction of the CPU time used by the GC over the last reporting period.", Orientation: -1, ShortName: "gc cpu fraction"},
+}
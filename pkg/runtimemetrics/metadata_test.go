@@ -0,0 +1,35 @@
+package runtimemetrics
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestMetadata(t *testing.T) {
+	md := Metadata()
+	require.NotEmpty(t, md)
+	assert.NotEmpty(t, MetadataGoVersion)
+
+	names := map[string]MetricMetadata{}
+	for _, m := range md {
+		assert.True(t, strings.HasPrefix(m.Name, datadogMetricPrefix), m.Name)
+		assert.Contains(t, []string{"gauge", "distribution"}, m.Type, m.Name)
+		assert.Contains(t, []int{-1, 0, 1}, m.Orientation, m.Name)
+		assert.NotEmpty(t, m.Description, m.Name)
+		names[m.Name] = m
+	}
+	assert.Len(t, names, len(md), "names should be unique")
+	assert.Equal(t, MetricMetadata{
+		Name:        "runtime.go.metrics.gc_heap_utilization",
+		Type:        "gauge",
+		Unit:        "fraction",
+		Description: "Ratio of the live heap to the heap goal, i.e. how close the heap is to triggering the next GC cycle.",
+		ShortName:   "gc heap utilization",
+	}, names["runtime.go.metrics.gc_heap_utilization"])
+
+	md[0].Name = "modified"
+	assert.NotEqual(t, "modified", Metadata()[0].Name, "the embedded metadata should be copied")
+}
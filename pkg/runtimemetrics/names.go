@@ -0,0 +1,274 @@
+package runtimemetrics
+
+import (
+	"fmt"
+	"log/slog"
+	"runtime/metrics"
+	"slices"
+	"strings"
+	"time"
+)
+
+// skippedValuesMetricName is the self-metric counting the values skipped
+// because they were absurdly high, see report.
+const skippedValuesMetricName = "runtime.go.metrics.skipped_values"
+
+// emittedMetricNames returns the sorted Datadog names of the metrics reported
+// for descs with the given options, see emittedSeries, and of the
+// self-metrics.
+func emittedMetricNames(descs []metrics.Description, opts *Options) []string {
+	rms := newRuntimeMetricStore(descs, nil, opts)
+	defer rms.close()
+	names := []string{skippedValuesMetricName}
+	for _, s := range rms.emittedSeries(descs) {
+		names = append(names, s.name)
+	}
+	slices.Sort(names)
+	// Summaries and tagged families share their names.
+	return slices.Compact(names)
+}
+
+// AllDatadogMetricNames returns the sorted Datadog names of all the metrics
+// the library may report on the running Go version with the default naming
+// options: the runtime metrics, the distribution and summaries of histograms,
+// the counts of HistogramCounts, the metrics of BuildInfo, GOMAXPROCSGauge,
+// NumCPUGauge, GODEBUGActiveGauge, GoroutineSamplePeriod, MemStats and
+// DerivedMetrics, and the self-metrics. The legacy names are listed by
+// LegacyMetrics. This is the list documented by tools/metricmetadata, plus
+// the optional metrics.
+func AllDatadogMetricNames() []string {
+	return emittedMetricNames(metrics.All(), &Options{
+		Logger:                slog.Default(),
+		HistogramCounts:       true,
+		BuildInfo:             true,
+		GOMAXPROCSGauge:       true,
+		NumCPUGauge:           true,
+		GODEBUGActiveGauge:    true,
+		MemStats:              true,
+		DerivedMetrics:        true,
+		GoroutineSamplePeriod: time.Second,
+	})
+}
+
+// series is a metric name and tags pair reported by the store.
+type series struct {
+	kind MetricKind
+	name string
+	tags []string
+}
+
+// emittedSeries returns the distinct series rms reports when all the given
+// runtime metrics changed, including the series that are only reported once
+// some condition is met, e.g. derived metrics that need a GC cycle. The
+// self-metrics are not included.
+func (rms *runtimeMetricStore) emittedSeries(descs []metrics.Description) []series {
+	var res []series
+	if rms.buildInfoTags != nil {
+		res = append(res, series{GaugeMetric, buildInfoMetricName, rms.buildInfoTags})
+	}
+	for _, d := range descs {
+		rm, ok := rms.metrics[d.Name]
+		if !ok {
+			continue
+		}
+		if d.Kind == metrics.KindFloat64Histogram {
+			res = append(res, series{DistributionMetric, rm.ddDistributionName, rms.baseTags})
+			for _, i := range rm.summaryStats {
+				res = append(res, series{GaugeMetric, rm.summaryNames[i], rms.summaryTags[i]})
+			}
+			if rm.countName != "" {
+				res = append(res, series{CountMetric, rm.countName, rms.baseTags})
+			}
+		} else {
+			res = append(res, series{GaugeMetric, rm.ddMetricName, rm.tags})
+		}
+	}
+	var additional []string
+	if rms.gomaxprocsGauge {
+		additional = append(additional, gomaxprocsGaugeName)
+	}
+	if rms.numCPUGauge {
+		additional = append(additional, numCPUGaugeName)
+	}
+	if rms.godebug != nil {
+		additional = append(additional, godebugActiveGaugeName)
+	}
+	if rms.goroutines != nil {
+		res = append(res, series{DistributionMetric, goroutineSamplesMetricName, rms.baseTags})
+	}
+	if rms.derived != nil {
+		additional = append(additional, rms.derived.names()...)
+	}
+	if rms.memStats != nil {
+		additional = append(additional, memStatsMetricNames...)
+	}
+	if rms.legacy != nil && (rms.dualEmitUntil.IsZero() || rms.now().Before(rms.dualEmitUntil)) {
+		for _, m := range rms.legacy {
+			additional = append(additional, m.Name)
+		}
+	}
+	for _, name := range additional {
+		res = append(res, series{GaugeMetric, name, rms.baseTags})
+	}
+
+	// Dedupe, e.g. summaries reported under the same name and tags.
+	seen := map[string]bool{}
+	return slices.DeleteFunc(res, func(s series) bool {
+		key := s.kind.String() + "|" + s.name + "|" + strings.Join(s.tags, ",")
+		if seen[key] {
+			return true
+		}
+		seen[key] = true
+		return false
+	})
+}
+
+// mapping describes the runtime metrics collected by rms, one per line in the
+// order of descs, e.g.
+//
+//	/gc/heap/live:bytes -> runtime.go.metrics.gc_heap_live.bytes (uint64)
+//	/gc/pauses:seconds -> runtime.go.metrics.gc_pauses.seconds (cumulative float64 histogram, distribution: ..., summaries: ...)
+//
+// See Options.LogMappingOnStart.
+func (rms *runtimeMetricStore) mapping(descs []metrics.Description) string {
+	var b strings.Builder
+	for _, d := range descs {
+		rm, ok := rms.metrics[d.Name]
+		if !ok {
+			continue
+		}
+		if b.Len() > 0 {
+			b.WriteByte('\n')
+		}
+		fmt.Fprintf(&b, "%s -> %s (", d.Name, rm.ddMetricName)
+		if rm.cumulative {
+			b.WriteString("cumulative ")
+		}
+		switch d.Kind {
+		case metrics.KindUint64:
+			b.WriteString("uint64")
+		case metrics.KindFloat64:
+			b.WriteString("float64")
+		case metrics.KindFloat64Histogram:
+			var summaries []string
+			for _, i := range rm.summaryStats {
+				summaries = append(summaries, rm.summaryNames[i])
+			}
+			fmt.Fprintf(&b, "float64 histogram, distribution: %s, summaries: %s", rm.ddDistributionName, strings.Join(slices.Compact(summaries), " "))
+			if rm.countName != "" {
+				fmt.Fprintf(&b, ", count: %s", rm.countName)
+			}
+		}
+		if rm.delta {
+			b.WriteString(", delta")
+		}
+		if len(rm.tags) > len(rms.baseTags) {
+			fmt.Fprintf(&b, ", tag: %s", rm.tags[len(rm.tags)-1])
+		}
+		b.WriteByte(')')
+	}
+	return b.String()
+}
+
+// SupportedMetrics returns the descriptions of the runtime/metrics reported
+// by an emitter with the default options, sorted by name. Metrics whose kind
+// or name isn't supported, and aliased metrics whose Datadog name is already
+// reported by another metric, are left out.
+func SupportedMetrics() []metrics.Description {
+	descs := metrics.All()
+	rms := newRuntimeMetricStore(descs, nil, &Options{Logger: slog.Default()})
+	defer rms.close()
+	var res []metrics.Description
+	for _, d := range descs {
+		if _, ok := rms.metrics[d.Name]; ok {
+			res = append(res, d)
+		}
+	}
+	slices.SortFunc(res, func(a, b metrics.Description) int { return strings.Compare(a.Name, b.Name) })
+	return res
+}
+
+// ReferencedMetrics returns the sorted runtime/metrics names the library
+// refers to explicitly, rather than through metrics.All(): the metrics of
+// the base tags, the inputs of derived and legacy metrics, and the aliased
+// metrics. On Go versions missing some of them, the features relying on them
+// are degraded, see metadata.Audit.
+func ReferencedMetrics() []string {
+	names := []string{gogcMetricName, gomemlimitMetricName, gomaxProcsMetricName}
+	for name := range defaultMetricAliases {
+		names = append(names, name)
+	}
+	for _, d := range derivedMetricDescriptions {
+		names = append(names, d.Inputs...)
+	}
+	for _, m := range legacyMetrics {
+		names = append(names, m.RuntimeMetrics...)
+	}
+	slices.Sort(names)
+	return slices.Compact(names)
+}
+
+// DatadogMetricName returns the Datadog name the given runtime/metrics metric
+// is reported under with the default options, e.g.
+// runtime.go.metrics.gc_heap_live.bytes for /gc/heap/live:bytes, taking the
+// built-in aliases that apply to the running Go version into account.
+// Histograms are reported as a distribution under this name, and their
+// summaries under DatadogSummaryMetricName.
+func DatadogMetricName(runtimeName string) (string, error) {
+	name, _, err := DatadogMetricNameWithOptions(runtimeName, nil)
+	return name, err
+}
+
+// DatadogSummaryMetricName returns the Datadog name of the gauge reporting
+// the given summary of a runtime/metrics histogram with the default options,
+// e.g. runtime.go.metrics.gc_pauses.seconds.p95 for /gc/pauses:seconds and
+// p95. The summaries are avg, min, max, median, p95 and p99, see
+// HistogramSummaries.
+func DatadogSummaryMetricName(runtimeName, stat string) (string, error) {
+	name, _, err := DatadogSummaryMetricNameWithOptions(runtimeName, stat, nil)
+	return name, err
+}
+
+// DatadogMetricNameWithOptions is DatadogMetricName for an emitter created
+// with opts rather than the default options, taking the options renaming
+// metrics into account: MetricAliases, TaggedFamilies, NameSeparator,
+// SubsystemPrefixes, DurationUnit and UnitScale. tag is the tag the metric
+// is reported with besides the base tags, e.g. state:running for
+// /sched/goroutines/running:goroutines with the goroutines family, or "". It
+// returns an error if opts is invalid, like NewEmitter.
+func DatadogMetricNameWithOptions(runtimeName string, opts *Options) (name, tag string, err error) {
+	if opts == nil {
+		opts = &Options{}
+	}
+	descs := metrics.All()
+	if err := validateOptions(opts, descs); err != nil {
+		return "", "", err
+	}
+	name, tag, _, err = reportedMetricName(runtimeName, metricAliases(opts.MetricAliases, descs), opts)
+	return name, tag, err
+}
+
+// DatadogSummaryMetricNameWithOptions is DatadogSummaryMetricName for an
+// emitter created with opts, see DatadogMetricNameWithOptions. With
+// Options.PercentilesAsTags, the summaries share the <histogram>.summary
+// name, and tag is the stat:<summary> tag telling them apart, otherwise it is
+// "".
+func DatadogSummaryMetricNameWithOptions(runtimeName, stat string, opts *Options) (name, tag string, err error) {
+	if !slices.Contains(histogramStatNames[:], stat) {
+		return "", "", fmt.Errorf("runtimemetrics: unknown histogram summary %q", stat)
+	}
+	ddMetricName, _, err := DatadogMetricNameWithOptions(runtimeName, opts)
+	if err != nil {
+		return "", "", err
+	}
+	if opts != nil && opts.PercentilesAsTags {
+		return ddMetricName + ".summary", "stat:" + stat, nil
+	}
+	return summaryMetricName(ddMetricName, stat), "", nil
+}
+
+// summaryMetricName returns the name of the gauge reporting the given summary
+// of the histogram reported as ddMetricName.
+func summaryMetricName(ddMetricName, stat string) string {
+	return ddMetricName + "." + stat
+}
@@ -0,0 +1,293 @@
+package runtimemetrics
+
+import (
+	"bytes"
+	"flag"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"regexp"
+	"runtime"
+	"runtime/metrics"
+	"slices"
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/DataDog/go-runtime-metrics-internal/internal/metadata"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+var update = flag.Bool("update", false, "update the golden files in testdata")
+
+// goMinorVersionRegex matches the Go version a golden file applies to, e.g.
+// go1.22 for go1.22.3, capturing the minor version.
+var goMinorVersionRegex = regexp.MustCompile(`^go1\.(\d+)`)
+
+// TestEmittedMetricNames pins the Datadog names of all metrics emitted with
+// the default options, renaming a metric breaks dashboards and monitors. Run
+// with -update to regenerate the golden file of the current Go version.
+//
+// Every Go version from the oldest supported one has a golden file. The test
+// only skips versions newer than the newest golden file, to not fail before
+// a new Go release has been added.
+func TestEmittedMetricNames(t *testing.T) {
+	m := goMinorVersionRegex.FindStringSubmatch(runtime.Version())
+	if m == nil {
+		t.Skipf("unsupported Go version: %s", runtime.Version())
+	}
+	goVersion := m[0]
+	golden := filepath.Join("testdata", "metric_names", goVersion+".golden")
+	got := strings.Join(emittedMetricNames(metrics.All(), &Options{Logger: slog.Default()}), "\n") + "\n"
+
+	if *update {
+		require.NoError(t, os.MkdirAll(filepath.Dir(golden), 0o755))
+		require.NoError(t, os.WriteFile(golden, []byte(got), 0o644))
+		return
+	}
+	want, err := os.ReadFile(golden)
+	if os.IsNotExist(err) {
+		minor, _ := strconv.Atoi(m[1])
+		if minor > newestGoldenMinorVersion(t) {
+			t.Skipf("no golden file for %s, run go test -run TestEmittedMetricNames -update with that version to add it", goVersion)
+		}
+		t.Fatalf("no golden file for %s, run go test -run TestEmittedMetricNames -update with that version to add it", goVersion)
+	}
+	require.NoError(t, err)
+	assert.Equal(t, string(want), got, "emitted metric names changed, run go test -run TestEmittedMetricNames -update if this is intended")
+}
+
+// newestGoldenMinorVersion returns the minor Go version of the newest golden
+// file of TestEmittedMetricNames.
+func newestGoldenMinorVersion(t *testing.T) int {
+	files, err := filepath.Glob(filepath.Join("testdata", "metric_names", "go1.*.golden"))
+	require.NoError(t, err)
+	newest := 0
+	for _, f := range files {
+		if m := goMinorVersionRegex.FindStringSubmatch(filepath.Base(f)); m != nil {
+			minor, _ := strconv.Atoi(m[1])
+			newest = max(newest, minor)
+		}
+	}
+	return newest
+}
+
+func TestEmittedMetricNamesOptions(t *testing.T) {
+	descs := []metrics.Description{
+		metricDesc("/gc/pauses:seconds", metrics.KindFloat64Histogram),
+		fakeMetricDesc("/sched/goroutines/running:goroutines", metrics.KindUint64),
+		fakeMetricDesc("/sched/goroutines/waiting:goroutines", metrics.KindUint64),
+	}
+	names := emittedMetricNames(descs, &Options{
+		Logger:             slog.Default(),
+		DistributionSuffix: ".distribution",
+		PercentilesAsTags:  true,
+		TaggedFamilies:     []string{"goroutines"},
+	})
+	assert.Equal(t, []string{
+		"runtime.go.metrics.gc_pauses.seconds.distribution",
+		"runtime.go.metrics.gc_pauses.seconds.summary",
+		"runtime.go.metrics.sched_goroutines_by_state.goroutines",
+		"runtime.go.metrics.skipped_values",
+	}, names)
+}
+
+func TestDatadogSummaryMetricName(t *testing.T) {
+	t.Run("should return the name of each summary", func(t *testing.T) {
+		mock, _ := reportMetric("/gc/pauses:seconds", metrics.KindFloat64Histogram)
+		for _, stat := range []string{"avg", "min", "max", "median", "p95", "p99"} {
+			name, err := DatadogSummaryMetricName("/gc/pauses:seconds", stat)
+			require.NoError(t, err)
+			assert.Equal(t, "runtime.go.metrics.gc_pauses.seconds."+stat, name)
+			assert.Len(t, mock.CallsWithSuffix(name).Gauges, 1, "should match the reported name")
+		}
+	})
+
+	t.Run("should match the names of the store", func(t *testing.T) {
+		rms := newRuntimeMetricStore(metrics.All(), nil, &Options{Logger: slog.Default()})
+		defer rms.close()
+		for _, d := range metrics.All() {
+			rm, ok := rms.metrics[d.Name]
+			if !ok || d.Kind != metrics.KindFloat64Histogram {
+				continue
+			}
+			for i, stat := range histogramStatNames {
+				name, err := DatadogSummaryMetricName(d.Name, stat)
+				require.NoError(t, err)
+				assert.Equal(t, rm.summaryNames[i], name, d.Name)
+			}
+		}
+	})
+
+	t.Run("should return an error for an unknown summary", func(t *testing.T) {
+		name, err := DatadogSummaryMetricName("/gc/pauses:seconds", "p50")
+		assert.EqualError(t, err, `runtimemetrics: unknown histogram summary "p50"`)
+		assert.Empty(t, name)
+	})
+
+	t.Run("should return an error for an invalid metric name", func(t *testing.T) {
+		name, err := DatadogSummaryMetricName("Lorem Ipsum", "p95")
+		assert.Error(t, err)
+		assert.Empty(t, name)
+	})
+}
+
+func TestDatadogMetricNameWithOptions(t *testing.T) {
+	opts := &Options{
+		Logger:            slog.Default(),
+		TaggedFamilies:    []string{"goroutines", "memory_classes"},
+		SubsystemPrefixes: map[string]string{"sched": "runtime.sched."},
+		MetricAliases:     map[string]string{"/gc/heap/goal:bytes": "runtime.go.metrics.heap_goal.bytes"},
+	}
+
+	t.Run("should match the reported names", func(t *testing.T) {
+		descs := metrics.All()
+		rms := newRuntimeMetricStore(descs, nil, opts)
+		defer rms.close()
+		require.NotEmpty(t, rms.metrics)
+		for runtimeName, rm := range rms.metrics {
+			name, tag, err := DatadogMetricNameWithOptions(runtimeName, opts)
+			require.NoError(t, err)
+			assert.Equal(t, rm.ddMetricName, name, runtimeName)
+			assert.Equal(t, rm.tags[len(rms.baseTags):], slices.DeleteFunc([]string{tag}, func(s string) bool { return s == "" }), runtimeName)
+		}
+	})
+
+	t.Run("should apply the naming options", func(t *testing.T) {
+		name, tag, err := DatadogMetricNameWithOptions("/sched/goroutines/runnable:goroutines", opts)
+		require.NoError(t, err)
+		assert.Equal(t, "runtime.sched.sched_goroutines_by_state.goroutines", name)
+		assert.Equal(t, "state:runnable", tag)
+
+		name, tag, err = DatadogMetricNameWithOptions("/gc/heap/goal:bytes", opts)
+		require.NoError(t, err)
+		assert.Equal(t, "runtime.go.metrics.heap_goal.bytes", name)
+		assert.Empty(t, tag)
+
+		name, tag, err = DatadogSummaryMetricNameWithOptions("/sched/latencies:seconds", "p99", opts)
+		require.NoError(t, err)
+		assert.Equal(t, "runtime.sched.sched_latencies.seconds.p99", name)
+		assert.Empty(t, tag)
+	})
+
+	t.Run("should tag the summaries with PercentilesAsTags", func(t *testing.T) {
+		name, tag, err := DatadogSummaryMetricNameWithOptions("/gc/pauses:seconds", "p99", &Options{PercentilesAsTags: true})
+		require.NoError(t, err)
+		assert.Equal(t, "runtime.go.metrics.gc_pauses.seconds.summary", name)
+		assert.Equal(t, "stat:p99", tag)
+	})
+
+	t.Run("should default to the default options", func(t *testing.T) {
+		name, tag, err := DatadogMetricNameWithOptions("/gc/heap/live:bytes", nil)
+		require.NoError(t, err)
+		assert.Equal(t, "runtime.go.metrics.gc_heap_live.bytes", name)
+		assert.Empty(t, tag)
+	})
+
+	t.Run("should return an error for invalid options", func(t *testing.T) {
+		_, _, err := DatadogMetricNameWithOptions("/gc/heap/live:bytes", &Options{TaggedFamilies: []string{"nope"}})
+		assert.Error(t, err)
+		_, _, err = DatadogSummaryMetricNameWithOptions("/gc/pauses:seconds", "p99", &Options{SubsystemPrefixes: map[string]string{"nope": "a."}})
+		assert.Error(t, err)
+	})
+}
+
+func TestSupportedMetrics(t *testing.T) {
+	descs := SupportedMetrics()
+	require.NotEmpty(t, descs)
+	assert.True(t, slices.IsSortedFunc(descs, func(a, b metrics.Description) int { return strings.Compare(a.Name, b.Name) }))
+
+	names := emittedMetricNames(metrics.All(), &Options{Logger: slog.Default()})
+	for _, d := range descs {
+		name, err := DatadogMetricName(d.Name)
+		require.NoError(t, err)
+		assert.Contains(t, names, name, d.Name)
+	}
+
+	name, err := DatadogMetricName("/sched/pauses/total/gc:seconds")
+	require.NoError(t, err)
+	assert.Equal(t, "runtime.go.metrics.sched_pauses_total_gc.seconds", name, "aliases shouldn't apply while /gc/pauses:seconds exists")
+
+	_, err = DatadogMetricName("/gc/heap/live")
+	assert.Error(t, err)
+}
+
+func TestMapping(t *testing.T) {
+	descs := []metrics.Description{
+		metricDesc("/gc/heap/live:bytes", metrics.KindUint64),
+		metricDesc("/gc/pauses:seconds", metrics.KindFloat64Histogram),
+		fakeMetricDesc("/sched/goroutines/runnable:goroutines", metrics.KindUint64),
+	}
+	rms := newRuntimeMetricStore(descs, nil, &Options{
+		Logger:            slog.Default(),
+		HistogramCounts:   true,
+		PercentilesAsTags: true,
+		TaggedFamilies:    []string{"goroutines"},
+	})
+	defer rms.close()
+	assert.Equal(t, strings.Join([]string{
+		"/gc/heap/live:bytes -> runtime.go.metrics.gc_heap_live.bytes (uint64)",
+		"/gc/pauses:seconds -> runtime.go.metrics.gc_pauses.seconds (cumulative float64 histogram, distribution: runtime.go.metrics.gc_pauses.seconds, summaries: runtime.go.metrics.gc_pauses.seconds.summary, count: runtime.go.metrics.gc_pauses.seconds.count)",
+		"/sched/goroutines/runnable:goroutines -> runtime.go.metrics.sched_goroutines_by_state.goroutines (uint64, tag: state:runnable)",
+	}, "\n"), rms.mapping(descs))
+
+	t.Run("should be logged on start", func(t *testing.T) {
+		var logs bytes.Buffer
+		e, err := NewEmitter(&statsdClientMock{}, &Options{
+			Logger:            slog.New(slog.NewTextHandler(&logs, nil)),
+			LogMappingOnStart: true,
+		})
+		require.NoError(t, err)
+		e.Stop()
+		assert.Contains(t, logs.String(), "runtimemetrics: metric mapping")
+		assert.Contains(t, logs.String(), "/gc/heap/live:bytes -> runtime.go.metrics.gc_heap_live.bytes (uint64)")
+	})
+}
+
+func TestAllDatadogMetricNames(t *testing.T) {
+	names := AllDatadogMetricNames()
+	assert.True(t, slices.IsSorted(names))
+	assert.Equal(t, slices.Compact(slices.Clone(names)), names, "names should be unique")
+
+	for _, name := range []string{
+		"runtime.go.metrics.gc_pauses.seconds",
+		"runtime.go.metrics.gc_pauses.seconds.avg",
+		"runtime.go.metrics.gc_pauses.seconds.min",
+		"runtime.go.metrics.gc_pauses.seconds.max",
+		"runtime.go.metrics.gc_pauses.seconds.median",
+		"runtime.go.metrics.gc_pauses.seconds.p95",
+		"runtime.go.metrics.gc_pauses.seconds.p99",
+		"runtime.go.metrics.gc_pauses.seconds.count",
+		"runtime.go.metrics.gc_heap_live.bytes",
+		gcFrequencyMetricName,
+		gomaxprocsGaugeName,
+		numCPUGaugeName,
+		godebugActiveGaugeName,
+		goroutineSamplesMetricName,
+		buildInfoMetricName,
+		skippedValuesMetricName,
+	} {
+		assert.Contains(t, names, name)
+	}
+	for _, name := range emittedMetricNames(metrics.All(), &Options{Logger: slog.Default()}) {
+		assert.Contains(t, names, name, "the default metrics should be included")
+	}
+}
+
+// TestAudit ensures the running toolchain has all the metrics the library
+// refers to, see metadata.Audit.
+func TestAudit(t *testing.T) {
+	referenced := ReferencedMetrics()
+	assert.True(t, slices.IsSorted(referenced))
+	assert.Contains(t, referenced, gogcMetricName)
+	assert.Contains(t, referenced, gcCyclesMetricName)
+
+	var supported []string
+	for _, d := range SupportedMetrics() {
+		supported = append(supported, d.Name)
+	}
+	r := metadata.Audit(metrics.All(), supported, referenced)
+	assert.Empty(t, r.Missing)
+	assert.Empty(t, r.UnmappedUnits)
+}
@@ -0,0 +1,151 @@
+package runtimemetrics
+
+import (
+	"bufio"
+	"bytes"
+	"io"
+	"math"
+	"net/http"
+	"regexp"
+	"runtime/metrics"
+	"strconv"
+	"strings"
+)
+
+// invalidOpenMetricsNameRegex matches the characters OpenMetrics doesn't
+// allow in metric names.
+var invalidOpenMetricsNameRegex = regexp.MustCompile(`[^a-zA-Z0-9_:]`)
+
+// OpenMetricsName returns the OpenMetrics name of the metric reported under
+// the given Datadog name: the characters that are invalid in OpenMetrics
+// names, i.e. the periods, are replaced with underscores, e.g.
+// runtime_go_metrics_gc_heap_live_bytes for
+// runtime.go.metrics.gc_heap_live.bytes.
+func OpenMetricsName(ddMetricName string) string {
+	return invalidOpenMetricsNameRegex.ReplaceAllString(ddMetricName, "_")
+}
+
+// WriteOpenMetrics reads the runtime metrics reported with the default
+// options once, see SupportedMetrics, and writes them to w in the
+// OpenMetrics text format, e.g. for debugging without a statsd pipeline. It
+// reads the runtime metrics independently and can be called while an emitter
+// is running.
+//
+// Metrics are named after their Datadog name, see OpenMetricsName, and
+// described by their runtime/metrics description. Values aren't converted,
+// unlike Options.DurationUnit or Options.UnitScale:
+//
+//   - cumulative metrics are counters, and their sample has the _total
+//     suffix,
+//   - other scalar metrics are gauges,
+//   - cumulative histograms are histograms, with the runtime buckets as le
+//     buckets and a _count. The runtime doesn't track their sum, so there
+//     is no _sum.
+//   - other histograms are gauge histograms, with a _gcount.
+func WriteOpenMetrics(w io.Writer) error {
+	descs := SupportedMetrics()
+	samples := make([]metrics.Sample, len(descs))
+	for i, d := range descs {
+		samples[i].Name = d.Name
+	}
+	metrics.Read(samples)
+
+	bw := bufio.NewWriter(w)
+	for i, d := range descs {
+		ddMetricName, err := DatadogMetricName(d.Name)
+		if err != nil {
+			continue
+		}
+		writeOpenMetricsFamily(bw, OpenMetricsName(ddMetricName), d, samples[i].Value)
+	}
+	bw.WriteString("# EOF\n")
+	return bw.Flush()
+}
+
+// openMetricsContentType is the content type of the OpenMetrics text format.
+const openMetricsContentType = "application/openmetrics-text; version=1.0.0; charset=utf-8"
+
+// OpenMetricsHandler returns an http.Handler serving the runtime metrics in
+// the OpenMetrics text format, read on each request with WriteOpenMetrics,
+// e.g. for Prometheus scrapers. Scrapes don't affect the reports of e.
+func (e *Emitter) OpenMetricsHandler() http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var buf bytes.Buffer
+		if err := WriteOpenMetrics(&buf); err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+		w.Header().Set("Content-Type", openMetricsContentType)
+		w.Write(buf.Bytes())
+	})
+}
+
+// writeOpenMetricsFamily writes the metric family of the runtime metric d
+// with the value v.
+func writeOpenMetricsFamily(w *bufio.Writer, name string, d metrics.Description, v metrics.Value) {
+	cumulative := isCumulative(d)
+	var typ string
+	switch {
+	case v.Kind() == metrics.KindFloat64Histogram && cumulative:
+		typ = "histogram"
+	case v.Kind() == metrics.KindFloat64Histogram:
+		typ = "gaugehistogram"
+	case cumulative:
+		typ = "counter"
+	default:
+		typ = "gauge"
+	}
+	w.WriteString("# TYPE " + name + " " + typ + "\n")
+	w.WriteString("# HELP " + name + " " + openMetricsHelpEscaper.Replace(d.Description) + "\n")
+
+	switch v.Kind() {
+	case metrics.KindUint64:
+		writeOpenMetricsSample(w, name, typ, "", strconv.FormatUint(v.Uint64(), 10))
+	case metrics.KindFloat64:
+		writeOpenMetricsSample(w, name, typ, "", formatOpenMetricsFloat(v.Float64()))
+	case metrics.KindFloat64Histogram:
+		h := v.Float64Histogram()
+		var count uint64
+		for i, c := range h.Counts {
+			count += c
+			le := h.Buckets[i+1]
+			if math.IsInf(le, -1) {
+				continue
+			}
+			writeOpenMetricsSample(w, name+"_bucket", "", `{le="`+formatOpenMetricsFloat(le)+`"}`, strconv.FormatUint(count, 10))
+		}
+		if !math.IsInf(h.Buckets[len(h.Buckets)-1], 1) {
+			writeOpenMetricsSample(w, name+"_bucket", "", `{le="+Inf"}`, strconv.FormatUint(count, 10))
+		}
+		if typ == "histogram" {
+			writeOpenMetricsSample(w, name+"_count", "", "", strconv.FormatUint(count, 10))
+		} else {
+			writeOpenMetricsSample(w, name+"_gcount", "", "", strconv.FormatUint(count, 10))
+		}
+	}
+}
+
+// writeOpenMetricsSample writes a sample line, adding the _total suffix to
+// the samples of counters.
+func writeOpenMetricsSample(w *bufio.Writer, name, typ, labels, value string) {
+	if typ == "counter" {
+		name += "_total"
+	}
+	w.WriteString(name + labels + " " + value + "\n")
+}
+
+// formatOpenMetricsFloat formats f as an OpenMetrics number.
+func formatOpenMetricsFloat(f float64) string {
+	switch {
+	case math.IsInf(f, 1):
+		return "+Inf"
+	case math.IsInf(f, -1):
+		return "-Inf"
+	case math.IsNaN(f):
+		return "NaN"
+	}
+	return strconv.FormatFloat(f, 'g', -1, 64)
+}
+
+// see https://github.com/OpenObservability/OpenMetrics/blob/main/specification/OpenMetrics.md#escaping
+var openMetricsHelpEscaper = strings.NewReplacer(`\`, `\\`, "\n", `\n`, `"`, `\"`)
@@ -0,0 +1,184 @@
+package runtimemetrics
+
+import (
+	"bufio"
+	"bytes"
+	"fmt"
+	"io"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"regexp"
+	"slices"
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// openMetricsFamily is a metric family parsed by parseOpenMetrics.
+type openMetricsFamily struct {
+	typ     string
+	help    string
+	samples map[string]float64
+}
+
+var openMetricsSampleRegex = regexp.MustCompile(`^([a-zA-Z_:][a-zA-Z0-9_:]*)(\{le="([^"]+)"\})? (\S+)$`)
+
+// parseOpenMetrics parses the subset of the OpenMetrics text format written
+// by WriteOpenMetrics, checking the structure the tests rely on: declared
+// families, sample suffixes per type, cumulative buckets ending with +Inf and
+// matching the count, and the terminating # EOF. The conformance of the output
+// is checked with the Prometheus parser in internal/openmetricstest.
+func parseOpenMetrics(text string) (map[string]*openMetricsFamily, error) {
+	families := map[string]*openMetricsFamily{}
+	var current string
+	var lastBucket float64
+	var sawInf, eof bool
+	scanner := bufio.NewScanner(strings.NewReader(text))
+	for scanner.Scan() {
+		line := scanner.Text()
+		if eof {
+			return nil, fmt.Errorf("line after # EOF: %q", line)
+		}
+		switch {
+		case line == "# EOF":
+			eof = true
+		case strings.HasPrefix(line, "# TYPE "):
+			fields := strings.Fields(line)
+			if len(fields) != 4 {
+				return nil, fmt.Errorf("invalid TYPE line: %q", line)
+			}
+			if _, ok := families[fields[2]]; ok {
+				return nil, fmt.Errorf("duplicate family %s", fields[2])
+			}
+			current, lastBucket, sawInf = fields[2], 0, false
+			families[current] = &openMetricsFamily{typ: fields[3], samples: map[string]float64{}}
+		case strings.HasPrefix(line, "# HELP "):
+			name, help, _ := strings.Cut(strings.TrimPrefix(line, "# HELP "), " ")
+			if name != current {
+				return nil, fmt.Errorf("HELP for %s in family %s", name, current)
+			}
+			families[current].help = help
+		default:
+			m := openMetricsSampleRegex.FindStringSubmatch(line)
+			if m == nil {
+				return nil, fmt.Errorf("invalid sample line: %q", line)
+			}
+			f := families[current]
+			if f == nil {
+				return nil, fmt.Errorf("sample before any family: %q", line)
+			}
+			value, err := strconv.ParseFloat(m[4], 64)
+			if err != nil {
+				return nil, fmt.Errorf("invalid value in %q: %w", line, err)
+			}
+			suffix, ok := strings.CutPrefix(m[1], current)
+			if !ok {
+				return nil, fmt.Errorf("sample %s outside of family %s", m[1], current)
+			}
+			allowed := map[string][]string{
+				"gauge":          {""},
+				"counter":        {"_total"},
+				"histogram":      {"_bucket", "_count"},
+				"gaugehistogram": {"_bucket", "_gcount"},
+			}[f.typ]
+			if !slices.Contains(allowed, suffix) {
+				return nil, fmt.Errorf("invalid sample %s for a %s", m[1], f.typ)
+			}
+			switch suffix {
+			case "_bucket":
+				if value < lastBucket {
+					return nil, fmt.Errorf("buckets of %s aren't cumulative", current)
+				}
+				lastBucket = value
+				sawInf = m[3] == "+Inf"
+				if _, err := strconv.ParseFloat(m[3], 64); err != nil {
+					return nil, fmt.Errorf("invalid le in %q", line)
+				}
+				f.samples[m[1]+`{le="`+m[3]+`"}`] = value
+				continue
+			case "_count", "_gcount":
+				if !sawInf || value != lastBucket {
+					return nil, fmt.Errorf("the count of %s doesn't match its +Inf bucket", current)
+				}
+			}
+			f.samples[m[1]] = value
+		}
+	}
+	if !eof {
+		return nil, fmt.Errorf("missing # EOF")
+	}
+	return families, scanner.Err()
+}
+
+func TestWriteOpenMetrics(t *testing.T) {
+	var buf bytes.Buffer
+	require.NoError(t, WriteOpenMetrics(&buf))
+	families, err := parseOpenMetrics(buf.String())
+	require.NoError(t, err, buf.String())
+
+	for _, d := range SupportedMetrics() {
+		ddMetricName, err := DatadogMetricName(d.Name)
+		require.NoError(t, err)
+		assert.Contains(t, families, OpenMetricsName(ddMetricName), d.Name)
+	}
+
+	live := families["runtime_go_metrics_gc_heap_live_bytes"]
+	require.NotNil(t, live)
+	assert.Equal(t, "gauge", live.typ)
+	assert.Contains(t, live.samples, "runtime_go_metrics_gc_heap_live_bytes")
+	assert.NotEmpty(t, live.help)
+
+	allocs := families["runtime_go_metrics_gc_heap_allocs_bytes"]
+	require.NotNil(t, allocs)
+	assert.Equal(t, "counter", allocs.typ)
+	assert.Positive(t, allocs.samples["runtime_go_metrics_gc_heap_allocs_bytes_total"])
+
+	pauses := families["runtime_go_metrics_gc_pauses_seconds"]
+	require.NotNil(t, pauses)
+	assert.Equal(t, "histogram", pauses.typ)
+	assert.Contains(t, pauses.samples, `runtime_go_metrics_gc_pauses_seconds_bucket{le="+Inf"}`)
+	assert.Contains(t, pauses.samples, "runtime_go_metrics_gc_pauses_seconds_count")
+
+	t.Run("should not disturb a running emitter", func(t *testing.T) {
+		mock := &statsdClientMock{}
+		rms := newRuntimeMetricStore(SupportedMetrics(), mock, &Options{Logger: slog.Default()})
+		rm := rms.metrics["/gc/heap/allocs:bytes"]
+		value, timestamp := rm.currentValue, rm.timestamp
+		require.NoError(t, WriteOpenMetrics(&bytes.Buffer{}))
+		assert.Equal(t, value, rm.currentValue)
+		assert.Equal(t, timestamp, rm.timestamp)
+	})
+}
+
+func TestOpenMetricsName(t *testing.T) {
+	assert.Equal(t, "runtime_go_metrics_gc_heap_live_bytes", OpenMetricsName("runtime.go.metrics.gc_heap_live.bytes"))
+	assert.Equal(t, "runtime_go_metrics_memory_classes_heap_free_bytes", OpenMetricsName("runtime.go.metrics.memory-classes/heap.free.bytes"))
+}
+
+func TestOpenMetricsHandler(t *testing.T) {
+	e, err := NewEmitter(&statsdClientMock{}, nil)
+	require.NoError(t, err)
+	defer e.Stop()
+
+	server := httptest.NewServer(e.OpenMetricsHandler())
+	defer server.Close()
+	resp, err := http.Get(server.URL)
+	require.NoError(t, err)
+	defer resp.Body.Close()
+	assert.Equal(t, http.StatusOK, resp.StatusCode)
+	assert.Equal(t, openMetricsContentType, resp.Header.Get("Content-Type"))
+	body, err := io.ReadAll(resp.Body)
+	require.NoError(t, err)
+
+	families, err := parseOpenMetrics(string(body))
+	require.NoError(t, err, string(body))
+	assert.Equal(t, "gauge", families["runtime_go_metrics_gc_heap_live_bytes"].typ)
+	assert.Contains(t, string(body), "\nruntime_go_metrics_gc_heap_live_bytes ")
+	assert.Equal(t, "histogram", families["runtime_go_metrics_gc_pauses_seconds"].typ)
+	assert.Regexp(t, `\nruntime_go_metrics_gc_pauses_seconds_bucket\{le="\+Inf"\} \d+\n`, string(body))
+	assert.Regexp(t, `\nruntime_go_metrics_gc_pauses_seconds_count \d+\n`, string(body))
+}
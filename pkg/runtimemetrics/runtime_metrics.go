@@ -2,20 +2,30 @@
 package runtimemetrics
 
 import (
+	"context"
 	"errors"
 	"fmt"
+	"io"
 	"log/slog"
 	"math"
+	"reflect"
 	"regexp"
+	"runtime"
+	"runtime/debug"
 	"runtime/metrics"
+	"slices"
 	"strings"
 	"sync"
+	"sync/atomic"
 	"time"
+
+	"github.com/DataDog/go-runtime-metrics-internal/internal/metadata"
 )
 
-// pollFrequency is the frequency at which we poll runtime/metrics and report
-// them to statsd. The statsd client aggregates this data, usually over a 2s
-// window [1], and so does the agent, usually over a 10s window [2].
+// DefaultPeriod is the default period at which we poll runtime/metrics and
+// report them to statsd, see Options.Period. The statsd client aggregates
+// this data, usually over a 2s window [1], and so does the agent, usually
+// over a 10s window [2].
 //
 // Our goal is to submit one data point per aggregation window, using the
 // CountWithTimestamp / GaugeWithTimestamp APIs for submitting precisely aligned
@@ -23,33 +33,485 @@ import (
 //
 // [1] https://github.com/DataDog/datadog-go/blob/e612112c8bb396b33ad5d9edd645d289b07d0e40/statsd/options.go/#L23
 // [2] https://docs.datadoghq.com/developers/dogstatsd/data_aggregation/#how-is-aggregation-performed-with-the-dogstatsd-server
-var pollFrequency = 10 * time.Second
+const DefaultPeriod = 10 * time.Second
 
 var unknownMetricLogOnce, unsupportedKindLogOnce sync.Once
 
 // mu protects the variables below
 var mu sync.Mutex
-var enabled bool
 
-// NOTE: The Start method below is intentionally minimal for now. We probably want to think about
+// activeEmitters are the running emitters, in the order they were started,
+// see ActiveEmitters.
+var activeEmitters []*Emitter
+
+// Options are the options for the runtime metrics emitter.
+type Options struct {
+	// Logger is used to log errors. Defaults to slog.Default() if nil.
+	Logger *slog.Logger
+	// Period is the period at which runtime/metrics are polled and reported
+	// to statsd. Defaults to DefaultPeriod, see its documentation for why.
+	// PeriodForBudget can be used to derive a period from an ingestion
+	// budget. Periods shorter than MinPeriod are raised to it, and periods
+	// longer than 10 minutes log a warning, see checkPeriod.
+	Period time.Duration
+	// InitialDelay delays the collection of runtime metrics after the
+	// emitter starts, so that the process can warm up. The first report
+	// happens one Period after the delay, or WarmUp after it if set, and only
+	// covers that interval. Stopping the emitter during the delay prevents
+	// any report. Defaults to 0, no delay.
+	InitialDelay time.Duration
+	// WarmUp makes the first report happen WarmUp after the emitter starts,
+	// or after InitialDelay, rather than one Period after. The baselines of
+	// cumulative metrics are read when the emitter starts, so the deltas of
+	// the first report cover the warm-up interval, not the lifetime of the
+	// process, and are available early, e.g. for short-lived processes. The
+	// tradeoff is a first report over a short interval: its histograms have
+	// few samples, which makes their summaries noisy. Subsequent reports
+	// happen every Period after the first one. Defaults to 0, disabled.
+	WarmUp time.Duration
+	// WarmupPeriods suppresses the delta-based series of the first
+	// WarmupPeriods reports, which process startup skews: the distribution,
+	// summaries and count of cumulative histograms, the derived metrics
+	// computed from deltas (seconds_since_gc, gc_frequency, gc_thrashing
+	// and gc_cpu_fraction), the metrics of GaugeAsDelta, and the legacy
+	// metrics computed from histograms. They are computed all the same, so
+	// that their baselines and state are current once the warm-up
+	// completes. Other series, including the running totals of cumulative
+	// scalar metrics, are reported from the first report. Unlike InitialDelay
+	// and WarmUp, this doesn't change when reports happen. Reports skipped
+	// while the client is nil or closed don't count. Defaults to 0, disabled.
+	WarmupPeriods int
+	// Tags are added to all metrics, after the base tags (gogc, gomemlimit,
+	// gomaxprocs).
+	Tags []string
+	// SelfMetricTags are added to the metrics reporting on the library
+	// itself, such as runtime.go.metrics.skipped_values, after Tags, e.g.
+	// telemetry:internal to exclude them from cost dashboards. They aren't
+	// added to the runtime metrics.
+	SelfMetricTags []string
+	// OnReport is called at the end of each report that submitted metrics to
+	// the statsd client, from the reporting goroutine.
+	OnReport func(ReportStats)
+	// BeforeReport is called at the start of each report that collects
+	// metrics, from the reporting goroutine, e.g. to start a span tracing
+	// the collection. The context it returns, derived from the one it is
+	// given, is passed to AfterReport.
+	BeforeReport func(context.Context) context.Context
+	// AfterReport is called at the end of each report BeforeReport was
+	// called for, after OnReport, including the reports of a paused emitter
+	// which don't submit any metric. Either hook may be set without the
+	// other, the context is then context.Background().
+	AfterReport func(context.Context, ReportStats)
+	// HistorySize is the number of reports the emitter retains, along with
+	// the gauges and counts they submitted, to inspect what was recently
+	// sent, see Emitter.History. Distribution samples aren't retained, so
+	// the memory used is bounded by HistorySize times the number of metrics.
+	// Defaults to 0, disabled.
+	HistorySize int
+	// BuildInfo enables the runtime.go.metrics.build_info gauge. It is
+	// always reported with a value of 1 and tagged with the VCS revision,
+	// VCS time and Go version found in the binary's build info, so deploys
+	// can be correlated in dashboards.
+	BuildInfo bool
+	// GOMAXPROCSGauge additionally reports the live value of GOMAXPROCS as
+	// the runtime.go.metrics.gomaxprocs gauge each report, to graph its
+	// changes over time, e.g. when it is adjusted to cgroup limits. The
+	// gomaxprocs base tag keeps being attached, with the value read when the
+	// emitter started.
+	GOMAXPROCSGauge bool
+	// NumCPUGauge additionally reports runtime.NumCPU(), the number of CPUs
+	// usable by the process, as the runtime.go.metrics.num_cpu gauge each
+	// report. Compared with GOMAXPROCS, see GOMAXPROCSGauge, it shows the
+	// scheduling headroom of the process.
+	NumCPUGauge bool
+	// GODEBUGActiveGauge additionally reports the runtime.go.metrics.godebug_active
+	// gauge each report: the total number of events of non-default GODEBUG
+	// behaviors since the process started, i.e. the sum of the collected
+	// /godebug/non-default-behavior/*:events metrics. A non-zero value flags
+	// a GODEBUG setting changing the semantics of the runtime or the standard
+	// library, with a single series rather than one per setting.
+	GODEBUGActiveGauge bool
+	// GoroutineSamplePeriod makes the emitter sample the number of
+	// goroutines, runtime.NumGoroutine(), every GoroutineSamplePeriod from a
+	// dedicated goroutine, and submit the samples taken since the last
+	// report as the runtime.go.metrics.sched_goroutines_sampled.goroutines
+	// distribution each report. Unlike the goroutine gauges read at report
+	// time, it captures the peaks and troughs between reports.
+	//
+	// At most 1024 samples are retained between two reports, so periods
+	// shorter than Period/1024 are raised to it. Samples taken once 1024 are
+	// retained, e.g. after lengthening the period with Emitter.SetPeriod, are
+	// dropped. Defaults to 0, disabled.
+	GoroutineSamplePeriod time.Duration
+	// VersionTag attaches a go_runtime_metrics_version tag, see Version, to
+	// all metrics.
+	VersionTag bool
+	// ContainerIDTag attaches a container_id tag to all metrics, with the ID
+	// of the container the process runs in, read from /proc/self/cgroup when
+	// the emitter starts, to correlate metrics with Kubernetes pods. The tag
+	// is left out when the ID can't be found, e.g. outside of a container or
+	// on other operating systems than Linux.
+	ContainerIDTag bool
+	// DistributionSuffix is appended as-is to the name of the distribution
+	// metric reported for histograms, e.g. ".distribution". By default the
+	// distribution shares the base name of the histogram, while the summary
+	// gauges get their own suffixes (.avg, .p99, ...).
+	DistributionSuffix string
+	// DurationUnit is the unit of the metrics measured in seconds by
+	// runtime/metrics, e.g. /gc/pauses:seconds: seconds (the default),
+	// milliseconds or nanoseconds. Their values, including histogram
+	// summaries and distribution samples, are converted, and the .seconds
+	// suffix of their name is replaced by the unit, e.g.
+	// runtime.go.metrics.gc_pauses.milliseconds.p99. CPU time metrics, in
+	// cpu-seconds, are not converted.
+	//
+	// Changing the unit changes the metric names, dashboards built for one
+	// unit won't show metrics reported in another. NewEmitter returns an
+	// error for unsupported units.
+	DurationUnit string
+	// FlushClient makes the emitter call Flush() on the statsd client after
+	// each report, if the client implements it. Metrics are submitted with
+	// explicit timestamps, and buffering inside the client delays them by its
+	// own flush interval. Regardless of this option, the client is flushed
+	// when the emitter is stopped.
+	FlushClient bool
+	// StrictTags makes NewEmitter return an error if any of the base tags
+	// (gogc, gomemlimit, gomaxprocs) can't be computed. By default the
+	// emitter logs a warning and reports metrics without the missing tags.
+	StrictTags bool
+	// LogMappingOnStart makes NewEmitter log, at the info level, the Datadog
+	// name and kind of every runtime metric collected, and the distribution,
+	// summaries and count reported for histograms. It is about a hundred
+	// lines, hence disabled by default.
+	LogMappingOnStart bool
+	// SubmitTimeout bounds the time a report may spend waiting for the statsd
+	// client, e.g. a client blocked on a full unix socket. When set,
+	// submissions are handed over to a worker goroutine, and the ones the
+	// client doesn't accept before the deadline are dropped and logged. The
+	// worker is given the same timeout to drain when the emitter stops.
+	//
+	// By default submissions are synchronous.
+	SubmitTimeout time.Duration
+	// FallbackToLog makes the emitter log the gauges and counts of its
+	// reports through Logger, at the info level, once the statsd client
+	// failed 3 consecutive reports, so that the metrics aren't entirely lost
+	// during an outage, e.g. to diagnose metrics that disappeared. A report
+	// fails if none of its submissions succeeded. With SubmitTimeout, the
+	// submissions are made by a worker goroutine, and only the ones dropped
+	// because of the timeout count as failed. The logging stops, with a
+	// warning, once a report succeeds again. Distribution samples aren't
+	// logged, the summaries of histograms are.
+	FallbackToLog bool
+	// EmitLegacyNames additionally reports the dd-trace-go v1 runtime metrics
+	// (runtime.go.mem_stats.*, runtime.go.num_goroutine, ...) that can be
+	// computed from runtime/metrics, to ease the migration of dashboards and
+	// monitors. See LegacyMetrics for the mapping.
+	EmitLegacyNames bool
+	// DualEmitUntil makes the emitter report the legacy names (see
+	// EmitLegacyNames) alongside the runtime.go.metrics.* names until the
+	// given time, and only the latter afterwards. A warning counting down to
+	// the deadline is logged weekly, to give teams a forcing function to
+	// finish their dashboard migrations. When set, it takes precedence over
+	// EmitLegacyNames.
+	DualEmitUntil time.Time
+	// MetricAliases maps runtime/metrics names to the Datadog name they are
+	// reported under, instead of the name derived from the runtime name. This
+	// keeps series stable when Go renames a metric. It is merged over a
+	// built-in set of aliases for known renames, which only apply on Go
+	// versions that no longer have the metric the renamed one replaces.
+	//
+	// When a runtime metric and an aliased one resolve to the same Datadog
+	// name, only the former is reported.
+	MetricAliases map[string]string
+	// MaxReportDuration bounds the time spent collecting and submitting
+	// metrics in a single report. Once a report exceeds it, the remaining
+	// metrics of that report are skipped and a warning is logged, trading
+	// completeness for keeping reports from stacking up on very constrained
+	// instances. See also ReportStats.Truncated. Disabled by default.
+	MaxReportDuration time.Duration
+	// PercentilesAsTags reports the summaries of histograms (avg, min, max,
+	// median, p95, p99) under a single <histogram>.summary gauge tagged with
+	// stat:<summary>, instead of one gauge name per summary, e.g.
+	// runtime.go.metrics.gc_pauses.seconds.summary with the stat:p99 tag
+	// instead of runtime.go.metrics.gc_pauses.seconds.p99.
+	PercentilesAsTags bool
+	// HistogramStatOverrides maps the runtime/metrics names of histograms,
+	// e.g. /sched/latencies:seconds, to the summaries reported for them,
+	// among avg, min, max, median, p95 and p99, instead of all of them, e.g.
+	// {"/sched/latencies:seconds": {"max"}} to only report the max of the
+	// scheduling latencies. An empty list only reports the distribution.
+	// NewEmitter returns an error for unknown summaries and for metrics that
+	// aren't histograms.
+	HistogramStatOverrides map[string][]string
+	// MinHistogramSamples is the minimum number of values a histogram must
+	// have recorded since the last report for its summary gauges to be
+	// reported, percentiles computed from a handful of values being
+	// meaningless. Distribution samples are always reported. Defaults to 0,
+	// no minimum.
+	MinHistogramSamples int
+	// PercentileInterpolation is how the percentile summaries of histograms
+	// (median, p95 and p99) are estimated within the bucket they fall in.
+	// Defaults to LinearInterpolation. ExponentialInterpolation fits the
+	// exponential buckets of most runtime histograms better, and
+	// NoInterpolation reports the upper boundary of the bucket. NewEmitter
+	// returns an error for unsupported values.
+	PercentileInterpolation PercentileInterpolation
+	// MemStats enables the reporting of a few runtime.MemStats fields that
+	// have no direct runtime/metrics equivalent, as
+	// runtime.go.metrics.memstats.* gauges: mallocs, frees, num_forced_gc,
+	// gc_cpu_fraction and seconds_since_last_gc.
+	//
+	// runtime.ReadMemStats stops the world, so these are read at a reduced
+	// cadence, see MemStatsPeriod.
+	MemStats bool
+	// MemStatsPeriod is the minimum period between two runtime.ReadMemStats
+	// calls when MemStats is set. Reads happen during reports, so the
+	// effective period is rounded up to a multiple of the report period.
+	// Defaults to DefaultMemStatsPeriod.
+	MemStatsPeriod time.Duration
+	// DerivedMetrics enables the runtime.go.metrics.derived.* gauges, which
+	// are computed from the collected runtime/metrics:
+	//
+	//   - seconds_since_gc.seconds: the time since the last report that
+	//     observed an increase of /gc/cycles/total:gc-cycles. It isn't
+	//     reported until a GC cycle has been observed.
+	//
+	//   - gc_thrashing: 1 once the fraction of CPU time used by the GC exceeded
+	//     GCThrashingThreshold for GCThrashingPeriods consecutive periods, 0
+	//     otherwise. A warning is logged when this starts and stops. Pausing
+	//     reports, see Emitter.SetClient, resets this state.
+	//
+	// As well as:
+	//
+	//   - runtime.go.metrics.gc_frequency: the number of GC cycles per second
+	//     over the last reporting period, from /gc/cycles/total:gc-cycles.
+	//
+	//   - runtime.go.metrics.gc_heap_utilization: the ratio of
+	//     /gc/heap/live:bytes to /gc/heap/goal:bytes, i.e. how close the heap
+	//     is to triggering the next GC cycle. It is 0 while the goal is 0.
+	//
+	//   - runtime.go.metrics.gc_cpu_fraction: the fraction of the CPU time
+	//     used by the GC over the last reporting period, from the deltas of
+	//     /cpu/classes/gc/total:cpu-seconds and
+	//     /cpu/classes/total:cpu-seconds, like runtime.MemStats.GCCPUFraction
+	//     but over the period rather than since the process started. It is 0
+	//     if no CPU time was used.
+	//
+	//   - runtime.go.metrics.heap_fragmentation: the fraction of the heap
+	//     that is free but not released to the OS, i.e.
+	//     /memory/classes/heap/free:bytes / (free + released + objects),
+	//     from /memory/classes/heap/{free,released,objects}:bytes. It is 0
+	//     while the heap is empty.
+	//
+	// Derived metrics whose inputs aren't collected, e.g. on older Go
+	// versions, aren't reported.
+	DerivedMetrics bool
+	// GCThrashingThreshold is the GC CPU fraction above which a period counts
+	// towards runtime.go.metrics.derived.gc_thrashing. Defaults to
+	// DefaultGCThrashingThreshold.
+	GCThrashingThreshold float64
+	// GCThrashingPeriods is the number of consecutive periods above
+	// GCThrashingThreshold after which the GC is considered to be thrashing.
+	// Defaults to DefaultGCThrashingPeriods.
+	GCThrashingPeriods int
+	// SubsystemPrefixes maps the subsystem of runtime metrics, the first
+	// segment of their path such as "gc" or "sched", to the prefix replacing
+	// "runtime.go.metrics." in their Datadog name, e.g. "runtime.gc.". Metrics
+	// of unmapped subsystems, aliased metrics and metrics that aren't read
+	// from runtime/metrics keep the default prefix. NewEmitter returns an
+	// error for unknown subsystems and invalid prefixes.
+	SubsystemPrefixes map[string]string
+	// TaggedFamilies lists the metric families reported under a single
+	// Datadog name with a tag discriminating their members, rather than
+	// under one name per member:
+	//
+	//   - goroutines: /sched/goroutines/* as
+	//     runtime.go.metrics.sched_goroutines_by_state.goroutines with a
+	//     state tag, e.g. state:runnable.
+	//   - memory_classes: /memory/classes/* as
+	//     runtime.go.metrics.memory_classes_by_class.bytes with a class tag,
+	//     e.g. class:heap/free.
+	//   - cpu_classes: /cpu/classes/* as
+	//     runtime.go.metrics.cpu_classes_by_class.cpu_seconds with a class
+	//     tag, e.g. class:gc/mark/assist.
+	//
+	// Totals keep being reported under their own name, so that the members
+	// of a family add up to them. NewEmitter returns an error for unknown
+	// families.
+	TaggedFamilies []string
+	// RequireMappedUnit only reports the runtime metrics whose unit has a
+	// standard Datadog unit, e.g. bytes or seconds, leaving out the ones in
+	// goroutines, cpu-seconds, gc-cycles, calls, etc. Derived metrics computed
+	// from the metrics left out aren't reported either.
+	RequireMappedUnit bool
+	// NameSeparator replaces both the separators of the path segments of
+	// runtime metrics and the separator of their unit in their Datadog
+	// name. It must be "." or "_", e.g. "." reports /gc/heap/live:bytes as
+	// runtime.go.metrics.gc.heap.live.bytes. By default path segments are
+	// joined with "_" and the unit with ".", e.g.
+	// runtime.go.metrics.gc_heap_live.bytes. Aliased metrics, see
+	// MetricAliases, keep their name. NewEmitter returns an error for other
+	// separators.
+	NameSeparator string
+	// HistogramCounts additionally reports the number of values recorded by
+	// each cumulative histogram since the last report as a <histogram>.count
+	// count, e.g. runtime.go.metrics.gc_pauses.seconds.count for the number
+	// of GC pauses per period, which is exact unlike counts derived from the
+	// distribution.
+	HistogramCounts bool
+	// GaugeAsDelta lists the runtime/metrics names of scalar metrics
+	// reported as the difference between their value and the one of the
+	// previous report, rather than as their value, for the few metrics that
+	// are conceptually cumulative but exposed as gauges. They keep their
+	// Datadog name. Like the other delta-based series, they aren't reported
+	// during WarmupPeriods. NewEmitter returns an error for metrics that don't
+	// exist or aren't scalars.
+	GaugeAsDelta []string
+	// UnitScale maps runtime units to a factor their values are multiplied by,
+	// e.g. {"bytes": 1.0 / (1 << 20)} to report byte metrics in MiB. The unit
+	// suffix of the scaled metrics is renamed accordingly, e.g.
+	// runtime.go.metrics.gc_heap_live.mebibytes. Only byte metrics can be
+	// scaled, by 1.0/(1<<10), 1.0/(1<<20), 1.0/(1<<30), 1e-3, 1e-6 or 1e-9.
+	// Durations are converted with DurationUnit. NewEmitter returns an error
+	// for other units and scales.
+	//
+	// Deltas and derived metrics are computed from the unscaled values.
+	UnitScale map[string]float64
+
+	// clock is realClock, except in tests.
+	clock clock
+}
+
+// ReportStats describes a report, see Options.OnReport.
+type ReportStats struct {
+	// Timestamp is the timestamp the metrics were submitted with.
+	Timestamp time.Time
+	// Duration is the time it took to collect and submit the metrics.
+	Duration time.Duration
+	// Tags are the tags attached to the runtime metrics of the report. The
+	// slice belongs to the callback and may be modified.
+	Tags []string
+	// Truncated is the number of runtime metrics that were not submitted
+	// because the report exceeded Options.MaxReportDuration.
+	Truncated int
+}
+
+// Emitter periodically reports runtime/metrics to a statsd client.
+type Emitter struct {
+	// rms is created by newStore on the first call of store, usually by
+	// the reporting goroutine, so that NewEmitter returns quickly.
+	rms      *runtimeMetricStore
+	newStore func() *runtimeMetricStore
+	initOnce sync.Once
+	// initErr is the error of the initialization of rms, see LastError.
+	initErr  error
+	stop     chan struct{}
+	stopOnce sync.Once
+	done     chan struct{}
+	// periods holds the last period set by SetPeriod until the reporting
+	// goroutine applies it, so that SetPeriod never waits for it.
+	periods chan time.Duration
+	period  time.Duration
+	delay   time.Duration
+	warmUp  time.Duration
+	clock   clock
+	logger  *slog.Logger
+	// ownedClient is the client created by NewEmitterWithStatsdAddr, closed
+	// by Stop.
+	ownedClient io.Closer
+	// stack is the stack trace of the NewEmitter call, logged when another
+	// emitter is started while this one is running.
+	stack string
+}
+
+// NOTE: The Start function below is intentionally minimal for now. We probably want to think about
 // this API a bit more before we publish it in dd-trace-go. I.e. do we want to make the
-// pollFrequency configurable (higher resolution at the cost of higher overhead on the agent and
-// statsd library)? Do we want to support multiple instances? We probably also want a (flushing?)
-// stop method.
+// period configurable (higher resolution at the cost of higher overhead on the agent and
+// statsd library)? Do we want to support multiple instances?
 
 // Start starts reporting runtime/metrics to the given statsd client.
 func Start(statsd partialStatsdClientInterface, logger *slog.Logger) error {
-	mu.Lock()
-	defer mu.Unlock()
+	_, err := NewEmitter(statsd, &Options{Logger: logger})
+	return err
+}
 
-	if enabled {
-		// We could support multiple instances, but the use cases for it are not
-		// clear, so for now let's consider this to be a misconfiguration.
-		return errors.New("runtimemetrics has already been started")
+// NewEmitter creates a new runtime metrics emitter and starts it.
+//
+// Several emitters may run at the same time, e.g. to report to different
+// backends, but each of them reports every series, which doubles gauges and
+// counts if they share a backend. A warning with the stack traces of both
+// NewEmitter calls is logged when an emitter starts while another one is
+// running, see also ActiveEmitters.
+//
+// NewEmitter validates opts and returns quickly: the metrics are set up, and
+// the baselines of cumulative metrics read, by the reporting goroutine. The
+// errors of that initialization are logged and returned by LastError.
+//
+// If statsd is a datadog-go statsd.NoOpClient, or implements an
+// IsNoOp() bool method returning true, the emitter doesn't collect any metrics
+// until it is given another client with SetClient.
+func NewEmitter(statsd partialStatsdClientInterface, opts *Options) (*Emitter, error) {
+	// Work on a copy so defaults don't leak into the caller's options.
+	var o Options
+	if opts != nil {
+		o = *opts
+	}
+	if o.Logger == nil {
+		o.Logger = slog.Default()
+	}
+	if o.Period <= 0 {
+		o.Period = DefaultPeriod
 	}
+	o.Period = checkPeriod(o.Period, o.Logger)
+	o.GoroutineSamplePeriod = checkGoroutineSamplePeriod(o.GoroutineSamplePeriod, o.Period, o.Logger)
+	if o.clock == nil {
+		o.clock = realClock{}
+	}
+	opts = &o
+
+	mu.Lock()
+	defer mu.Unlock()
 
 	descs := metrics.All()
-	rms := newRuntimeMetricStore(descs, statsd, logger)
+	if err := validateOptions(opts, descs); err != nil {
+		return nil, err
+	}
+	if opts.StrictTags {
+		if err := validateBaseTags(getBaseTags()); err != nil {
+			return nil, err
+		}
+	}
+	e := &Emitter{
+		stop:    make(chan struct{}),
+		periods: make(chan time.Duration, 1),
+		done:    make(chan struct{}),
+		period:  opts.Period,
+		delay:   opts.InitialDelay,
+		warmUp:  opts.WarmUp,
+		clock:   opts.clock,
+		logger:  opts.Logger,
+		stack:   string(debug.Stack()),
+	}
+	e.newStore = func() *runtimeMetricStore {
+		rms := newRuntimeMetricStore(descs, statsd, opts)
+		if err := validateBaseTags(rms.baseTags); err != nil {
+			e.initErr = err
+			opts.Logger.Warn("runtimemetrics: reporting metrics with missing base tags", slog.Attr{Key: "error", Value: slog.StringValue(err.Error())})
+		}
+		if opts.LogMappingOnStart {
+			opts.Logger.Info("runtimemetrics: metric mapping", slog.Attr{Key: "mapping", Value: slog.StringValue(rms.mapping(descs))})
+		}
+		return rms
+	}
+	if len(activeEmitters) > 0 {
+		// This is most likely a misconfiguration, e.g. a library and the
+		// application both starting an emitter, but it isn't always one.
+		opts.Logger.Warn("runtimemetrics: another emitter is already running, the runtime metrics will be reported more than once",
+			slog.Attr{Key: "active_emitters", Value: slog.IntValue(len(activeEmitters))},
+			slog.Attr{Key: "stack", Value: slog.StringValue(e.stack)},
+			slog.Attr{Key: "running_emitter_stack", Value: slog.StringValue(activeEmitters[0].stack)},
+		)
+	}
 	// TODO: Go services experiencing high scheduling latency might see a
 	// large variance for the period in between rms.report calls. This might
 	// cause spikes in cumulative metric reporting. Should we try to correct
@@ -63,35 +525,533 @@ func Start(statsd partialStatsdClientInterface, logger *slog.Logger) error {
 	//
 	// [1] https://github.com/golang/go/blob/go1.21.3/src/runtime/mstats.go#L939
 	// [2] https://github.com/golang/go/issues/59749
-	go func() {
-		for range time.Tick(pollFrequency) {
+	go e.run()
+	activeEmitters = append(activeEmitters, e)
+	return e, nil
+}
+
+// ActiveEmitters returns the number of running emitters, i.e. started and not
+// stopped yet. More than one means that the runtime metrics are reported
+// more than once, see NewEmitter.
+func ActiveEmitters() int {
+	mu.Lock()
+	defer mu.Unlock()
+	return len(activeEmitters)
+}
+
+// MinPeriod is the shortest reporting period, see Options.Period. Shorter
+// periods would burn CPU time collecting metrics and flood the agent.
+const MinPeriod = 100 * time.Millisecond
+
+// maxRecommendedPeriod is the period above which checkPeriod warns.
+const maxRecommendedPeriod = 10 * time.Minute
+
+// checkPeriod returns the period to use for the requested period d: d
+// raised to MinPeriod, with a warning. It also warns about periods longer
+// than maxRecommendedPeriod, whose metrics are submitted with timestamps
+// that may be older than the intake window of the backend, and dropped.
+func checkPeriod(d time.Duration, logger *slog.Logger) time.Duration {
+	if d < MinPeriod {
+		logger.Warn("runtimemetrics: period is too short, using the minimum period instead",
+			slog.Attr{Key: "period", Value: slog.DurationValue(d)},
+			slog.Attr{Key: "min_period", Value: slog.DurationValue(MinPeriod)},
+		)
+		return MinPeriod
+	}
+	if d > maxRecommendedPeriod {
+		logger.Warn("runtimemetrics: period is very long, metrics with old timestamps may be dropped by the backend",
+			slog.Attr{Key: "period", Value: slog.DurationValue(d)},
+			slog.Attr{Key: "max_recommended_period", Value: slog.DurationValue(maxRecommendedPeriod)},
+		)
+	}
+	return d
+}
+
+// validateOptions returns an error if opts are invalid for the given runtime
+// metrics.
+func validateOptions(opts *Options, descs []metrics.Description) error {
+	if err := validateSubsystemPrefixes(opts.SubsystemPrefixes, descs); err != nil {
+		return err
+	}
+	if err := validateTaggedFamilies(opts.TaggedFamilies); err != nil {
+		return err
+	}
+	if err := validateNameSeparator(opts.NameSeparator); err != nil {
+		return err
+	}
+	if err := validateUnitScale(opts.UnitScale); err != nil {
+		return err
+	}
+	if err := validatePercentileInterpolation(opts.PercentileInterpolation); err != nil {
+		return err
+	}
+	if err := validateHistogramStatOverrides(opts.HistogramStatOverrides, descs); err != nil {
+		return err
+	}
+	if err := validateGaugeAsDelta(opts.GaugeAsDelta, descs); err != nil {
+		return err
+	}
+	return validateDurationUnit(opts.DurationUnit)
+}
+
+// store returns the store of the emitter, creating it on the first call.
+func (e *Emitter) store() *runtimeMetricStore {
+	e.initOnce.Do(func() {
+		if e.rms == nil {
+			e.rms = e.newStore()
+		}
+	})
+	return e.rms
+}
+
+// LastError returns the error the emitter ran into while initializing on the
+// reporting goroutine, e.g. base tags that couldn't be computed, or nil. Such
+// errors are logged as well. It waits for the initialization.
+func (e *Emitter) LastError() error {
+	e.store()
+	return e.initErr
+}
+
+func (e *Emitter) run() {
+	defer e.exit()
+	rms := e.store()
+	if g := rms.goroutines; g != nil {
+		stop := make(chan struct{})
+		done := make(chan struct{})
+		go func() {
+			defer close(done)
+			g.run(e.clock, stop)
+		}()
+		// Stop sampling before exit closes the store.
+		defer func() {
+			close(stop)
+			<-done
+		}()
+	}
+	if e.delay > 0 {
+		if !e.sleep(e.delay) {
+			return
+		}
+		// Don't report what happened during the delay.
+		rms.update()
+	}
+	if e.warmUp > 0 {
+		if !e.sleep(e.warmUp) {
+			return
+		}
+		rms.report()
+	}
+	ticker := e.clock.NewTicker(e.period)
+	defer func() { ticker.Stop() }()
+	for {
+		select {
+		case <-e.stop:
+			return
+		case d := <-e.periods:
+			e.period = d
+			ticker.Stop()
+			ticker = e.clock.NewTicker(d)
+		case <-ticker.C():
 			rms.report()
+			if rms.closedReports > maxClosedClientReports {
+				e.logger.Warn("runtimemetrics: statsd client has been closed for too long, the emitter stopped itself",
+					slog.Attr{Key: "skipped_reports", Value: slog.IntValue(rms.closedReports)},
+				)
+				return
+			}
+		}
+	}
+}
+
+// sleep waits for d, applying the periods set meanwhile. It returns false if
+// the emitter was stopped.
+func (e *Emitter) sleep(d time.Duration) bool {
+	timer := e.clock.NewTicker(d)
+	defer timer.Stop()
+	for {
+		select {
+		case <-e.stop:
+			return false
+		case d := <-e.periods:
+			e.period = d
+		case <-timer.C():
+			return true
 		}
-	}()
-	enabled = true
+	}
+}
+
+// exit is called when the reporting goroutine exits, after which the emitter
+// is no longer active.
+func (e *Emitter) exit() {
+	e.store().close()
+	mu.Lock()
+	activeEmitters = slices.DeleteFunc(activeEmitters, func(a *Emitter) bool { return a == e })
+	mu.Unlock()
+	close(e.done)
+}
+
+// SetClient replaces the statsd client used by the emitter. The new client is
+// used starting with the next report, a report in progress always completes
+// with the client it started with.
+//
+// Setting a nil client pauses submissions. Metrics keep being collected while
+// paused, so that cumulative metrics report correct deltas once a client is
+// set again.
+func (e *Emitter) SetClient(c partialStatsdClientInterface) {
+	e.store().setClient(c)
+}
+
+// History returns the last reports retained with Options.HistorySize, oldest
+// first, or nil if it isn't set. It is safe to call concurrently with
+// reports.
+func (e *Emitter) History() []Report {
+	rms := e.store()
+	if rms.history == nil {
+		return nil
+	}
+	return rms.history.get()
+}
+
+// MemoryFootprint returns an estimate of the memory in bytes the emitter
+// retains between reports for the values of the metrics, as of the last
+// report: the current and previous bucket counts of histograms, their bucket
+// boundaries, which are shared, the buffers reused by reports, and the
+// goroutine samples of Options.GoroutineSamplePeriod. It doesn't include the
+// memory of the statsd client. It is safe to call concurrently with reports.
+func (e *Emitter) MemoryFootprint() int {
+	return int(e.store().footprint.Load())
+}
+
+// SetStatsdClient is SetClient for callers swapping clients, e.g. to
+// reconnect, that don't mean to pause submissions: it returns an error for a
+// nil client, including a nil pointer such as a nil *statsd.Client, and keeps
+// the current one.
+func (e *Emitter) SetStatsdClient(c StatsdClient) error {
+	if isNilClient(c) {
+		return errors.New("runtimemetrics: the statsd client must not be nil")
+	}
+	e.SetClient(c)
 	return nil
 }
 
+// isNilClient returns true if c is nil, or an interface holding a nil
+// pointer, map, slice, channel or function.
+func isNilClient(c StatsdClient) bool {
+	if c == nil {
+		return true
+	}
+	switch v := reflect.ValueOf(c); v.Kind() {
+	case reflect.Pointer, reflect.Map, reflect.Slice, reflect.Chan, reflect.Func:
+		return v.IsNil()
+	}
+	return false
+}
+
+// EnableMetric resumes the reporting of a runtime metric disabled by
+// DisableMetric, given its runtime/metrics name, e.g. /gc/pauses:seconds. It
+// is safe to call concurrently with reports.
+func (e *Emitter) EnableMetric(name string) {
+	e.setMetricEnabled(name, true)
+}
+
+// DisableMetric stops reporting a runtime metric, given its runtime/metrics
+// name, e.g. /gc/pauses:seconds, until EnableMetric is called. The metric
+// keeps being read while disabled, so that cumulative metrics report the
+// deltas since the previous report once enabled again, rather than since
+// they were disabled. It is safe to call concurrently with reports.
+func (e *Emitter) DisableMetric(name string) {
+	e.setMetricEnabled(name, false)
+}
+
+func (e *Emitter) setMetricEnabled(name string, enabled bool) {
+	rms := e.store()
+	rm, ok := rms.metrics[name]
+	if !ok {
+		rms.logger.Warn("runtimemetrics: can't enable or disable a metric that isn't reported", slog.Attr{Key: "metric_name", Value: slog.StringValue(name)})
+		return
+	}
+	rm.disabled.Store(!enabled)
+}
+
+// SetPeriod changes the period of the reports, e.g. to report more often
+// during an incident. The next report happens d after the change, and
+// cumulative metrics report the deltas since the previous report. Like
+// Options.Period, d is raised to MinPeriod if shorter. It returns an error if
+// d isn't positive or if the emitter is stopped.
+//
+// SetPeriod doesn't wait for the reporting goroutine, so it can be called
+// from the report hooks, e.g. Options.OnReport. When called several times
+// before the reporting goroutine applies the period, the last one wins.
+func (e *Emitter) SetPeriod(d time.Duration) error {
+	if d <= 0 {
+		return fmt.Errorf("runtimemetrics: invalid period %s, it must be positive", d)
+	}
+	d = checkPeriod(d, e.logger)
+	select {
+	case <-e.done:
+		return errors.New("runtimemetrics: the emitter is stopped")
+	default:
+	}
+	for {
+		select {
+		case e.periods <- d:
+			return nil
+		default:
+			// Replace the period the reporting goroutine hasn't applied yet.
+			select {
+			case <-e.periods:
+			default:
+			}
+		}
+	}
+}
+
+// Stop stops the emitter. It is idempotent and blocks until the reporting
+// goroutine has exited, after which ActiveEmitters no longer counts it.
+func (e *Emitter) Stop() {
+	if e == nil {
+		return
+	}
+	e.stopOnce.Do(func() {
+		close(e.stop)
+		<-e.done
+		rms := e.store()
+		rms.flush(rms.client.Load().statsd)
+		if e.ownedClient != nil {
+			e.ownedClient.Close()
+		}
+	})
+	<-e.done
+}
+
 type runtimeMetric struct {
 	ddMetricName string
 	cumulative   bool
+	// disabled is set by Emitter.DisableMetric. Disabled metrics keep being
+	// read, so that their baseline stays current.
+	disabled atomic.Bool
+	// scale converts the values to Options.DurationUnit or
+	// Options.UnitScale.
+	scale float64
+	// tags are the tags of scalar metrics: the base tags, plus the member
+	// tag of metrics reported as part of a tagged family.
+	tags []string
+	// delta is set for the scalar metrics reported as deltas, see
+	// Options.GaugeAsDelta.
+	delta bool
+
+	// ddDistributionName, summaryNames and summaryStats are only used for
+	// histograms. summaryStats are the indexes in histogramStatNames of the
+	// summaries reported, see Options.HistogramStatOverrides.
+	ddDistributionName string
+	summaryNames       [len(histogramStatNames)]string
+	summaryStats       []int
+	// countName is the name of the count of values recorded by cumulative
+	// histograms, see Options.HistogramCounts. It is empty when disabled.
+	countName string
+
+	// previousValue is only set to its kind for histograms, whose previous
+	// bucket counts are retained in previousCounts rather than as a whole
+	// histogram, see previousHistogram.
+	currentValue      value
+	previousValue     value
+	previousCounts    []uint64
+	timestamp         time.Time
+	previousTimestamp time.Time
+	// buckets are the bucket boundaries of histograms. They don't change
+	// during the life of the process, so all the values of a histogram
+	// share them.
+	buckets []float64
+}
+
+// scalarDelta returns the difference between the current and previous
+// values of a scalar metric, and false if there is no previous value.
+func (rm *runtimeMetric) scalarDelta() (float64, bool) {
+	switch {
+	case rm.currentValue.Kind() == metrics.KindUint64 && rm.previousValue.Kind() == metrics.KindUint64:
+		return float64(rm.currentValue.Uint64()) - float64(rm.previousValue.Uint64()), true
+	case rm.currentValue.Kind() == metrics.KindFloat64 && rm.previousValue.Kind() == metrics.KindFloat64:
+		return rm.currentValue.Float64() - rm.previousValue.Float64(), true
+	}
+	return 0, false
+}
+
+// previousHistogram returns the previous value of a histogram, or an empty
+// histogram if there is none.
+func (rm *runtimeMetric) previousHistogram() *metrics.Float64Histogram {
+	if rm.previousValue.Kind() != metrics.KindFloat64Histogram {
+		return &metrics.Float64Histogram{}
+	}
+	return &metrics.Float64Histogram{Counts: rm.previousCounts, Buckets: rm.buckets}
+}
 
-	currentValue  metrics.Value
-	previousValue metrics.Value
-	timestamp     time.Time
+// retainPrevious makes the current value of rm the previous one. It must be
+// called before the next value is read, which may overwrite the histogram of
+// the current one, see runtimeSampler.
+func (rm *runtimeMetric) retainPrevious() {
+	if rm.currentValue.Kind() == metrics.KindFloat64Histogram {
+		// Only retain the counts, in the buffer of the previous ones.
+		rm.previousCounts = append(rm.previousCounts[:0], rm.currentValue.Float64Histogram().Counts...)
+		rm.previousValue = value{kind: metrics.KindFloat64Histogram}
+	} else {
+		rm.previousCounts = rm.previousCounts[:0]
+		rm.previousValue = rm.currentValue
+	}
+	rm.previousTimestamp = rm.timestamp
+}
+
+// setValue makes v the current value of rm, see retainPrevious.
+func (rm *runtimeMetric) setValue(v value, timestamp time.Time) {
+	if h := v.histogram; h != nil && !sameSlice(h.Buckets, rm.buckets) {
+		// The runtime shares the buckets of all the reads of a histogram,
+		// other samplers may not.
+		if !slices.Equal(h.Buckets, rm.buckets) {
+			rm.buckets = h.Buckets
+		}
+		v = histogramValue(&metrics.Float64Histogram{Counts: h.Counts, Buckets: rm.buckets})
+	}
+	rm.currentValue = v
+	rm.timestamp = timestamp
+}
+
+// sameSlice returns true if a and b are the same slice, rather than slices
+// with the same elements.
+func sameSlice(a, b []float64) bool {
+	return len(a) == len(b) && (len(a) == 0 || &a[0] == &b[0])
+}
+
+// memoryFootprint returns the size in bytes of the histogram values retained
+// by the store between reports, including the buffers reused by reports. The
+// bucket boundaries shared by several histograms are only counted once. The
+// scalar values are negligible.
+func (rms *runtimeMetricStore) memoryFootprint() int {
+	const size = 8 // the size of a uint64 count or a float64 boundary
+	footprint := size * (cap(rms.delta.Counts) + cap(rms.scaled.Buckets) + cap(rms.distValues))
+	footprint += 16 * cap(rms.distSamples)
+	// The goroutine sampler retains a buffer of the same size.
+	footprint += 2 * size * cap(rms.goroutineSamples)
+	// There are only a handful of distinct buckets.
+	buckets := make([]*float64, 0, 8)
+	for _, rm := range rms.metrics {
+		footprint += size * (cap(rm.previousCounts) + cap(rm.currentValue.Float64Histogram().Counts))
+		if len(rm.buckets) > 0 && !slices.Contains(buckets, &rm.buckets[0]) {
+			buckets = append(buckets, &rm.buckets[0])
+			footprint += size * cap(rm.buckets)
+		}
+	}
+	return footprint
 }
 
 // the map key is the name of the metric in runtime/metrics
 type runtimeMetricStore struct {
-	metrics  map[string]*runtimeMetric
-	statsd   partialStatsdClientInterface
+	metrics map[string]*runtimeMetric
+	// names are the keys of metrics, sorted so that reports submit the
+	// metrics in a stable order.
+	names    []string
+	client   atomic.Pointer[statsdClientRef]
 	logger   *slog.Logger
 	baseTags []string
+	// selfTags are the tags of the self-metrics, see
+	// Options.SelfMetricTags.
+	selfTags []string
+	// summaryTags are the tags of each histogram summary, see
+	// Options.PercentilesAsTags.
+	summaryTags [len(histogramStatNames)][]string
+
+	// closedReports is the number of consecutive reports that were skipped
+	// because the statsd client was closed.
+	closedReports int
+
+	// warmupReports is the number of reports left before the delta-based
+	// series are submitted, see Options.WarmupPeriods.
+	warmupReports int
+
+	// lastTimestamp is the timestamp of the last update. clockJumped is set
+	// if it is before the one of the update before, in wall clock time, and
+	// clockJumpLogged once that has been logged.
+	lastTimestamp   time.Time
+	clockJumped     bool
+	clockJumpLogged bool
+
+	// footprint is the memoryFootprint of the last update, see
+	// Emitter.MemoryFootprint.
+	footprint atomic.Int64
+
+	// The buffers below are reused by reports, so that they don't allocate
+	// once the first report is done. samples are read by update. delta
+	// holds the delta of the cumulative histogram being reported, or of the
+	// input of a legacy metric, and scaled the histogram in
+	// Options.DurationUnit or Options.UnitScale.
+	// distSamples and distValues are its distribution samples.
+	samples     []sample
+	delta       metrics.Float64Histogram
+	scaled      metrics.Float64Histogram
+	distSamples []distributionSample
+	distValues  []float64
+
+	// flushEachReport is set by Options.FlushClient.
+	flushEachReport bool
+
+	// async is nil unless Options.SubmitTimeout is set.
+	async *asyncSubmitter
+
+	onReport          func(ReportStats)
+	beforeReport      func(context.Context) context.Context
+	afterReport       func(context.Context, ReportStats)
+	maxReportDuration time.Duration
+	// history is nil unless Options.HistorySize is positive.
+	history *reportHistory
+	// fallback is nil unless Options.FallbackToLog is set.
+	fallback *fallbackLogger
+	// minHistogramSamples is set by Options.MinHistogramSamples.
+	minHistogramSamples int
+	// percentileInterpolation is set by Options.PercentileInterpolation.
+	percentileInterpolation PercentileInterpolation
+
+	// legacy is nil unless Options.EmitLegacyNames or Options.DualEmitUntil
+	// is set.
+	legacy        []resolvedLegacyMetric
+	dualEmitUntil time.Time
+	// lastDualEmitLog is the time of the last countdown warning.
+	lastDualEmitLog time.Time
+
+	// derived is nil unless Options.DerivedMetrics is set.
+	derived *derivedMetrics
+
+	// memStats is nil unless Options.MemStats is set.
+	memStats *memStatsCollector
+
+	// now is time.Now, except in tests.
+	now func() time.Time
+	// sampler is runtimeSampler, except in tests.
+	sampler sampler
+
+	// buildInfoTags is nil unless Options.BuildInfo is set and the build
+	// info could be read.
+	buildInfoTags []string
+
+	// gomaxprocsGauge is set by Options.GOMAXPROCSGauge.
+	gomaxprocsGauge bool
+	// numCPUGauge is set by Options.NumCPUGauge.
+	numCPUGauge bool
+	// godebug is nil unless Options.GODEBUGActiveGauge is set, in which case
+	// it holds the collected godebug metrics, possibly none.
+	godebug []*runtimeMetric
+
+	// goroutines is nil unless Options.GoroutineSamplePeriod is set. Its
+	// samples are drained into goroutineSamples by update.
+	goroutines       *goroutineSampler
+	goroutineSamples []float64
 }
 
-// partialStatsdClientInterface is the subset of statsd.ClientInterface that is
-// used by this package.
-type partialStatsdClientInterface interface {
+// partialStatsdClientInterface is the name of StatsdClient within this
+// package.
+type partialStatsdClientInterface = StatsdClient
+
+// StatsdClient is the statsd client the emitter submits to, the subset of
+// statsd.ClientInterface of datadog-go used by this package. UDPClient,
+// ChannelSink and SinkClient implement it too.
+type StatsdClient interface {
 	// Rate is used in the datadog-go statsd library to sample to values sent,
 	// we should always submit a rate >=1 to ensure our submissions are not sampled.
 	// The rate is forwarded to the agent but then discarded for gauge metrics.
@@ -100,69 +1060,411 @@ type partialStatsdClientInterface interface {
 	DistributionSamples(name string, values []float64, tags []string, rate float64) error
 }
 
-func newRuntimeMetricStore(descs []metrics.Description, statsdClient partialStatsdClientInterface, logger *slog.Logger) runtimeMetricStore {
-	rms := runtimeMetricStore{
+// closableStatsdClient is implemented by statsd clients that can tell whether
+// they have been closed, e.g. the datadog-go statsd client.
+type closableStatsdClient interface {
+	IsClosed() bool
+}
+
+// flushableStatsdClient is implemented by statsd clients that buffer
+// submissions, e.g. the datadog-go statsd client.
+type flushableStatsdClient interface {
+	Flush() error
+}
+
+// histogramStatsdClient is implemented by statsd client replacements that
+// take the runtime histograms, in addition to their distribution samples and
+// summaries, see SinkClient.
+type histogramStatsdClient interface {
+	HistogramWithTimestamp(name string, h *metrics.Float64Histogram, tags []string, timestamp time.Time) error
+}
+
+// noOpStatsdClient can be implemented by statsd clients that discard all
+// submissions. When IsNoOp returns true, reports skip collecting metrics
+// altogether, which makes the emitter practically free.
+//
+// The datadog-go statsd.NoOpClient and statsd.NoOpClientDirect types are
+// detected without implementing this interface, see isNoOpClient.
+type noOpStatsdClient interface {
+	IsNoOp() bool
+}
+
+// isNoOpClient returns true if the given client is known to discard all
+// submissions.
+func isNoOpClient(c partialStatsdClientInterface) bool {
+	if c, ok := c.(noOpStatsdClient); ok {
+		return c.IsNoOp()
+	}
+	// Avoid depending on datadog-go just for an identity check.
+	t := reflect.TypeOf(c)
+	if t == nil {
+		return false
+	}
+	if t.Kind() == reflect.Pointer {
+		t = t.Elem()
+	}
+	return strings.HasPrefix(t.PkgPath(), "github.com/DataDog/datadog-go/") &&
+		(t.Name() == "NoOpClient" || t.Name() == "NoOpClientDirect")
+}
+
+// maxClosedClientReports is the number of consecutive reports skipped because
+// of a closed statsd client after which the emitter stops itself.
+const maxClosedClientReports = 6
+
+// statsdClientRef allows to store a possibly nil
+// partialStatsdClientInterface in an atomic.Pointer.
+type statsdClientRef struct {
+	statsd partialStatsdClientInterface
+	noop   bool
+}
+
+func newRuntimeMetricStore(descs []metrics.Description, statsdClient partialStatsdClientInterface, opts *Options) *runtimeMetricStore {
+	return newRuntimeMetricStoreWithSampler(descs, &runtimeSampler{}, statsdClient, opts)
+}
+
+// newRuntimeMetricStoreWithSampler is newRuntimeMetricStore, reading the
+// metrics from the given sampler.
+func newRuntimeMetricStoreWithSampler(descs []metrics.Description, sampler sampler, statsdClient partialStatsdClientInterface, opts *Options) *runtimeMetricStore {
+	rms := &runtimeMetricStore{
 		metrics:  map[string]*runtimeMetric{},
-		statsd:   statsdClient,
-		logger:   logger,
+		logger:   opts.Logger,
 		baseTags: getBaseTags(),
-	}
 
-	for _, d := range descs {
-		cumulative := d.Cumulative
+		flushEachReport: opts.FlushClient,
+		onReport:        opts.OnReport,
+		beforeReport:    opts.BeforeReport,
+		afterReport:     opts.AfterReport,
+
+		maxReportDuration:       opts.MaxReportDuration,
+		minHistogramSamples:     opts.MinHistogramSamples,
+		percentileInterpolation: opts.PercentileInterpolation,
+		dualEmitUntil:           opts.DualEmitUntil,
+		gomaxprocsGauge:         opts.GOMAXPROCSGauge,
+		numCPUGauge:             opts.NumCPUGauge,
+		warmupReports:           opts.WarmupPeriods,
+		now:                     time.Now,
+		sampler:                 sampler,
+	}
+	if opts.clock != nil {
+		rms.now = opts.clock.Now
+	}
+	rms.setClient(statsdClient)
+	if opts.SubmitTimeout > 0 {
+		rms.async = newAsyncSubmitter(opts.SubmitTimeout)
+	}
+	if opts.HistorySize > 0 {
+		rms.history = newReportHistory(opts.HistorySize)
+	}
+	if opts.VersionTag {
+		rms.baseTags = append(rms.baseTags, "go_runtime_metrics_version:"+Version())
+	}
+	if opts.ContainerIDTag {
+		if id := readContainerID(cgroupPath); id != "" {
+			rms.baseTags = append(rms.baseTags, "container_id:"+id)
+		}
+	}
+	rms.baseTags = append(rms.baseTags, opts.Tags...)
+	rms.selfTags = append(slices.Clip(rms.baseTags), opts.SelfMetricTags...)
+	for i, stat := range histogramStatNames {
+		if opts.PercentilesAsTags {
+			rms.summaryTags[i] = append(slices.Clip(rms.baseTags), "stat:"+stat)
+		} else {
+			rms.summaryTags[i] = rms.baseTags
+		}
+	}
 
-		// /sched/latencies:seconds is incorrectly set as non-cumulative,
-		// fixed by https://go-review.googlesource.com/c/go/+/486755
-		// TODO: Use a build tag to apply this logic to Go versions < 1.20.
-		if d.Name == "/sched/latencies:seconds" {
-			cumulative = true
+	if opts.BuildInfo {
+		if bi, ok := readBuildInfo(); ok {
+			rms.buildInfoTags = append(buildInfoTags(bi), rms.baseTags...)
+		} else {
+			rms.logger.Warn("runtimemetrics: build info is not available, not reporting runtime.go.metrics.build_info")
 		}
+	}
 
-		ddMetricName, err := datadogMetricName(d.Name)
-		if err != nil {
-			rms.logger.Warn("runtimemetrics: not reporting one of the runtime metrics", slog.Attr{Key: "error", Value: slog.StringValue(err.Error())})
-			continue
+	aliases := metricAliases(opts.MetricAliases, descs)
+	// ddNames maps Datadog names to the runtime metric reported under them.
+	ddNames := map[string]string{}
+	// Metrics without alias are registered first, so that they win over the
+	// aliased metrics reporting under the same name.
+	for _, aliased := range []bool{false, true} {
+		for _, d := range descs {
+			_, ok := aliases[d.Name]
+			if ok != aliased {
+				continue
+			}
+			if opts.RequireMappedUnit && !hasDatadogUnit(d.Name) {
+				continue
+			}
+
+			cumulative := isCumulative(d)
+
+			ddMetricName, tag, scale, err := reportedMetricName(d.Name, aliases, opts)
+			if err != nil {
+				rms.logger.Warn("runtimemetrics: not reporting one of the runtime metrics", slog.Attr{Key: "error", Value: slog.StringValue(err.Error())})
+				continue
+			}
+			tags := rms.baseTags
+			if tag != "" {
+				tags = append(slices.Clip(rms.baseTags), tag)
+			}
+
+			// Members of a tagged family share their Datadog name.
+			ddKey := ddMetricName
+			if len(tags) > len(rms.baseTags) {
+				ddKey += "," + tags[len(tags)-1]
+			}
+			if other, ok := ddNames[ddKey]; ok {
+				rms.logger.Debug("runtimemetrics: not reporting an aliased runtime metric, its Datadog name is already reported",
+					slog.Attr{Key: "metric_name", Value: slog.StringValue(d.Name)},
+					slog.Attr{Key: "reported_metric_name", Value: slog.StringValue(other)},
+				)
+				continue
+			}
+			ddNames[ddKey] = d.Name
+
+			rm := &runtimeMetric{
+				ddMetricName:       ddMetricName,
+				scale:              scale,
+				tags:               tags,
+				ddDistributionName: ddMetricName + opts.DistributionSuffix,
+				cumulative:         cumulative,
+				delta:              slices.Contains(opts.GaugeAsDelta, d.Name),
+			}
+			if d.Kind == metrics.KindFloat64Histogram {
+				for i, stat := range histogramStatNames {
+					if opts.PercentilesAsTags {
+						rm.summaryNames[i] = ddMetricName + ".summary"
+					} else {
+						rm.summaryNames[i] = summaryMetricName(ddMetricName, stat)
+					}
+				}
+				rm.summaryStats = allHistogramStats
+				if stats, ok := opts.HistogramStatOverrides[d.Name]; ok {
+					rm.summaryStats = histogramStatIndexes(stats)
+				}
+				if opts.HistogramCounts && cumulative {
+					rm.countName = ddMetricName + ".count"
+				}
+			}
+			rms.metrics[d.Name] = rm
+			rms.names = append(rms.names, d.Name)
 		}
+	}
+	slices.Sort(rms.names)
+
+	if opts.DerivedMetrics {
+		rms.derived = newDerivedMetrics(rms.metrics, opts)
+	}
+
+	if opts.GODEBUGActiveGauge {
+		rms.godebug = godebugMetrics(rms.metrics)
+	}
+
+	if opts.FallbackToLog {
+		rms.fallback = &fallbackLogger{logger: rms.logger, baseTags: rms.baseTags}
+	}
+
+	if opts.GoroutineSamplePeriod > 0 {
+		rms.goroutines = newGoroutineSampler(opts.GoroutineSamplePeriod)
+		rms.goroutineSamples = make([]float64, 0, maxGoroutineSamples)
+	}
 
-		rms.metrics[d.Name] = &runtimeMetric{
-			ddMetricName: ddMetricName,
-			cumulative:   cumulative,
+	if opts.MemStats {
+		rms.memStats = &memStatsCollector{period: opts.MemStatsPeriod}
+		if rms.memStats.period <= 0 {
+			rms.memStats.period = DefaultMemStatsPeriod
 		}
 	}
 
+	if opts.EmitLegacyNames || !opts.DualEmitUntil.IsZero() {
+		rms.legacy = resolveLegacyMetrics(rms.metrics)
+	}
+
 	rms.update()
 
 	return rms
 }
 
-func (rms runtimeMetricStore) update() {
-	// TODO: Reuse this slice to avoid allocations? Note: I don't see these
-	// allocs show up in profiling.
-	samples := make([]metrics.Sample, len(rms.metrics))
-	i := 0
-	// NOTE: Map iteration in Go is randomized, so we end up randomizing the
-	// samples slice. In theory this should not impact correctness, but it's
-	// worth keeping in mind in case problems are observed in the future.
-	for name := range rms.metrics {
-		samples[i].Name = name
-		i++
+// isCumulative returns whether the runtime metric d is cumulative.
+func isCumulative(d metrics.Description) bool {
+	// /sched/latencies:seconds is incorrectly set as non-cumulative,
+	// fixed by https://go-review.googlesource.com/c/go/+/486755
+	// TODO: Use a build tag to apply this logic to Go versions < 1.20.
+	return d.Cumulative || d.Name == "/sched/latencies:seconds"
+}
+
+// close releases the resources held by the store.
+func (rms *runtimeMetricStore) close() {
+	if rms.async != nil {
+		rms.async.close()
 	}
-	metrics.Read(samples)
-	timestamp := time.Now()
-	for _, s := range samples {
-		runtimeMetric := rms.metrics[s.Name]
+}
 
-		runtimeMetric.previousValue = runtimeMetric.currentValue
-		runtimeMetric.currentValue = s.Value
-		runtimeMetric.timestamp = timestamp
+func (rms *runtimeMetricStore) setClient(c partialStatsdClientInterface) {
+	rms.client.Store(&statsdClientRef{statsd: c, noop: isNoOpClient(c)})
+}
+
+// flush flushes the given client if it supports it and hasn't been closed.
+func (rms *runtimeMetricStore) flush(statsd partialStatsdClientInterface) {
+	if c, ok := statsd.(closableStatsdClient); ok && c.IsClosed() {
+		return
+	}
+	c, ok := statsd.(flushableStatsdClient)
+	if !ok {
+		return
+	}
+	if err := c.Flush(); err != nil {
+		rms.logger.Warn("runtimemetrics: failed to flush the statsd client", slog.Attr{Key: "error", Value: slog.StringValue(err.Error())})
 	}
 }
 
-func (rms runtimeMetricStore) report() {
-	rms.update()
-	samples := []distributionSample{}
+func (rms *runtimeMetricStore) update() time.Time {
+	if rms.samples == nil {
+		rms.samples = make([]sample, len(rms.names))
+		for i, name := range rms.names {
+			rms.samples[i].name = name
+		}
+	}
+	for _, s := range rms.samples {
+		rms.metrics[s.name].retainPrevious()
+	}
+	rms.sampler.read(rms.samples)
+	if rms.goroutines != nil {
+		// The samples taken before this update, e.g. during
+		// Options.InitialDelay, are discarded if it doesn't lead to a report.
+		rms.goroutineSamples = rms.goroutines.drain(rms.goroutineSamples)
+	}
+	timestamp := rms.now()
+	// Timestamps read from time.Now carry a monotonic clock reading, which
+	// time.Time.Sub uses, so the intervals of rates aren't skewed by wall
+	// clock adjustments, e.g. NTP steps. The timestamps submitted are wall
+	// clock times though, which shouldn't go backwards.
+	rms.clockJumped = !rms.lastTimestamp.IsZero() && timestamp.Round(0).Before(rms.lastTimestamp.Round(0))
+	rms.lastTimestamp = timestamp
+	for _, s := range rms.samples {
+		rms.metrics[s.name].setValue(s.value, timestamp)
+	}
+	rms.footprint.Store(int64(rms.memoryFootprint()))
+	return timestamp
+}
+
+// report reads the runtime metrics and submits them to the statsd client.
+// Once the first report has sized the buffers of the store, it doesn't
+// allocate, apart from the statsd client itself. The exceptions are logging,
+// e.g. of submission errors, the runtime changing the buckets of a
+// histogram, and the options that call user code, e.g. Options.OnReport.
+func (rms *runtimeMetricStore) report() {
+	// Load the client once, so that SetClient never takes effect in the
+	// middle of a report.
+	ref := rms.client.Load()
+	if ref.noop {
+		// Everything would be thrown away, so there's no point in reading
+		// metrics. If the client gets replaced later on, the first deltas of
+		// cumulative metrics will cover the whole no-op period.
+		return
+	}
+	client := ref.statsd
+	if c, ok := client.(closableStatsdClient); ok && c.IsClosed() {
+		// The application probably closed its client at shutdown without
+		// stopping us, don't waste any work on collecting metrics.
+		if rms.closedReports == 0 {
+			rms.logger.Warn("runtimemetrics: statsd client is closed, skipping reports until it is replaced")
+		}
+		rms.closedReports++
+		return
+	}
+	rms.closedReports = 0
 
-	for name, rm := range rms.metrics {
+	start := time.Now()
+	var timestamp time.Time
+	// truncated is the number of metrics skipped because of
+	// Options.MaxReportDuration.
+	var truncated int
+	ctx := context.Background()
+	if rms.beforeReport != nil {
+		ctx = rms.beforeReport(ctx)
+	}
+	if rms.afterReport != nil {
+		defer func() {
+			rms.afterReport(ctx, ReportStats{
+				Timestamp: timestamp,
+				Duration:  time.Since(start),
+				Tags:      slices.Clone(rms.baseTags),
+				Truncated: truncated,
+			})
+		}()
+	}
+	timestamp = rms.update()
+	if client == nil {
+		// Submissions are paused, but we still updated the values above to
+		// keep the cumulative baselines current.
+		if rms.derived != nil {
+			rms.derived.reset()
+		}
+		return
+	}
+	// suppressDeltas suppresses the delta-based series during the warm-up,
+	// see Options.WarmupPeriods, and after a wall clock jump, see update.
+	suppressDeltas := rms.warmupReports > 0
+	if suppressDeltas {
+		rms.warmupReports--
+		if rms.warmupReports == 0 {
+			rms.logger.Debug("runtimemetrics: warm-up completed, delta-based series are reported from the next report on")
+		}
+	}
+	if rms.clockJumped {
+		if !rms.clockJumpLogged {
+			rms.clockJumpLogged = true
+			rms.logger.Warn("runtimemetrics: the wall clock jumped backwards, skipping the delta-based series of the report")
+		}
+		suppressDeltas = true
+	}
+	// recorder is nil unless Options.HistorySize is positive.
+	var recorder *historyRecorder
+	if rms.onReport != nil || rms.history != nil {
+		defer func() {
+			stats := ReportStats{
+				Timestamp: timestamp,
+				Duration:  time.Since(start),
+				Tags:      slices.Clone(rms.baseTags),
+				Truncated: truncated,
+			}
+			if rms.history != nil {
+				rms.history.add(Report{ReportStats: stats, Samples: recorder.samples})
+			}
+			if rms.onReport != nil {
+				stats.Tags = slices.Clone(stats.Tags)
+				rms.onReport(stats)
+			}
+		}()
+	}
+	statsd := client
+	if rms.async != nil {
+		rms.async.begin(client)
+		statsd = rms.async
+	}
+	if rms.history != nil {
+		recorder = &historyRecorder{statsd: statsd}
+		statsd = recorder
+	}
+	if rms.fallback != nil {
+		rms.fallback.begin(statsd)
+		defer rms.fallback.end()
+		statsd = rms.fallback
+	}
+	if rms.buildInfoTags != nil {
+		statsd.GaugeWithTimestamp(buildInfoMetricName, 1, rms.buildInfoTags, 1, timestamp)
+	}
+
+	for _, name := range rms.names {
+		rm := rms.metrics[name]
+		if rm.disabled.Load() {
+			continue
+		}
+		if rms.maxReportDuration > 0 && (truncated > 0 || time.Since(start) > rms.maxReportDuration) {
+			truncated++
+			continue
+		}
 		switch rm.currentValue.Kind() {
 		case metrics.KindUint64:
 			v := rm.currentValue.Uint64()
@@ -184,10 +1486,10 @@ func (rms runtimeMetricStore) report() {
 			// This is known to happen with the '/memory/classes/heap/unused:bytes' metric: https://github.com/golang/go/blob/go1.22.1/src/runtime/metrics.go#L364
 			// Until this bug is fixed, we log the problematic value and skip submitting that point to avoid spurious spikes in graphs.
 			if v > math.MaxUint64/2 {
-				tags := make([]string, 0, len(rms.baseTags)+1)
-				tags = append(tags, rms.baseTags...)
+				tags := make([]string, 0, len(rms.selfTags)+1)
+				tags = append(tags, rms.selfTags...)
 				tags = append(tags, "metric_name:"+rm.ddMetricName)
-				rms.statsd.CountWithTimestamp("runtime.go.metrics.skipped_values", 1, tags, 1, rm.timestamp)
+				statsd.CountWithTimestamp(skippedValuesMetricName, 1, tags, 1, rm.timestamp)
 
 				// Some metrics are ~sort of expected to report this high value (e.g.
 				// "runtime.go.metrics.gc_gogc.percent" will consistently report "MaxUint64 - 1" if
@@ -206,7 +1508,8 @@ func (rms runtimeMetricStore) report() {
 					}
 
 					// Append all Uint64 values for maximum observability
-					for name, rm := range rms.metrics {
+					for _, name := range rms.names {
+						rm := rms.metrics[name]
 						if rm.currentValue.Kind() == metrics.KindUint64 {
 							logAttrs = append(logAttrs, slog.Attr{Key: name, Value: slog.Uint64Value(rm.currentValue.Uint64())})
 						}
@@ -217,7 +1520,11 @@ func (rms runtimeMetricStore) report() {
 				continue
 			}
 
-			rms.statsd.GaugeWithTimestamp(rm.ddMetricName, float64(v), rms.baseTags, 1, rm.timestamp)
+			if rm.delta {
+				rms.reportDelta(statsd, rm, suppressDeltas)
+				continue
+			}
+			statsd.GaugeWithTimestamp(rm.ddMetricName, float64(v)*rm.scale, rm.tags, 1, rm.timestamp)
 		case metrics.KindFloat64:
 			v := rm.currentValue.Float64()
 			// if the value didn't change between two reporting
@@ -229,37 +1536,53 @@ func (rms runtimeMetricStore) report() {
 			if rm.cumulative && v != 0 && v == rm.previousValue.Float64() {
 				continue
 			}
-			rms.statsd.GaugeWithTimestamp(rm.ddMetricName, v, rms.baseTags, 1, rm.timestamp)
+			if rm.delta {
+				rms.reportDelta(statsd, rm, suppressDeltas)
+				continue
+			}
+			statsd.GaugeWithTimestamp(rm.ddMetricName, v*rm.scale, rm.tags, 1, rm.timestamp)
 		case metrics.KindFloat64Histogram:
 			v := rm.currentValue.Float64Histogram()
-			var equal bool
 			if rm.cumulative {
 				// Note: This branch should ALWAYS be taken as of go1.21.
-				v, equal = sub(v, rm.previousValue.Float64Histogram())
+				equal := subInto(&rms.delta, v, rm.previousHistogram())
+				v = &rms.delta
 				// if the histogram didn't change between two reporting
 				// cycles, don't submit anything. this avoids having
 				// inaccurate drops to zero for percentile metrics
-				if equal {
+				if equal || suppressDeltas {
 					continue
 				}
 			}
 
-			samples = samples[:0]
-			distSamples := distributionSamplesFromHist(v, samples)
-			values := make([]float64, len(distSamples))
-			for i, ds := range distSamples {
-				values[i] = ds.Value
-				rms.statsd.DistributionSamples(rm.ddMetricName, values[i:i+1], rms.baseTags, ds.Rate)
+			if rm.countName != "" {
+				statsd.CountWithTimestamp(rm.countName, int64(histogramCount(v)), rms.baseTags, 1, rm.timestamp)
+			}
+
+			if rm.scale != 1 {
+				scaleHistogram(&rms.scaled, v, rm.scale)
+				v = &rms.scaled
+			}
+			if c, ok := statsd.(histogramStatsdClient); ok {
+				c.HistogramWithTimestamp(rm.ddMetricName, v, rms.baseTags, rm.timestamp)
+			}
+
+			rms.distSamples = distributionSamplesFromHist(v, rms.distSamples[:0])
+			rms.distValues = slices.Grow(rms.distValues[:0], len(rms.distSamples))[:len(rms.distSamples)]
+			for i, ds := range rms.distSamples {
+				rms.distValues[i] = ds.Value
+				statsd.DistributionSamples(rm.ddDistributionName, rms.distValues[i:i+1], rms.baseTags, ds.Rate)
 			}
 
-			stats := statsFromHist(v)
+			if len(rm.summaryStats) == 0 || rms.minHistogramSamples > 0 && histogramCount(v) < uint64(rms.minHistogramSamples) {
+				continue
+			}
+			stats := statsFromHist(v, rms.percentileInterpolation)
+			values := stats.values()
 			// TODO: Could/should we use datadog distribution metrics for this?
-			rms.statsd.GaugeWithTimestamp(rm.ddMetricName+".avg", stats.Avg, rms.baseTags, 1, rm.timestamp)
-			rms.statsd.GaugeWithTimestamp(rm.ddMetricName+".min", stats.Min, rms.baseTags, 1, rm.timestamp)
-			rms.statsd.GaugeWithTimestamp(rm.ddMetricName+".max", stats.Max, rms.baseTags, 1, rm.timestamp)
-			rms.statsd.GaugeWithTimestamp(rm.ddMetricName+".median", stats.Median, rms.baseTags, 1, rm.timestamp)
-			rms.statsd.GaugeWithTimestamp(rm.ddMetricName+".p95", stats.P95, rms.baseTags, 1, rm.timestamp)
-			rms.statsd.GaugeWithTimestamp(rm.ddMetricName+".p99", stats.P99, rms.baseTags, 1, rm.timestamp)
+			for _, i := range rm.summaryStats {
+				statsd.GaugeWithTimestamp(rm.summaryNames[i], values[i], rms.summaryTags[i], 1, rm.timestamp)
+			}
 		case metrics.KindBad:
 			// This should never happen because all metrics are supported
 			// by construction.
@@ -279,6 +1602,80 @@ func (rms runtimeMetricStore) report() {
 			})
 		}
 	}
+
+	if truncated > 0 {
+		rms.logger.Warn("runtimemetrics: report exceeded the maximum report duration, skipped the remaining metrics",
+			slog.Attr{Key: "skipped_metrics", Value: slog.IntValue(truncated)},
+			slog.Attr{Key: "max_report_duration", Value: slog.DurationValue(rms.maxReportDuration)},
+		)
+	} else {
+		rms.reportAdditional(statsd, timestamp, suppressDeltas)
+	}
+
+	if rms.async != nil {
+		if dropped := rms.async.end(); dropped > 0 {
+			rms.logger.Warn("runtimemetrics: statsd client is too slow, dropped submissions",
+				slog.Attr{Key: "dropped", Value: slog.IntValue(dropped)},
+				slog.Attr{Key: "timeout", Value: slog.DurationValue(rms.async.timeout)},
+			)
+			// Flushing would most likely block as well.
+			return
+		}
+	}
+	if rms.flushEachReport {
+		rms.flush(client)
+	}
+}
+
+// reportDelta submits the delta of a scalar metric since the previous
+// report, see Options.GaugeAsDelta.
+func (rms *runtimeMetricStore) reportDelta(statsd partialStatsdClientInterface, rm *runtimeMetric, suppressDeltas bool) {
+	if d, ok := rm.scalarDelta(); ok && !suppressDeltas {
+		statsd.GaugeWithTimestamp(rm.ddMetricName, d*rm.scale, rm.tags, 1, rm.timestamp)
+	}
+}
+
+// reportAdditional submits the metrics that aren't read from runtime/metrics
+// directly: GOMAXPROCS, the number of CPUs, the GODEBUG events, derived
+// metrics, memstats and legacy names. The delta-based series are computed but
+// not submitted if suppressDeltas is set.
+func (rms *runtimeMetricStore) reportAdditional(statsd partialStatsdClientInterface, timestamp time.Time, suppressDeltas bool) {
+	if rms.gomaxprocsGauge {
+		statsd.GaugeWithTimestamp(gomaxprocsGaugeName, float64(runtime.GOMAXPROCS(0)), rms.baseTags, 1, timestamp)
+	}
+	if rms.numCPUGauge {
+		statsd.GaugeWithTimestamp(numCPUGaugeName, float64(runtime.NumCPU()), rms.baseTags, 1, timestamp)
+	}
+	if rms.godebug != nil {
+		statsd.GaugeWithTimestamp(godebugActiveGaugeName, godebugActive(rms.godebug), rms.baseTags, 1, timestamp)
+	}
+	if len(rms.goroutineSamples) > 0 {
+		statsd.DistributionSamples(goroutineSamplesMetricName, rms.goroutineSamples, rms.baseTags, 1)
+	}
+
+	if rms.derived != nil {
+		rms.derived.report(statsd, rms.baseTags, rms.logger, suppressDeltas)
+	}
+
+	if rms.memStats != nil {
+		rms.memStats.report(statsd, rms.baseTags, timestamp)
+	}
+
+	if rms.legacy != nil && !rms.dualEmitting(timestamp) {
+		rms.legacy = nil
+	}
+	for i := range rms.legacy {
+		if v, ok := rms.legacy[i].value(&rms.delta, rms.percentileInterpolation); ok && !(suppressDeltas && rms.legacy[i].fromDeltas()) {
+			statsd.GaugeWithTimestamp(rms.legacy[i].Name, v, rms.baseTags, 1, timestamp)
+		}
+	}
+}
+
+// hasDatadogUnit returns true if the unit of the runtime metric has a
+// standard Datadog unit, see Options.RequireMappedUnit.
+func hasDatadogUnit(runtimeName string) bool {
+	unit, _ := metadata.DatadogUnit(metadata.RuntimeUnit(runtimeName))
+	return unit != ""
 }
 
 // regex extracted from https://cs.opensource.google/go/go/+/refs/tags/go1.20.3:src/runtime/metrics/description.go;l=13
@@ -287,7 +1684,67 @@ var runtimeMetricRegex = regexp.MustCompile("^(?P<name>/[^:]+):(?P<unit>[^:*/]+(
 // see https://docs.datadoghq.com/metrics/custom_metrics/#naming-custom-metrics
 var datadogMetricRegex = regexp.MustCompile(`[^a-zA-Z0-9\._]`)
 
+// validateGaugeAsDelta returns an error if any of names, see
+// Options.GaugeAsDelta, isn't a scalar metric of descs.
+func validateGaugeAsDelta(names []string, descs []metrics.Description) error {
+	for _, name := range names {
+		i := slices.IndexFunc(descs, func(d metrics.Description) bool { return d.Name == name })
+		if i < 0 {
+			return fmt.Errorf("runtimemetrics: unknown metric %s, it can't be reported as a delta", name)
+		}
+		if k := descs[i].Kind; k != metrics.KindUint64 && k != metrics.KindFloat64 {
+			return fmt.Errorf("runtimemetrics: %s isn't a scalar metric, it can't be reported as a delta", name)
+		}
+	}
+	return nil
+}
+
+// validateNameSeparator returns an error if sep isn't a valid
+// Options.NameSeparator.
+func validateNameSeparator(sep string) error {
+	if sep != "" && sep != "." && sep != "_" {
+		return fmt.Errorf("runtimemetrics: invalid name separator %q, it must be \".\" or \"_\"", sep)
+	}
+	return nil
+}
+
 func datadogMetricName(runtimeName string) (string, error) {
+	return datadogMetricNameWithSeparator(runtimeName, "")
+}
+
+// reportedMetricName returns the Datadog name the runtime metric is reported
+// under with opts, the tag identifying it within its tagged family or "",
+// and the factor converting its values to the unit of that name. Aliased
+// metrics, see Options.MetricAliases, keep their alias rather than being
+// renamed by TaggedFamilies, NameSeparator and SubsystemPrefixes.
+func reportedMetricName(runtimeName string, aliases map[string]string, opts *Options) (name, tag string, scale float64, err error) {
+	name, aliased := aliases[runtimeName]
+	if !aliased {
+		ddPath := runtimeName
+		for _, f := range opts.TaggedFamilies {
+			family := metricFamilies[f]
+			if t, ok := family.member(runtimeName); ok {
+				ddPath = family.ddMetricPath + ":" + family.unit
+				tag = t
+				break
+			}
+		}
+		name, err = datadogMetricNameWithSeparator(ddPath, opts.NameSeparator)
+		if err != nil {
+			return "", "", 0, err
+		}
+		if prefix, ok := opts.SubsystemPrefixes[metricSubsystem(runtimeName)]; ok {
+			name = prefix + strings.TrimPrefix(name, datadogMetricPrefix)
+		}
+	}
+	name, scale = durationUnit(runtimeName, name, opts.DurationUnit)
+	name, factor := unitScale(runtimeName, name, opts.UnitScale)
+	return name, tag, scale * factor, nil
+}
+
+// datadogMetricNameWithSeparator is datadogMetricName with the given
+// Options.NameSeparator.
+func datadogMetricNameWithSeparator(runtimeName, sep string) (string, error) {
 	m := runtimeMetricRegex.FindStringSubmatch(runtimeName)
 
 	if len(m) != 3 {
@@ -298,9 +1755,13 @@ func datadogMetricName(runtimeName string) (string, error) {
 	metricPath := strings.TrimPrefix(m[1], "/")
 	metricUnit := m[2]
 
-	name := datadogMetricRegex.ReplaceAllString(metricPath+"."+metricUnit, "_")
+	pathSep, unitSep := "_", "."
+	if sep != "" {
+		pathSep, unitSep = sep, sep
+	}
+	name := datadogMetricRegex.ReplaceAllString(metricPath, pathSep) + unitSep + datadogMetricRegex.ReplaceAllString(metricUnit, pathSep)
 
 	// Note: This prefix is special. Don't change it without consulting the
 	// runtime/metrics squad.
-	return "runtime.go.metrics." + name, nil
+	return datadogMetricPrefix + name, nil
 }
@@ -1,11 +1,17 @@
 package runtimemetrics
 
 import (
+	"bytes"
+	"context"
+	"errors"
 	"fmt"
+	"io"
 	"log/slog"
+	"math"
 	"runtime"
 	"runtime/debug"
 	"runtime/metrics"
+	"slices"
 	"strings"
 	"sync"
 	"testing"
@@ -15,24 +21,34 @@ import (
 	"github.com/stretchr/testify/require"
 )
 
-func TestStart(t *testing.T) {
-	cleanup := func() {
-		mu.Lock()
-		enabled = false
-		mu.Unlock()
+// stopActiveEmitters stops the running emitters, including the ones started
+// without keeping a reference, e.g. by Start.
+func stopActiveEmitters() {
+	mu.Lock()
+	emitters := slices.Clone(activeEmitters)
+	mu.Unlock()
+	for _, e := range emitters {
+		e.Stop()
 	}
+}
 
-	t.Run("start returns an error when called successively", func(t *testing.T) {
-		t.Cleanup(cleanup)
-		err := Start(&statsdClientMock{}, slog.Default())
+func TestStart(t *testing.T) {
+	t.Run("start warns when called successively", func(t *testing.T) {
+		t.Cleanup(stopActiveEmitters)
+		var logs bytes.Buffer
+		logger := slog.New(slog.NewTextHandler(&logs, nil))
+		err := Start(&statsdClientMock{}, logger)
 		assert.NoError(t, err)
+		assert.NotContains(t, logs.String(), "another emitter is already running")
 
-		err = Start(&statsdClientMock{}, slog.Default())
-		assert.Error(t, err)
+		err = Start(&statsdClientMock{}, logger)
+		assert.NoError(t, err)
+		assert.Contains(t, logs.String(), "another emitter is already running")
+		assert.Equal(t, 2, ActiveEmitters())
 	})
 
 	t.Run("should not race with other start calls", func(t *testing.T) {
-		t.Cleanup(cleanup)
+		t.Cleanup(stopActiveEmitters)
 		wg := sync.WaitGroup{}
 		for i := 0; i < 10; i++ {
 			wg.Add(1)
@@ -43,6 +59,705 @@ func TestStart(t *testing.T) {
 		}
 		wg.Wait()
 	})
+
+	t.Run("a new emitter can be started after stopping the previous one", func(t *testing.T) {
+		t.Cleanup(stopActiveEmitters)
+		var logs bytes.Buffer
+		logger := slog.New(slog.NewTextHandler(&logs, nil))
+		e, err := NewEmitter(&statsdClientMock{}, &Options{Logger: logger})
+		require.NoError(t, err)
+		assert.NoError(t, e.LastError())
+		assert.Equal(t, 1, ActiveEmitters())
+		e.Stop()
+		e.Stop() // idempotent
+		assert.Equal(t, 0, ActiveEmitters())
+
+		e, err = NewEmitter(&statsdClientMock{}, &Options{Logger: logger})
+		require.NoError(t, err)
+		e.Stop()
+		assert.NotContains(t, logs.String(), "another emitter is already running")
+	})
+
+	t.Run("the warning shows where both emitters were started", func(t *testing.T) {
+		t.Cleanup(stopActiveEmitters)
+		first, err := NewEmitter(&statsdClientMock{}, nil)
+		require.NoError(t, err)
+
+		var logs bytes.Buffer
+		second, err := NewEmitter(&statsdClientMock{}, &Options{Logger: slog.New(slog.NewJSONHandler(&logs, nil))})
+		require.NoError(t, err, "the second emitter shouldn't be blocked")
+		assert.Equal(t, 2, ActiveEmitters())
+		assert.Contains(t, logs.String(), `"active_emitters":1`)
+		assert.Contains(t, logs.String(), "TestStart.func")
+		assert.Contains(t, logs.String(), `"running_emitter_stack":`)
+
+		first.Stop()
+		assert.Equal(t, 1, ActiveEmitters())
+		second.Stop()
+		assert.Equal(t, 0, ActiveEmitters())
+	})
+}
+
+func TestInitialDelay(t *testing.T) {
+	t.Run("no report occurs before the delay elapses", func(t *testing.T) {
+		mock := &statsdClientMock{}
+		_, clock, reports := startFakeEmitter(t, mock, Options{InitialDelay: time.Minute})
+		start := clock.Now()
+
+		clock.Advance(59 * time.Second)
+		assert.Empty(t, mock.GaugeCalls())
+		clock.Advance(time.Second)
+		<-clock.tickerCreated
+		assert.Empty(t, mock.GaugeCalls())
+
+		clock.Advance(DefaultPeriod)
+		stats := <-reports
+		assert.Equal(t, start.Add(time.Minute+DefaultPeriod), stats.Timestamp)
+		assert.NotEmpty(t, mock.GaugeCalls())
+	})
+
+	t.Run("the warm-up starts after the delay", func(t *testing.T) {
+		mock := &statsdClientMock{}
+		_, clock, reports := startFakeEmitter(t, mock, Options{InitialDelay: time.Minute, WarmUp: time.Second})
+		start := clock.Now()
+
+		clock.Advance(time.Minute)
+		<-clock.tickerCreated
+		assert.Empty(t, mock.GaugeCalls())
+		clock.Advance(time.Second)
+		assert.Equal(t, start.Add(time.Minute+time.Second), (<-reports).Timestamp)
+	})
+
+	t.Run("stopping during the delay doesn't report", func(t *testing.T) {
+		mock := &statsdClientMock{}
+		e, clock, _ := startFakeEmitter(t, mock, Options{InitialDelay: time.Minute})
+		clock.Advance(30 * time.Second)
+		e.Stop()
+		assert.Empty(t, mock.GaugeCalls())
+	})
+}
+
+func TestWarmUp(t *testing.T) {
+	// pauses returns the number of GC pauses since the process started.
+	pauses := func() uint64 {
+		s := []metrics.Sample{{Name: "/gc/pauses:seconds"}}
+		metrics.Read(s)
+		return histogramCount(s[0].Value.Float64Histogram())
+	}
+
+	t.Run("the first report covers the warm-up interval", func(t *testing.T) {
+		// Work done before the emitter starts shouldn't be reported.
+		for i := 0; i < 5; i++ {
+			runtime.GC()
+		}
+		mock := &statsdClientMock{}
+		_, clock, reports := startFakeEmitter(t, mock, Options{WarmUp: time.Second, HistogramCounts: true})
+		start := clock.Now()
+		before := pauses()
+		runtime.GC()
+		after := pauses()
+
+		clock.Advance(time.Second)
+		assert.Equal(t, start.Add(time.Second), (<-reports).Timestamp)
+		var counts []int64
+		for _, c := range mock.CountCalls() {
+			if c.Name == "runtime.go.metrics.gc_pauses.seconds.count" {
+				counts = append(counts, c.Value)
+			}
+		}
+		require.Len(t, counts, 1)
+		assert.GreaterOrEqual(t, uint64(counts[0]), after-before, "the GC during the warm-up should be reported")
+		assert.Less(t, uint64(counts[0]), after, "the GCs before the emitter started shouldn't be reported")
+
+		// Then reports happen every period.
+		<-clock.tickerCreated
+		clock.Advance(DefaultPeriod)
+		assert.Equal(t, start.Add(time.Second+DefaultPeriod), (<-reports).Timestamp)
+	})
+
+	t.Run("stopping during the warm-up doesn't report", func(t *testing.T) {
+		mock := &statsdClientMock{}
+		e, clock, _ := startFakeEmitter(t, mock, Options{WarmUp: time.Second})
+		clock.Advance(time.Second / 2)
+		e.Stop()
+		assert.Empty(t, mock.GaugeCalls())
+	})
+}
+
+func TestWarmupPeriods(t *testing.T) {
+	descs := []metrics.Description{
+		metricDesc("/gc/heap/live:bytes", metrics.KindUint64),
+		metricDesc("/gc/pauses:seconds", metrics.KindFloat64Histogram),
+		metricDesc(gcCyclesMetricName, metrics.KindUint64),
+	}
+	f := &fakeSampler{}
+	for i := uint64(0); i < 5; i++ {
+		f.steps = append(f.steps, map[string]value{
+			"/gc/heap/live:bytes": uint64Value(100 + i),
+			"/gc/pauses:seconds": histogramValue(&metrics.Float64Histogram{
+				Counts:  []uint64{i, 2 * i},
+				Buckets: []float64{0, 1, 2},
+			}),
+			gcCyclesMetricName: uint64Value(10 * i),
+		})
+	}
+	var logs bytes.Buffer
+	mock := &statsdClientMock{}
+	rms := newRuntimeMetricStoreWithSampler(descs, f, mock, &Options{
+		Logger:          slog.New(slog.NewTextHandler(&logs, &slog.HandlerOptions{Level: slog.LevelDebug})),
+		WarmupPeriods:   2,
+		DerivedMetrics:  true,
+		HistogramCounts: true,
+	})
+	now := time.Now()
+	rms.now = func() time.Time { return now }
+
+	type report struct {
+		live, distribution, summary, count, gcFrequency bool
+	}
+	var reports []report
+	for i := 0; i < 3; i++ {
+		mock.Reset()
+		now = now.Add(10 * time.Second)
+		rms.report()
+		reports = append(reports, report{
+			live:         mock.CallsWithSuffix("gc_heap_live.bytes").Gauges != nil,
+			distribution: len(mock.DistributionCalls()) > 0,
+			summary:      mock.CallsWithSuffix("gc_pauses.seconds.p99").Gauges != nil,
+			count:        len(mock.CountCalls()) > 0,
+			gcFrequency:  len(gaugeValues(mock, gcFrequencyMetricName)) > 0,
+		})
+		if i == 0 {
+			assert.NotContains(t, logs.String(), "warm-up completed")
+		}
+	}
+	assert.Equal(t, []report{
+		{live: true},
+		{live: true},
+		{live: true, distribution: true, summary: true, count: true, gcFrequency: true},
+	}, reports)
+	assert.Equal(t, 1, strings.Count(logs.String(), "warm-up completed"))
+}
+
+func TestEmitterSetPeriod(t *testing.T) {
+	t.Run("a faster period increases the report rate", func(t *testing.T) {
+		mock := &statsdClientMock{}
+		e, clock, reports := startFakeEmitter(t, mock, Options{})
+		start := clock.Now()
+
+		require.NoError(t, e.SetPeriod(time.Second))
+		<-clock.tickerCreated
+		for i := 1; i <= 3; i++ {
+			clock.Advance(time.Second)
+			assert.Equal(t, start.Add(time.Duration(i)*time.Second), (<-reports).Timestamp)
+		}
+
+		// Restore the default period.
+		require.NoError(t, e.SetPeriod(DefaultPeriod))
+		<-clock.tickerCreated
+		clock.Advance(DefaultPeriod - time.Second)
+		assert.Empty(t, reports)
+		clock.Advance(time.Second)
+		assert.Equal(t, start.Add(3*time.Second+DefaultPeriod), (<-reports).Timestamp)
+	})
+
+	t.Run("short periods are raised to the minimum", func(t *testing.T) {
+		var logs bytes.Buffer
+		mock := &statsdClientMock{}
+		e, clock, reports := startFakeEmitter(t, mock, Options{
+			Period: time.Microsecond,
+			Logger: slog.New(slog.NewTextHandler(&logs, nil)),
+		})
+		start := clock.Now()
+		assert.Equal(t, MinPeriod, e.period)
+		assert.Contains(t, logs.String(), "period is too short")
+
+		clock.Advance(MinPeriod)
+		assert.Equal(t, start.Add(MinPeriod), (<-reports).Timestamp)
+
+		logs.Reset()
+		require.NoError(t, e.SetPeriod(time.Nanosecond))
+		<-clock.tickerCreated
+		assert.Contains(t, logs.String(), "period is too short")
+		clock.Advance(MinPeriod)
+		assert.Equal(t, start.Add(2*MinPeriod), (<-reports).Timestamp)
+	})
+
+	t.Run("long periods log a warning", func(t *testing.T) {
+		var logs bytes.Buffer
+		logger := slog.New(slog.NewTextHandler(&logs, nil))
+		assert.Equal(t, time.Hour, checkPeriod(time.Hour, logger))
+		assert.Contains(t, logs.String(), "period is very long")
+
+		logs.Reset()
+		assert.Equal(t, DefaultPeriod, checkPeriod(DefaultPeriod, logger))
+		assert.Empty(t, logs.String())
+	})
+
+	t.Run("invalid periods are rejected", func(t *testing.T) {
+		e, _, _ := startFakeEmitter(t, &statsdClientMock{}, Options{})
+		assert.Error(t, e.SetPeriod(0))
+		assert.Error(t, e.SetPeriod(-time.Second))
+	})
+
+	t.Run("a stopped emitter returns an error", func(t *testing.T) {
+		e, _, _ := startFakeEmitter(t, &statsdClientMock{}, Options{})
+		e.Stop()
+		assert.Error(t, e.SetPeriod(time.Second))
+	})
+
+	t.Run("can be called from OnReport", func(t *testing.T) {
+		clock := newFakeClock()
+		reports := make(chan ReportStats, 1)
+		var e *Emitter
+		e, err := NewEmitter(&statsdClientMock{}, &Options{
+			clock: clock,
+			OnReport: func(s ReportStats) {
+				assert.NoError(t, e.SetPeriod(time.Second))
+				assert.NoError(t, e.SetPeriod(2*time.Second), "should replace the pending period")
+				reports <- s
+			},
+		})
+		require.NoError(t, err)
+		t.Cleanup(e.Stop)
+		<-clock.tickerCreated
+		start := clock.Now()
+
+		clock.Advance(DefaultPeriod)
+		assert.Equal(t, start.Add(DefaultPeriod), (<-reports).Timestamp)
+		<-clock.tickerCreated
+		clock.Advance(2 * time.Second)
+		assert.Equal(t, start.Add(DefaultPeriod+2*time.Second), (<-reports).Timestamp)
+	})
+}
+
+// TestDefaults pins how the options are resolved: zero values fall back to
+// the exported defaults, and explicit values take precedence over them. The
+// package doesn't read any environment variable.
+func TestDefaults(t *testing.T) {
+	t.Run("period", func(t *testing.T) {
+		for _, tt := range []struct {
+			name   string
+			period time.Duration
+			want   time.Duration
+		}{
+			{name: "zero", period: 0, want: DefaultPeriod},
+			{name: "negative", period: -time.Second, want: DefaultPeriod},
+			{name: "explicit", period: 3 * time.Second, want: 3 * time.Second},
+			{name: "below the minimum", period: time.Millisecond, want: MinPeriod},
+		} {
+			t.Run(tt.name, func(t *testing.T) {
+				e, _, _ := startFakeEmitter(t, &statsdClientMock{}, Options{Period: tt.period})
+				assert.Equal(t, tt.want, e.period)
+			})
+		}
+	})
+
+	t.Run("store", func(t *testing.T) {
+		for _, tt := range []struct {
+			name string
+			opts Options
+
+			wantMemStatsPeriod time.Duration
+			wantThreshold      float64
+			wantPeriods        int
+		}{
+			{
+				name:               "zero",
+				wantMemStatsPeriod: DefaultMemStatsPeriod,
+				wantThreshold:      DefaultGCThrashingThreshold,
+				wantPeriods:        DefaultGCThrashingPeriods,
+			},
+			{
+				name:               "negative",
+				opts:               Options{MemStatsPeriod: -time.Second, GCThrashingThreshold: -1, GCThrashingPeriods: -1},
+				wantMemStatsPeriod: DefaultMemStatsPeriod,
+				wantThreshold:      DefaultGCThrashingThreshold,
+				wantPeriods:        DefaultGCThrashingPeriods,
+			},
+			{
+				name:               "explicit",
+				opts:               Options{MemStatsPeriod: 5 * time.Minute, GCThrashingThreshold: 0.5, GCThrashingPeriods: 10},
+				wantMemStatsPeriod: 5 * time.Minute,
+				wantThreshold:      0.5,
+				wantPeriods:        10,
+			},
+		} {
+			t.Run(tt.name, func(t *testing.T) {
+				opts := tt.opts
+				opts.Logger = slog.Default()
+				opts.MemStats = true
+				opts.DerivedMetrics = true
+				rms := newRuntimeMetricStore(nil, &statsdClientMock{}, &opts)
+				defer rms.close()
+				assert.Equal(t, tt.wantMemStatsPeriod, rms.memStats.period)
+				assert.Equal(t, tt.wantThreshold, rms.derived.gcThrashing.threshold)
+				assert.Equal(t, tt.wantPeriods, rms.derived.gcThrashing.periods)
+			})
+		}
+	})
+
+	t.Run("the caller's options should not be modified", func(t *testing.T) {
+		opts := Options{}
+		e, err := NewEmitter(&statsdClientMock{}, &opts)
+		require.NoError(t, err)
+		e.Stop()
+		assert.Zero(t, opts.Period)
+		assert.Nil(t, opts.Logger)
+	})
+}
+
+func TestEmitterSetClient(t *testing.T) {
+	newEmitter := func(client partialStatsdClientInterface) *Emitter {
+		// Don't start the reporting goroutine, tests call report directly.
+		return &Emitter{rms: newRuntimeMetricStore(metrics.All(), client, &Options{Logger: slog.Default()})}
+	}
+
+	t.Run("subsequent reports use the new client", func(t *testing.T) {
+		first, second := &statsdClientMock{}, &statsdClientMock{}
+		e := newEmitter(first)
+		e.store().report()
+		require.NotEmpty(t, first.GaugeCalls())
+		firstCalls := len(first.GaugeCalls())
+
+		e.SetClient(second)
+		e.store().report()
+		assert.Equal(t, firstCalls, len(first.GaugeCalls()))
+		assert.NotEmpty(t, second.GaugeCalls())
+	})
+
+	t.Run("a nil client pauses submissions", func(t *testing.T) {
+		mock := &statsdClientMock{}
+		e := newEmitter(mock)
+		e.SetClient(nil)
+		assert.NotPanics(t, e.store().report)
+		assert.Empty(t, mock.GaugeCalls())
+		assert.Empty(t, mock.DistributionCalls())
+
+		e.SetClient(mock)
+		e.store().report()
+		assert.NotEmpty(t, mock.GaugeCalls())
+	})
+
+	t.Run("SetStatsdClient swaps clients mid-stream", func(t *testing.T) {
+		first, second := &statsdClientMock{}, &statsdClientMock{}
+		e := newEmitter(first)
+		e.store().report()
+		firstCalls := len(first.GaugeCalls())
+
+		require.NoError(t, e.SetStatsdClient(second))
+		e.store().report()
+		secondCalls := len(second.GaugeCalls())
+		require.NotZero(t, secondCalls)
+		e.store().report()
+		assert.Equal(t, firstCalls, len(first.GaugeCalls()))
+		assert.Greater(t, len(second.GaugeCalls()), secondCalls)
+	})
+
+	t.Run("SetStatsdClient rejects nil clients", func(t *testing.T) {
+		mock := &statsdClientMock{}
+		e := newEmitter(mock)
+		assert.Error(t, e.SetStatsdClient(nil))
+		assert.Error(t, e.SetStatsdClient((*statsdClientMock)(nil)))
+		assert.Error(t, e.SetStatsdClient((*UDPClient)(nil)))
+		e.store().report()
+		assert.NotEmpty(t, mock.GaugeCalls(), "the client should be kept")
+	})
+
+	t.Run("should not race with a report in progress", func(t *testing.T) {
+		clients := make([]*statsdClientMock, 10)
+		for i := range clients {
+			clients[i] = &statsdClientMock{}
+		}
+		e := newEmitter(clients[0])
+
+		done := make(chan struct{})
+		go func() {
+			defer close(done)
+			for i := 0; i < 20; i++ {
+				e.store().report()
+			}
+		}()
+		for _, c := range clients {
+			e.SetClient(c)
+			e.SetClient(nil)
+			e.SetClient(c)
+		}
+		<-done
+
+		last := clients[len(clients)-1]
+		before := len(last.GaugeCalls())
+		e.store().report()
+		assert.Greater(t, len(last.GaugeCalls()), before)
+	})
+}
+
+func TestEmitterDisableMetric(t *testing.T) {
+	const name = "runtime.go.metrics.gc_pauses.seconds"
+	buckets := []float64{0, 1, 2}
+	hist := func(counts ...uint64) value {
+		return histogramValue(&metrics.Float64Histogram{Counts: counts, Buckets: buckets})
+	}
+
+	t.Run("a disabled metric isn't reported until enabled again", func(t *testing.T) {
+		mock, rms := newFakeStore("/gc/pauses:seconds", metrics.KindFloat64Histogram,
+			hist(0, 0), hist(1, 0), hist(1, 100), hist(2, 100))
+		e := &Emitter{rms: rms}
+
+		rms.report()
+		require.Len(t, mock.CallsWithSuffix(name+".max").Gauges, 1)
+
+		e.DisableMetric("/gc/pauses:seconds")
+		rms.report()
+		assert.Len(t, mock.CallsWithSuffix(name+".max").Gauges, 1)
+
+		e.EnableMetric("/gc/pauses:seconds")
+		rms.report()
+		// The 100 values recorded while disabled aren't reported.
+		assert.Equal(t, []float64{1, 1}, gaugeValues(mock, name+".max"))
+		dists := mock.DistributionCalls()
+		require.Len(t, dists, 2)
+		assert.Equal(t, 1.0, dists[1].Rate)
+	})
+
+	t.Run("unknown metrics are ignored", func(t *testing.T) {
+		_, rms := newFakeStore("/gc/pauses:seconds", metrics.KindFloat64Histogram, hist(0, 0))
+		e := &Emitter{rms: rms}
+		assert.NotPanics(t, func() { e.DisableMetric("/unknown:bytes") })
+	})
+
+	t.Run("should not race with a report in progress", func(t *testing.T) {
+		e := &Emitter{rms: newRuntimeMetricStore(metrics.All(), &statsdClientMock{}, &Options{Logger: slog.Default()})}
+		done := make(chan struct{})
+		go func() {
+			defer close(done)
+			for i := 0; i < 10; i++ {
+				e.store().report()
+			}
+		}()
+		for i := 0; i < 10; i++ {
+			e.DisableMetric("/gc/heap/live:bytes")
+			e.EnableMetric("/gc/heap/live:bytes")
+		}
+		<-done
+	})
+}
+
+func TestClosedClient(t *testing.T) {
+	t.Run("reports are skipped while the client is closed", func(t *testing.T) {
+		mock := &closableStatsdClientMock{}
+		rms := newRuntimeMetricStore(metrics.All(), mock, &Options{Logger: slog.Default()})
+
+		mock.closed.Store(true)
+		rms.report()
+		rms.report()
+		assert.Empty(t, mock.GaugeCalls())
+		assert.Equal(t, 2, rms.closedReports)
+
+		mock.closed.Store(false)
+		rms.report()
+		assert.NotEmpty(t, mock.GaugeCalls())
+		assert.Equal(t, 0, rms.closedReports)
+	})
+
+	t.Run("the emitter stops itself when the client stays closed", func(t *testing.T) {
+		mock := &closableStatsdClientMock{}
+		mock.closed.Store(true)
+		e, clock, _ := startFakeEmitter(t, mock, Options{})
+
+		// Each tick is only received once the previous report completed.
+		for i := 0; i <= maxClosedClientReports; i++ {
+			clock.Advance(DefaultPeriod)
+		}
+		<-e.done
+		assert.Empty(t, mock.GaugeCalls())
+		assert.Equal(t, maxClosedClientReports+1, e.store().closedReports)
+
+		// A new emitter can be started once the previous one stopped itself.
+		e2, err := NewEmitter(&statsdClientMock{}, nil)
+		require.NoError(t, err)
+		e2.Stop()
+	})
+}
+
+func TestNoOpClient(t *testing.T) {
+	mock := &noOpStatsdClientMock{}
+	rms := newRuntimeMetricStore(metrics.All(), mock, &Options{Logger: slog.Default()})
+	rm := rms.metrics["/gc/cycles/total:gc-cycles"]
+	before := rm.timestamp
+
+	rms.report()
+	assert.Empty(t, mock.GaugeCalls())
+	assert.Empty(t, mock.DistributionCalls())
+	assert.Equal(t, before, rm.timestamp, "metrics should not be read")
+
+	client := &statsdClientMock{}
+	rms.setClient(client)
+	rms.report()
+	assert.NotEmpty(t, client.GaugeCalls())
+}
+
+func TestFlushClient(t *testing.T) {
+	t.Run("the client is not flushed after each report by default", func(t *testing.T) {
+		mock := &flushableStatsdClientMock{}
+		rms := newRuntimeMetricStore(metrics.All(), mock, &Options{Logger: slog.Default()})
+		rms.report()
+		assert.Equal(t, int64(0), mock.flushCalls.Load())
+	})
+
+	t.Run("the client is flushed after each report when enabled", func(t *testing.T) {
+		mock := &flushableStatsdClientMock{flushErr: errors.New("flush failed")}
+		rms := newRuntimeMetricStore(metrics.All(), mock, &Options{Logger: slog.Default(), FlushClient: true})
+		rms.report()
+		rms.report()
+		assert.Equal(t, int64(2), mock.flushCalls.Load())
+	})
+
+	t.Run("the client is flushed when the emitter stops", func(t *testing.T) {
+		mock := &flushableStatsdClientMock{}
+		e, err := NewEmitter(mock, nil)
+		require.NoError(t, err)
+		e.Stop()
+		e.Stop()
+		assert.Equal(t, int64(1), mock.flushCalls.Load())
+	})
+}
+
+func TestOnReport(t *testing.T) {
+	var stats []ReportStats
+	mock := &statsdClientMock{}
+	rms := newRuntimeMetricStore(metrics.All(), mock, &Options{
+		Logger:   slog.Default(),
+		Tags:     []string{"service:foo", "env:prod"},
+		OnReport: func(s ReportStats) { stats = append(stats, s) },
+	})
+
+	rms.report()
+	require.Len(t, stats, 1)
+	assert.False(t, stats[0].Timestamp.IsZero())
+	assert.Positive(t, stats[0].Duration)
+	assert.Subset(t, stats[0].Tags, []string{"service:foo", "env:prod"})
+	assert.Subset(t, stats[0].Tags, getBaseTags())
+	assert.Equal(t, mock.GaugeCalls()[0].Tags, stats[0].Tags)
+
+	// The tags are a copy of the store's tags.
+	stats[0].Tags[0] = "mutated"
+	rms.report()
+	require.Len(t, stats, 2)
+	assert.NotContains(t, stats[1].Tags, "mutated")
+	assert.NotContains(t, mock.GaugeCalls()[len(mock.GaugeCalls())-1].Tags, "mutated")
+}
+
+func TestReportHooks(t *testing.T) {
+	type spanKey struct{}
+	newHooks := func(events *[]string) *Options {
+		return &Options{
+			Logger: slog.Default(),
+			BeforeReport: func(ctx context.Context) context.Context {
+				*events = append(*events, "before")
+				return context.WithValue(ctx, spanKey{}, len(*events))
+			},
+			OnReport: func(ReportStats) { *events = append(*events, "report") },
+			AfterReport: func(ctx context.Context, s ReportStats) {
+				*events = append(*events, fmt.Sprintf("after %v", ctx.Value(spanKey{})))
+				assert.False(t, s.Timestamp.IsZero())
+			},
+		}
+	}
+
+	t.Run("the hooks fire in order and the context propagates", func(t *testing.T) {
+		var events []string
+		mock := &statsdClientMock{}
+		rms := newRuntimeMetricStore(metrics.All(), mock, newHooks(&events))
+		rms.report()
+		rms.report()
+		assert.Equal(t, []string{"before", "report", "after 1", "before", "report", "after 4"}, events)
+	})
+
+	t.Run("the after hook fires for paused reports", func(t *testing.T) {
+		var events []string
+		rms := newRuntimeMetricStore(metrics.All(), nil, newHooks(&events))
+		rms.report()
+		assert.Equal(t, []string{"before", "after 1"}, events)
+	})
+
+	t.Run("the after hook may be set alone", func(t *testing.T) {
+		var ctx context.Context
+		rms := newRuntimeMetricStore(metrics.All(), &statsdClientMock{}, &Options{
+			Logger:      slog.Default(),
+			AfterReport: func(c context.Context, _ ReportStats) { ctx = c },
+		})
+		rms.report()
+		assert.Equal(t, context.Background(), ctx)
+	})
+
+	t.Run("the hooks don't fire for no-op clients", func(t *testing.T) {
+		var events []string
+		rms := newRuntimeMetricStore(metrics.All(), &noOpStatsdClientMock{}, newHooks(&events))
+		rms.report()
+		assert.Empty(t, events)
+	})
+}
+
+func TestMaxReportDuration(t *testing.T) {
+	t.Run("a slow report is cut short", func(t *testing.T) {
+		var stats ReportStats
+		mock := &slowStatsdClientMock{delay: 10 * time.Millisecond}
+		rms := newRuntimeMetricStore(metrics.All(), mock, &Options{
+			Logger:            slog.Default(),
+			MaxReportDuration: 30 * time.Millisecond,
+			OnReport:          func(s ReportStats) { stats = s },
+		})
+		rms.report()
+
+		assert.Positive(t, stats.Truncated)
+		assert.Less(t, len(mock.GaugeCalls()), len(rms.metrics))
+	})
+
+	t.Run("a fast report is complete", func(t *testing.T) {
+		var stats ReportStats
+		// All the metrics change on every read, so that each report submits
+		// the same series.
+		descs := metrics.All()
+		mock := &statsdClientMock{}
+		rms := newRuntimeMetricStoreWithSampler(descs, changingSampler(descs, 4), mock, &Options{
+			Logger:            slog.Default(),
+			MaxReportDuration: time.Minute,
+			OnReport:          func(s ReportStats) { stats = s },
+		})
+		rms.report()
+		assert.Zero(t, stats.Truncated)
+		assert.NotEmpty(t, mock.GaugeCalls())
+	})
+}
+
+func TestSelfMetricTags(t *testing.T) {
+	f := &fakeSampler{steps: []map[string]value{
+		{"/gc/heap/goal:bytes": uint64Value(1), "/gc/heap/live:bytes": uint64Value(1)},
+		{"/gc/heap/goal:bytes": uint64Value(2), "/gc/heap/live:bytes": uint64Value(math.MaxUint64)},
+	}}
+	descs := []metrics.Description{
+		metricDesc("/gc/heap/goal:bytes", metrics.KindUint64),
+		metricDesc("/gc/heap/live:bytes", metrics.KindUint64),
+	}
+	mock := &statsdClientMock{}
+	rms := newRuntimeMetricStoreWithSampler(descs, f, mock, &Options{
+		Logger:         slog.Default(),
+		Tags:           []string{"service:foo"},
+		SelfMetricTags: []string{"telemetry:internal"},
+	})
+	rms.report()
+
+	counts := mock.CountCalls()
+	require.Len(t, counts, 1)
+	assert.Equal(t, "runtime.go.metrics.skipped_values", counts[0].Name)
+	assert.Contains(t, counts[0].Tags, "telemetry:internal")
+	assert.Contains(t, counts[0].Tags, "service:foo")
+
+	gauges := mock.GaugeCalls()
+	require.Len(t, gauges, 1)
+	assert.NotContains(t, gauges[0].Tags, "telemetry:internal")
+	assert.Contains(t, gauges[0].Tags, "service:foo")
 }
 
 func TestDatadogMetricName(t *testing.T) {
@@ -63,6 +778,229 @@ func TestDatadogMetricName(t *testing.T) {
 	})
 }
 
+func TestNameSeparator(t *testing.T) {
+	t.Run("should join the path and the unit with the separator", func(t *testing.T) {
+		for sep, want := range map[string]map[string]string{
+			"": {
+				"/gc/heap/live:bytes":                  "runtime.go.metrics.gc_heap_live.bytes",
+				"/sched/goroutines-created:goroutines": "runtime.go.metrics.sched_goroutines_created.goroutines",
+				"/cpu/classes/gc/total:cpu-seconds":    "runtime.go.metrics.cpu_classes_gc_total.cpu_seconds",
+			},
+			".": {
+				"/gc/heap/live:bytes":                  "runtime.go.metrics.gc.heap.live.bytes",
+				"/sched/goroutines-created:goroutines": "runtime.go.metrics.sched.goroutines.created.goroutines",
+				"/cpu/classes/gc/total:cpu-seconds":    "runtime.go.metrics.cpu.classes.gc.total.cpu.seconds",
+			},
+			"_": {
+				"/gc/heap/live:bytes":                  "runtime.go.metrics.gc_heap_live_bytes",
+				"/sched/goroutines-created:goroutines": "runtime.go.metrics.sched_goroutines_created_goroutines",
+				"/cpu/classes/gc/total:cpu-seconds":    "runtime.go.metrics.cpu_classes_gc_total_cpu_seconds",
+			},
+		} {
+			for runtimeName, ddMetricName := range want {
+				name, err := datadogMetricNameWithSeparator(runtimeName, sep)
+				require.NoError(t, err)
+				assert.Equal(t, ddMetricName, name, "separator %q", sep)
+			}
+		}
+	})
+
+	t.Run("should report metrics under the separated names", func(t *testing.T) {
+		f := &fakeSampler{steps: []map[string]value{
+			{"/sync/mutex/wait/total:seconds": float64Value(0)},
+			{"/sync/mutex/wait/total:seconds": float64Value(0.25)},
+		}}
+		desc := metricDesc("/sync/mutex/wait/total:seconds", metrics.KindFloat64)
+		mock := &statsdClientMock{}
+		rms := newRuntimeMetricStoreWithSampler([]metrics.Description{desc}, f, mock, &Options{Logger: slog.Default(), NameSeparator: "_", DurationUnit: "milliseconds"})
+		rms.report()
+		assert.Equal(t, []float64{250}, gaugeValues(mock, "runtime.go.metrics.sync_mutex_wait_total_milliseconds"))
+	})
+
+	t.Run("should reject unsafe separators", func(t *testing.T) {
+		for _, sep := range []string{"-", "/", " ", "..", "__"} {
+			e, err := NewEmitter(&statsdClientMock{}, &Options{NameSeparator: sep})
+			assert.Error(t, err, "separator %q", sep)
+			assert.Nil(t, e)
+		}
+	})
+}
+
+// TestGCScanMetrics checks that the /gc/scan/* metrics, added in go1.21, are
+// reported as gauges under sensible names.
+func TestGCScanMetrics(t *testing.T) {
+	for name, ddMetricName := range map[string]string{
+		"/gc/scan/globals:bytes": "runtime.go.metrics.gc_scan_globals.bytes",
+		"/gc/scan/heap:bytes":    "runtime.go.metrics.gc_scan_heap.bytes",
+		"/gc/scan/stack:bytes":   "runtime.go.metrics.gc_scan_stack.bytes",
+		"/gc/scan/total:bytes":   "runtime.go.metrics.gc_scan_total.bytes",
+	} {
+		desc := metricDesc(name, metrics.KindUint64)
+		assert.False(t, desc.Cumulative, name)
+		mock, _ := reportMetric(name, metrics.KindUint64)
+		require.Len(t, mock.GaugeCalls(), 1, name)
+		assert.Equal(t, ddMetricName, mock.GaugeCalls()[0].Name)
+	}
+}
+
+func TestRequireMappedUnit(t *testing.T) {
+	opts := &Options{Logger: slog.Default(), RequireMappedUnit: true}
+
+	t.Run("metrics without a Datadog unit are excluded", func(t *testing.T) {
+		mock, rms := reportMetricWithOptions("/sched/goroutines:goroutines", metrics.KindUint64, opts)
+		assert.Empty(t, rms.metrics)
+		assert.Empty(t, mock.GaugeCalls())
+	})
+
+	t.Run("metrics with a Datadog unit are reported", func(t *testing.T) {
+		mock, _ := reportMetricWithOptions("/gc/heap/live:bytes", metrics.KindUint64, opts)
+		require.Len(t, mock.GaugeCalls(), 1)
+		assert.Equal(t, "runtime.go.metrics.gc_heap_live.bytes", mock.GaugeCalls()[0].Name)
+	})
+
+	t.Run("all metrics are reported by default", func(t *testing.T) {
+		mock, _ := reportMetric("/sched/goroutines:goroutines", metrics.KindUint64)
+		assert.Len(t, mock.GaugeCalls(), 1)
+	})
+}
+
+func TestReportOrder(t *testing.T) {
+	t.Run("should not depend on the order of the descriptions", func(t *testing.T) {
+		names := []string{
+			"/gc/heap/live:bytes",
+			"/gc/gogc:percent",
+			"/sched/goroutines:goroutines",
+			"/gc/heap/goal:bytes",
+			"/gc/stack/starting-size:bytes",
+		}
+		step := map[string]value{}
+		var descs []metrics.Description
+		for _, name := range names {
+			step[name] = uint64Value(1)
+			descs = append(descs, metricDesc(name, metrics.KindUint64))
+		}
+		reversed := slices.Clone(descs)
+		slices.Reverse(reversed)
+
+		var calls [2][]string
+		for i, d := range [][]metrics.Description{descs, reversed} {
+			mock := &statsdClientMock{}
+			sampler := &fakeSampler{steps: []map[string]value{step}}
+			rms := newRuntimeMetricStoreWithSampler(d, sampler, mock, &Options{Logger: slog.Default()})
+			rms.report()
+			for _, c := range mock.GaugeCalls() {
+				calls[i] = append(calls[i], c.Name)
+			}
+		}
+		require.Len(t, calls[0], len(names))
+		assert.Equal(t, calls[0], calls[1])
+		assert.True(t, slices.IsSorted(calls[0]), "metrics should be reported in the order of their runtime name")
+	})
+
+	t.Run("consecutive reports should submit the metrics in the same order", func(t *testing.T) {
+		// All the metrics change on every read, so that each report submits
+		// the same series.
+		descs := metrics.All()
+		mock := &statsdClientMock{}
+		rms := newRuntimeMetricStoreWithSampler(descs, changingSampler(descs, 4), mock, &Options{
+			Logger:             slog.Default(),
+			HistogramCounts:    true,
+			DerivedMetrics:     true,
+			GOMAXPROCSGauge:    true,
+			GODEBUGActiveGauge: true,
+		})
+		defer rms.close()
+		// The first report is skipped, its series depend on the values read
+		// by the constructor.
+		rms.report()
+		var reports [2][3][]string
+		for i := range reports {
+			mock.Reset()
+			rms.report()
+			for _, c := range mock.GaugeCalls() {
+				reports[i][0] = append(reports[i][0], c.Name)
+			}
+			for _, c := range mock.CountCalls() {
+				reports[i][1] = append(reports[i][1], c.Name)
+			}
+			for _, c := range mock.DistributionCalls() {
+				reports[i][2] = append(reports[i][2], c.Name)
+			}
+		}
+		require.NotEmpty(t, reports[0][0])
+		assert.Equal(t, reports[0], reports[1])
+	})
+}
+
+func TestGaugeAsDelta(t *testing.T) {
+	descs := []metrics.Description{
+		metricDesc("/gc/heap/live:bytes", metrics.KindUint64),
+		metricDesc("/gc/heap/goal:bytes", metrics.KindUint64),
+		metricDesc("/cpu/classes/gc/total:cpu-seconds", metrics.KindFloat64),
+	}
+	step := func(live, goal uint64, gcCPU float64) map[string]value {
+		return map[string]value{
+			descs[0].Name: uint64Value(live),
+			descs[1].Name: uint64Value(goal),
+			descs[2].Name: float64Value(gcCPU),
+		}
+	}
+	opts := func() *Options {
+		return &Options{
+			Logger:       slog.Default(),
+			GaugeAsDelta: []string{"/gc/heap/live:bytes", "/cpu/classes/gc/total:cpu-seconds"},
+		}
+	}
+
+	t.Run("should report the deltas of the listed metrics", func(t *testing.T) {
+		f := &fakeSampler{steps: []map[string]value{
+			step(10, 100, 1),
+			step(15, 100, 1.5),
+			step(15, 200, 3),
+			step(40, 200, 3.25),
+		}}
+		mock := &statsdClientMock{}
+		rms := newRuntimeMetricStoreWithSampler(descs, f, mock, opts())
+		rms.report()
+		rms.report()
+		rms.report()
+
+		assert.Equal(t, []float64{5, 0, 25}, gaugeValues(mock, "runtime.go.metrics.gc_heap_live.bytes"), "should report the deltas of an increasing gauge")
+		assert.Equal(t, []float64{100, 200, 200}, gaugeValues(mock, "runtime.go.metrics.gc_heap_goal.bytes"), "other metrics should report their value")
+		assert.Equal(t, []float64{0.5, 1.5, 0.25}, gaugeValues(mock, "runtime.go.metrics.cpu_classes_gc_total.cpu_seconds"))
+		assert.Contains(t, rms.mapping(descs), "/gc/heap/live:bytes -> runtime.go.metrics.gc_heap_live.bytes (uint64, delta)")
+	})
+
+	t.Run("should report negative deltas", func(t *testing.T) {
+		f := &fakeSampler{steps: []map[string]value{step(10, 100, 1), step(4, 100, 1)}}
+		mock := &statsdClientMock{}
+		rms := newRuntimeMetricStoreWithSampler(descs, f, mock, opts())
+		rms.report()
+		assert.Equal(t, []float64{-6}, gaugeValues(mock, "runtime.go.metrics.gc_heap_live.bytes"))
+	})
+
+	t.Run("should not be reported during the warm-up", func(t *testing.T) {
+		f := &fakeSampler{steps: []map[string]value{step(10, 100, 1), step(15, 100, 2), step(30, 100, 3)}}
+		mock := &statsdClientMock{}
+		o := opts()
+		o.WarmupPeriods = 1
+		rms := newRuntimeMetricStoreWithSampler(descs, f, mock, o)
+		rms.report()
+		rms.report()
+		assert.Equal(t, []float64{15}, gaugeValues(mock, "runtime.go.metrics.gc_heap_live.bytes"))
+	})
+
+	t.Run("should reject unknown metrics", func(t *testing.T) {
+		_, err := NewEmitter(&statsdClientMock{}, &Options{GaugeAsDelta: []string{"/gc/heap/lived:bytes"}})
+		assert.ErrorContains(t, err, "unknown metric /gc/heap/lived:bytes")
+	})
+
+	t.Run("should reject histograms", func(t *testing.T) {
+		_, err := NewEmitter(&statsdClientMock{}, &Options{GaugeAsDelta: []string{"/gc/pauses:seconds"}})
+		assert.ErrorContains(t, err, "isn't a scalar metric")
+	})
+}
+
 // TestMetricKinds is an integration test that tests one metric for each
 // metrics.ValueKind that exists.
 func TestMetricKinds(t *testing.T) {
@@ -72,24 +1010,24 @@ func TestMetricKinds(t *testing.T) {
 			old := debug.SetGCPercent(123)
 			defer debug.SetGCPercent(old)
 			mock, _ := reportMetric("/gc/gogc:percent", metrics.KindUint64)
-			require.Equal(t, 1, len(mock.gaugeCall))
-			require.Equal(t, 123.0, mock.gaugeCall[0].value)
-			require.True(t, strings.HasSuffix(mock.gaugeCall[0].name, ".gc_gogc.percent"))
+			require.Equal(t, 1, len(mock.GaugeCalls()))
+			require.Equal(t, 123.0, mock.GaugeCalls()[0].Value)
+			require.True(t, strings.HasSuffix(mock.GaugeCalls()[0].Name, ".gc_gogc.percent"))
 		})
 
 		t.Run("Cumulative", func(t *testing.T) {
 			// Note: This test could fail if an unexpected GC occurs. This
 			// should be extremely unlikely.
 			mock, rms := reportMetric("/gc/cycles/total:gc-cycles", metrics.KindUint64)
-			require.Equal(t, 1, len(mock.gaugeCall))
-			require.GreaterOrEqual(t, mock.gaugeCall[0].value, 1.0)
-			require.True(t, strings.HasSuffix(mock.gaugeCall[0].name, ".gc_cycles_total.gc_cycles"), mock.gaugeCall[0].name)
+			require.Equal(t, 1, len(mock.GaugeCalls()))
+			require.GreaterOrEqual(t, mock.GaugeCalls()[0].Value, 1.0)
+			require.True(t, strings.HasSuffix(mock.GaugeCalls()[0].Name, ".gc_cycles_total.gc_cycles"), mock.GaugeCalls()[0].Name)
 			// Note: Only these two GC cycles are expected to occur here
 			runtime.GC()
 			runtime.GC()
 			rms.report()
-			require.Equal(t, 2, len(mock.gaugeCall))
-			require.Greater(t, mock.gaugeCall[1].value, mock.gaugeCall[0].value)
+			require.Equal(t, 2, len(mock.GaugeCalls()))
+			require.Greater(t, mock.GaugeCalls()[1].Value, mock.GaugeCalls()[0].Value)
 		})
 	})
 
@@ -105,19 +1043,15 @@ func TestMetricKinds(t *testing.T) {
 		})
 
 		t.Run("Cumulative", func(t *testing.T) {
-			// Note: This test could fail if we get extremely unlucky with the
-			// scheduling. This should be extremely unlikely.
-			mock, rms := reportMetric("/sync/mutex/wait/total:seconds", metrics.KindFloat64)
-
-			// With Go 1.22: mutex wait sometimes increments when calling runtime.GC().
-			// This does not seem to happen with Go <= 1.21
-			beforeCallCount := len(mock.gaugeCall)
-			require.LessOrEqual(t, beforeCallCount, 1)
-			createLockContention(100 * time.Millisecond)
+			mock, rms := newFakeStore("/sync/mutex/wait/total:seconds", metrics.KindFloat64,
+				float64Value(0), float64Value(0), float64Value(0.5), float64Value(0.5))
+			// Zero values are submitted even if unchanged, see report.
+			rms.report()
+			require.Equal(t, []float64{0}, gaugeValues(mock, "runtime.go.metrics.sync_mutex_wait_total.seconds"))
 			rms.report()
-			require.Equal(t, beforeCallCount+1, len(mock.gaugeCall))
-			require.Greater(t, mock.gaugeCall[beforeCallCount].value, 0.0)
-			require.True(t, strings.HasSuffix(mock.gaugeCall[0].name, ".sync_mutex_wait_total.seconds"), mock.gaugeCall[0].name)
+			require.Equal(t, []float64{0, 0.5}, gaugeValues(mock, "runtime.go.metrics.sync_mutex_wait_total.seconds"))
+			rms.report()
+			require.Equal(t, []float64{0, 0.5}, gaugeValues(mock, "runtime.go.metrics.sync_mutex_wait_total.seconds"))
 		})
 	})
 
@@ -138,42 +1072,107 @@ func TestMetricKinds(t *testing.T) {
 			// Note: This test could fail if an unexpected GC occurs. This
 			// should be extremely unlikely.
 			mock, rms := reportMetric("/gc/pauses:seconds", metrics.KindFloat64Histogram)
-			require.Equal(t, len(summaries), len(mock.gaugeCall))
+			require.Equal(t, len(summaries), len(mock.GaugeCalls()))
 			for _, summary := range summaries {
 				want := ".gc_pauses.seconds." + summary
-				found := false
-				for _, call := range mock.gaugeCall {
-					if strings.HasSuffix(call.name, want) {
-						found = true
-						break
-					}
-				}
-				if !found {
-					t.Errorf("missing %s metric", want)
-				}
-			}
-			found := false
-			want := ".gc_pauses.seconds"
-			for _, call := range mock.distributionSampleCall {
-				if strings.HasSuffix(call.name, want) {
-					found = true
-					break
-				}
-			}
-			if !found {
-				t.Errorf("missing %s metric", want)
+				assert.Len(t, mock.CallsWithSuffix(want).Gauges, 1, "missing %s metric", want)
 			}
+			assert.NotEmpty(t, mock.CallsWithSuffix(".gc_pauses.seconds").Distributions, "missing .gc_pauses.seconds metric")
 			rms.report()
 			// Note: No GC cycle is expected to occur here
-			require.Equal(t, len(summaries), len(mock.gaugeCall))
+			require.Equal(t, len(summaries), len(mock.GaugeCalls()))
 			// Note: Only this GC cycle is expected to occur here
 			runtime.GC()
 			rms.report()
-			require.Equal(t, len(summaries)*2, len(mock.gaugeCall))
+			require.Equal(t, len(summaries)*2, len(mock.GaugeCalls()))
+		})
+
+		t.Run("PercentilesAsTags", func(t *testing.T) {
+			mock, _ := reportMetricWithOptions("/gc/pauses:seconds", metrics.KindFloat64Histogram, &Options{
+				Logger:            slog.Default(),
+				PercentilesAsTags: true,
+			})
+			require.Len(t, mock.GaugeCalls(), 6)
+			var stats []string
+			for _, call := range mock.GaugeCalls() {
+				require.Equal(t, "runtime.go.metrics.gc_pauses.seconds.summary", call.Name)
+				stat := call.Tags[len(call.Tags)-1]
+				require.True(t, strings.HasPrefix(stat, "stat:"), stat)
+				stats = append(stats, strings.TrimPrefix(stat, "stat:"))
+				assert.Subset(t, call.Tags, getBaseTags())
+			}
+			assert.Equal(t, []string{"avg", "min", "max", "median", "p95", "p99"}, stats)
+			// the distribution keeps its name
+			require.NotEmpty(t, mock.DistributionCalls())
+			assert.Equal(t, "runtime.go.metrics.gc_pauses.seconds", mock.DistributionCalls()[0].Name)
+		})
+
+		t.Run("DistributionSuffix", func(t *testing.T) {
+			mock, _ := reportMetricWithOptions("/gc/pauses:seconds", metrics.KindFloat64Histogram, &Options{
+				Logger:             slog.Default(),
+				DistributionSuffix: ".distribution",
+			})
+			require.NotEmpty(t, mock.DistributionCalls())
+			for _, call := range mock.DistributionCalls() {
+				require.Equal(t, "runtime.go.metrics.gc_pauses.seconds.distribution", call.Name)
+			}
+			// summaries are not affected
+			for _, call := range mock.GaugeCalls() {
+				require.NotContains(t, call.Name, ".distribution")
+			}
 		})
 	})
 }
 
+func TestMemoryFootprint(t *testing.T) {
+	t.Run("should stay within the budget", func(t *testing.T) {
+		mock := &statsdClientMock{}
+		e, clock, reports := startFakeEmitter(t, mock, Options{})
+		clock.Advance(DefaultPeriod)
+		<-reports
+
+		// The previous and current counts of each histogram, and at most
+		// one copy of its boundaries.
+		var budget int
+		for _, d := range metrics.All() {
+			if d.Kind != metrics.KindFloat64Histogram {
+				continue
+			}
+			s := []metrics.Sample{{Name: d.Name}}
+			metrics.Read(s)
+			h := s[0].Value.Float64Histogram()
+			budget += 8 * (2*len(h.Counts) + len(h.Buckets))
+		}
+		assert.Positive(t, e.MemoryFootprint())
+		assert.LessOrEqual(t, e.MemoryFootprint(), budget)
+	})
+
+	t.Run("the buckets should be shared by the values of a histogram", func(t *testing.T) {
+		desc := metricDesc("/gc/pauses:seconds", metrics.KindFloat64Histogram)
+		f := &fakeSampler{steps: []map[string]value{
+			{desc.Name: histogramValue(&metrics.Float64Histogram{Counts: []uint64{1, 2, 3}, Buckets: []float64{0, 1, 2, 3}})},
+			{desc.Name: histogramValue(&metrics.Float64Histogram{Counts: []uint64{2, 3, 4}, Buckets: []float64{0, 1, 2, 3}})},
+			{desc.Name: histogramValue(&metrics.Float64Histogram{Counts: []uint64{3, 4, 5}, Buckets: []float64{0, 1, 2, 3}})},
+		}}
+		mock := &statsdClientMock{}
+		rms := newRuntimeMetricStoreWithSampler([]metrics.Description{desc}, f, mock, &Options{Logger: slog.Default()})
+		rm := rms.metrics[desc.Name]
+		buckets := rm.currentValue.Float64Histogram().Buckets
+		rms.report()
+		rms.report()
+
+		assert.Same(t, &buckets[0], &rm.currentValue.Float64Histogram().Buckets[0])
+		assert.Same(t, &buckets[0], &rm.previousHistogram().Buckets[0])
+		assert.Equal(t, []uint64{2, 3, 4}, rm.previousHistogram().Counts)
+		// The previous and current counts, the shared buckets, and the report
+		// buffers.
+		buffers := 8*(cap(rms.delta.Counts)+cap(rms.distValues)) + 16*cap(rms.distSamples)
+		assert.Equal(t, 8*(3+3+4)+buffers, rms.memoryFootprint())
+		delta, _ := sub(rm.currentValue.Float64Histogram(), rm.previousHistogram())
+		assert.Equal(t, []uint64{1, 1, 1}, delta.Counts, "the deltas should be computed from the retained counts")
+	})
+}
+
 // TestSmoke is an integration test that is trying to read and report most
 // metrics and check that we don't crash or produce a very unexpected number of
 // metrics.
@@ -181,35 +1180,65 @@ func TestSmoke(t *testing.T) {
 	// Initialize store for all metrics with a mocked statsd client.
 	descs := metrics.All()
 	mock := &statsdClientMock{}
-	rms := newRuntimeMetricStore(descs, mock, slog.Default())
+	rms := newRuntimeMetricStore(descs, mock, &Options{Logger: slog.Default()})
 
 	// This poulates most runtime/metrics.
 	runtime.GC()
 
 	// But nothing should be sent to statsd yet.
-	assert.Equal(t, 0, len(mock.gaugeCall))
+	assert.Equal(t, 0, len(mock.GaugeCalls()))
 
 	// Flush the current metrics to our statsd mock.
 	rms.report()
 
-	// The exact number of statsd calls depends on the metric values and may
-	// also change as new version of Go are being released. So we assert that we
-	// get roughly the expected number of statsd calls (+/- 50%). This is meant
-	// to catch severe regression. Might need to be updated in the future if
-	// lots of new metrics are added.
-	assert.InDelta(t, 87, len(mock.gaugeCall), 87/2) // typically 87
+	// Cumulative metrics that didn't change since the store was created
+	// aren't reported, so we assert that we get at least half of the gauges
+	// of a report where all metrics changed. This is meant to catch severe
+	// regressions.
+	expected := ExpectedGaugeCount(descs, &Options{Logger: slog.Default()})
+	assert.LessOrEqual(t, len(mock.GaugeCalls()), expected)
+	assert.Greater(t, len(mock.GaugeCalls()), expected/2)
+
+	assert.Positive(t, len(mock.DistributionCalls()))
+}
+
+// TestReportAllocs ensures reports don't allocate once the buffers of the
+// store have been sized by the first one, see runtimeMetricStore.report.
+func TestReportAllocs(t *testing.T) {
+	for _, tt := range []struct {
+		name string
+		opts Options
+	}{
+		{name: "default"},
+		{name: "histogram counts and percentile tags", opts: Options{HistogramCounts: true, PercentilesAsTags: true}},
+		{name: "derived metrics", opts: Options{DerivedMetrics: true}},
+		{name: "tagged families", opts: Options{TaggedFamilies: []string{"goroutines"}}},
+		{name: "additional gauges", opts: Options{GOMAXPROCSGauge: true, GODEBUGActiveGauge: true}},
+		{name: "legacy names", opts: Options{EmitLegacyNames: true}},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			opts := tt.opts
+			opts.Logger = slog.Default()
+			rms := newRuntimeMetricStore(metrics.All(), &statsdClientMock{Discard: true}, &opts)
+			defer rms.close()
+			rms.report()
 
-	assert.Positive(t, len(mock.distributionSampleCall))
+			// Tolerate a stray allocation, e.g. by the runtime growing a
+			// histogram, but not one per metric.
+			allocs := testing.AllocsPerRun(100, rms.report)
+			assert.LessOrEqual(t, allocs, 1.0)
+		})
+	}
 }
 
 // BenchmarkReport is used to determine the overhead of collecting all metrics
 // and discarding them in a statsd mock. This can be used as a stress test,
-// identify regressions and to inform decisions about pollFrequency.
+// identify regressions and to inform decisions about DefaultPeriod.
 func BenchmarkReport(b *testing.B) {
 	// Initialize store for all metrics with a mocked statsd client.
 	descs := metrics.All()
 	mock := &statsdClientMock{Discard: true}
-	rms := newRuntimeMetricStore(descs, mock, slog.Default())
+	rms := newRuntimeMetricStore(descs, mock, &Options{Logger: slog.Default()})
 
 	// Benchmark report method
 	b.ReportAllocs()
@@ -219,14 +1248,53 @@ func BenchmarkReport(b *testing.B) {
 	}
 }
 
+// BenchmarkReportNoOp is like BenchmarkReport, but with a no-op client for
+// which reports are expected to be practically free.
+func BenchmarkReportNoOp(b *testing.B) {
+	descs := metrics.All()
+	rms := newRuntimeMetricStore(descs, &noOpStatsdClientMock{}, &Options{Logger: slog.Default()})
+
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		rms.report()
+	}
+}
+
+// BenchmarkNewEmitter measures the time NewEmitter blocks its caller, e.g.
+// during the cold start of a service. The emitters are stopped outside of
+// the timer.
+func BenchmarkNewEmitter(b *testing.B) {
+	mock := &statsdClientMock{Discard: true}
+	logger := slog.New(slog.NewTextHandler(io.Discard, nil))
+
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		e, err := NewEmitter(mock, &Options{Logger: logger})
+		if err != nil {
+			b.Fatal(err)
+		}
+		b.StopTimer()
+		e.Stop()
+		b.StartTimer()
+	}
+}
+
 // reportMetric creates a metrics store for the given metric, hooks it up to a
 // mock statsd client, triggers a GC cycle, calls report, and then returns
 // both. Callers are expected to observe the calls recorded by the mock and/or
 // trigger more activity.
-func reportMetric(name string, kind metrics.ValueKind) (*statsdClientMock, runtimeMetricStore) {
+func reportMetric(name string, kind metrics.ValueKind) (*statsdClientMock, *runtimeMetricStore) {
+	return reportMetricWithOptions(name, kind, &Options{Logger: slog.Default()})
+}
+
+// reportMetricWithOptions is like reportMetric, but creates the metrics store
+// with the given options.
+func reportMetricWithOptions(name string, kind metrics.ValueKind, opts *Options) (*statsdClientMock, *runtimeMetricStore) {
 	desc := metricDesc(name, kind)
 	mock := &statsdClientMock{}
-	rms := newRuntimeMetricStore([]metrics.Description{desc}, mock, slog.Default())
+	rms := newRuntimeMetricStore([]metrics.Description{desc}, mock, opts)
 	// Populate Metrics. Test implicitly expect this to be the only GC cycle to happen before report is finished.
 	runtime.GC()
 	rms.report()
@@ -245,27 +1313,3 @@ func metricDesc(name string, kind metrics.ValueKind) metrics.Description {
 	}
 	panic(fmt.Sprintf("unknown metric: %s", name))
 }
-
-// createLockContention attempts to create a lot of lock contention during the
-// given time window d. The runtime samples and upscales lock contention even
-// for metrics, so we need to produce up to 8 (gTrackingPeriod) contention
-// events per goroutine. If we get really unlucky with scheduling, we might fail
-// to achieve this, causing test flakiness. But this should be extremely
-// unlikely.
-func createLockContention(d time.Duration) {
-	var mu sync.Mutex
-	start := time.Now()
-	var wg sync.WaitGroup
-	for i := 0; i < runtime.GOMAXPROCS(0)*10; i++ {
-		wg.Add(1)
-		go func() {
-			defer wg.Done()
-			for time.Since(start) < d {
-				mu.Lock()
-				time.Sleep(d / 100)
-				mu.Unlock()
-			}
-		}()
-	}
-	wg.Wait()
-}
@@ -0,0 +1,178 @@
+// Package runtimemetricstest provides helpers for testing code that reports
+// metrics with the runtimemetrics package. The runtimemetrics tests use it as
+// well, and it is covered by the same compatibility guarantees as the rest of
+// the API, see the README.
+package runtimemetricstest
+
+import (
+	"context"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Call is a call recorded by a Recorder.
+type Call[T int64 | float64 | []float64] struct {
+	Name      string
+	Value     T
+	Tags      []string
+	Rate      float64
+	Timestamp time.Time
+}
+
+// Recorder is a statsd client recording all calls made by the runtimemetrics
+// emitter. It is safe for concurrent use.
+type Recorder struct {
+	mu                sync.Mutex
+	gaugeCalls        []Call[float64]
+	countCalls        []Call[int64]
+	distributionCalls []Call[[]float64]
+	// recorded is closed and reset when a call is recorded, see
+	// WaitForCalls.
+	recorded chan struct{}
+}
+
+// Calls are calls recorded by a Recorder, by kind.
+type Calls struct {
+	Gauges        []Call[float64]
+	Counts        []Call[int64]
+	Distributions []Call[[]float64]
+}
+
+// Len returns the total number of calls.
+func (c Calls) Len() int {
+	return len(c.Gauges) + len(c.Counts) + len(c.Distributions)
+}
+
+// notifyLocked wakes up the WaitForCalls callers. r.mu must be held.
+func (r *Recorder) notifyLocked() {
+	if r.recorded != nil {
+		close(r.recorded)
+		r.recorded = nil
+	}
+}
+
+// GaugeWithTimestamp records a gauge call.
+func (r *Recorder) GaugeWithTimestamp(name string, value float64, tags []string, rate float64, timestamp time.Time) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.gaugeCalls = append(r.gaugeCalls, Call[float64]{
+		Name:      name,
+		Value:     value,
+		Tags:      slices.Clone(tags),
+		Rate:      rate,
+		Timestamp: timestamp,
+	})
+	r.notifyLocked()
+	return nil
+}
+
+// CountWithTimestamp records a count call.
+func (r *Recorder) CountWithTimestamp(name string, value int64, tags []string, rate float64, timestamp time.Time) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.countCalls = append(r.countCalls, Call[int64]{
+		Name:      name,
+		Value:     value,
+		Tags:      slices.Clone(tags),
+		Rate:      rate,
+		Timestamp: timestamp,
+	})
+	r.notifyLocked()
+	return nil
+}
+
+// DistributionSamples records a distribution call.
+func (r *Recorder) DistributionSamples(name string, values []float64, tags []string, rate float64) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.distributionCalls = append(r.distributionCalls, Call[[]float64]{
+		Name:  name,
+		Value: slices.Clone(values),
+		Tags:  slices.Clone(tags),
+		Rate:  rate,
+	})
+	r.notifyLocked()
+	return nil
+}
+
+// GaugeCalls returns a copy of the recorded gauge calls.
+func (r *Recorder) GaugeCalls() []Call[float64] {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return slices.Clone(r.gaugeCalls)
+}
+
+// CountCalls returns a copy of the recorded count calls.
+func (r *Recorder) CountCalls() []Call[int64] {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return slices.Clone(r.countCalls)
+}
+
+// DistributionCalls returns a copy of the recorded distribution calls.
+func (r *Recorder) DistributionCalls() []Call[[]float64] {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return slices.Clone(r.distributionCalls)
+}
+
+// CallsWithSuffix returns a copy of the recorded calls whose name ends with
+// suffix, e.g. ".gc_pauses.seconds.p99".
+func (r *Recorder) CallsWithSuffix(suffix string) Calls {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return Calls{
+		Gauges:        withSuffix(r.gaugeCalls, suffix),
+		Counts:        withSuffix(r.countCalls, suffix),
+		Distributions: withSuffix(r.distributionCalls, suffix),
+	}
+}
+
+func withSuffix[T int64 | float64 | []float64](calls []Call[T], suffix string) []Call[T] {
+	var res []Call[T]
+	for _, c := range calls {
+		if strings.HasSuffix(c.Name, suffix) {
+			res = append(res, c)
+		}
+	}
+	return res
+}
+
+// WaitForCalls blocks until at least n calls of any kind have been recorded
+// since the Recorder was created or last reset. It returns ctx.Err() if ctx
+// is done first.
+func (r *Recorder) WaitForCalls(ctx context.Context, n int) error {
+	for {
+		r.mu.Lock()
+		if len(r.gaugeCalls)+len(r.countCalls)+len(r.distributionCalls) >= n {
+			r.mu.Unlock()
+			return nil
+		}
+		if r.recorded == nil {
+			r.recorded = make(chan struct{})
+		}
+		recorded := r.recorded
+		r.mu.Unlock()
+
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-recorded:
+		}
+	}
+}
+
+// Reset clears all recorded calls, leaving the Recorder in the same state as
+// a new one. The underlying storage is reused.
+func (r *Recorder) Reset() {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	clear(r.gaugeCalls)
+	r.gaugeCalls = r.gaugeCalls[:0]
+	clear(r.countCalls)
+	r.countCalls = r.countCalls[:0]
+	clear(r.distributionCalls)
+	r.distributionCalls = r.distributionCalls[:0]
+}
@@ -0,0 +1,98 @@
+package runtimemetricstest
+
+import (
+	"context"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRecorderReset(t *testing.T) {
+	t.Run("should clear all recorded calls", func(t *testing.T) {
+		r := &Recorder{}
+		r.GaugeWithTimestamp("gauge", 1, []string{"a:b"}, 1, time.Now())
+		r.CountWithTimestamp("count", 1, nil, 1, time.Now())
+		r.DistributionSamples("dist", []float64{1}, nil, 1)
+		gauges := r.GaugeCalls()
+
+		r.Reset()
+		assert.Empty(t, r.GaugeCalls())
+		assert.Empty(t, r.CountCalls())
+		assert.Empty(t, r.DistributionCalls())
+		// previously returned calls are not affected
+		assert.Equal(t, "gauge", gauges[0].Name)
+
+		r.GaugeWithTimestamp("other", 2, nil, 1, time.Now())
+		calls := r.GaugeCalls()
+		assert.Len(t, calls, 1)
+		assert.Equal(t, "other", calls[0].Name)
+	})
+
+	t.Run("should be safe for concurrent use", func(t *testing.T) {
+		r := &Recorder{}
+		var wg sync.WaitGroup
+		for i := 0; i < 10; i++ {
+			wg.Add(2)
+			go func() {
+				defer wg.Done()
+				for j := 0; j < 100; j++ {
+					r.GaugeWithTimestamp("gauge", 1, nil, 1, time.Now())
+					r.DistributionSamples("dist", []float64{1}, nil, 1)
+				}
+			}()
+			go func() {
+				defer wg.Done()
+				for j := 0; j < 10; j++ {
+					r.Reset()
+					r.GaugeCalls()
+				}
+			}()
+		}
+		wg.Wait()
+		r.Reset()
+		assert.Empty(t, r.GaugeCalls())
+	})
+}
+
+func TestRecorderCallsWithSuffix(t *testing.T) {
+	r := &Recorder{}
+	r.GaugeWithTimestamp("runtime.go.metrics.gc_pauses.seconds.p99", 1, nil, 1, time.Now())
+	r.GaugeWithTimestamp("runtime.go.metrics.gc_pauses.seconds.max", 1, nil, 1, time.Now())
+	r.CountWithTimestamp("runtime.go.metrics.skipped_values", 1, nil, 1, time.Now())
+	r.DistributionSamples("runtime.go.metrics.gc_pauses.seconds", []float64{1}, nil, 1)
+
+	calls := r.CallsWithSuffix(".p99")
+	assert.Equal(t, 1, calls.Len())
+	assert.Equal(t, "runtime.go.metrics.gc_pauses.seconds.p99", calls.Gauges[0].Name)
+
+	calls = r.CallsWithSuffix(".gc_pauses.seconds")
+	assert.Empty(t, calls.Gauges)
+	assert.Len(t, calls.Distributions, 1)
+
+	assert.Zero(t, r.CallsWithSuffix(".nope").Len())
+}
+
+func TestRecorderWaitForCalls(t *testing.T) {
+	t.Run("should return once enough calls are recorded", func(t *testing.T) {
+		r := &Recorder{}
+		go func() {
+			for i := 0; i < 3; i++ {
+				r.GaugeWithTimestamp("gauge", 1, nil, 1, time.Now())
+			}
+		}()
+		ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+		defer cancel()
+		assert.NoError(t, r.WaitForCalls(ctx, 3))
+		assert.Len(t, r.GaugeCalls(), 3)
+	})
+
+	t.Run("should return the context error", func(t *testing.T) {
+		r := &Recorder{}
+		r.CountWithTimestamp("count", 1, nil, 1, time.Now())
+		ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+		defer cancel()
+		assert.ErrorIs(t, r.WaitForCalls(ctx, 2), context.DeadlineExceeded)
+	})
+}
@@ -0,0 +1,87 @@
+package runtimemetrics
+
+import "runtime/metrics"
+
+// sampler reads runtime metrics. The store uses runtimeSampler, tests can
+// replace it to replay scripted samples that can't be provoked from the real
+// runtime.
+type sampler interface {
+	// read sets the value of each of the samples, like metrics.Read.
+	read(samples []sample)
+}
+
+// sample is a metrics.Sample whose value can be constructed outside of the
+// runtime.
+type sample struct {
+	name  string
+	value value
+}
+
+// value mirrors metrics.Value, which can't be constructed outside of the
+// runtime. Unlike metrics.Value, its accessors return the zero value rather
+// than panicking when called for the wrong kind.
+type value struct {
+	kind      metrics.ValueKind
+	uint64    uint64
+	float64   float64
+	histogram *metrics.Float64Histogram
+}
+
+func uint64Value(v uint64) value   { return value{kind: metrics.KindUint64, uint64: v} }
+func float64Value(v float64) value { return value{kind: metrics.KindFloat64, float64: v} }
+func histogramValue(h *metrics.Float64Histogram) value {
+	return value{kind: metrics.KindFloat64Histogram, histogram: h}
+}
+
+// Kind returns the kind of the value, metrics.KindBad for the zero value.
+func (v value) Kind() metrics.ValueKind { return v.kind }
+
+// Uint64 returns the value of a metrics.KindUint64 value.
+func (v value) Uint64() uint64 { return v.uint64 }
+
+// Float64 returns the value of a metrics.KindFloat64 value.
+func (v value) Float64() float64 { return v.float64 }
+
+// Float64Histogram returns the value of a metrics.KindFloat64Histogram value,
+// or an empty histogram for other kinds.
+func (v value) Float64Histogram() *metrics.Float64Histogram {
+	if v.histogram == nil {
+		return &metrics.Float64Histogram{}
+	}
+	return v.histogram
+}
+
+// runtimeSampler reads metrics from the runtime with metrics.Read. It reuses
+// its metrics.Samples, so that the runtime reuses the memory of their
+// histograms: the histograms of a read are overwritten by the next one.
+type runtimeSampler struct {
+	ms []metrics.Sample
+}
+
+func (r *runtimeSampler) read(samples []sample) {
+	if len(r.ms) != len(samples) {
+		r.ms = make([]metrics.Sample, len(samples))
+	}
+	for i := range samples {
+		if r.ms[i].Name != samples[i].name {
+			r.ms[i] = metrics.Sample{Name: samples[i].name}
+		}
+	}
+	// metrics.Read panics on an empty slice before go1.23.
+	if len(r.ms) == 0 {
+		return
+	}
+	metrics.Read(r.ms)
+	for i, s := range r.ms {
+		switch s.Value.Kind() {
+		case metrics.KindUint64:
+			samples[i].value = uint64Value(s.Value.Uint64())
+		case metrics.KindFloat64:
+			samples[i].value = float64Value(s.Value.Float64())
+		case metrics.KindFloat64Histogram:
+			samples[i].value = histogramValue(s.Value.Float64Histogram())
+		default:
+			samples[i].value = value{kind: s.Value.Kind()}
+		}
+	}
+}
@@ -0,0 +1,127 @@
+package runtimemetrics
+
+import (
+	"log/slog"
+	"math"
+	"runtime/metrics"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// fakeSampler replays scripted reads. Each read returns the next step,
+// repeating the last one once the script is exhausted. Metrics missing from a
+// step are read as metrics.KindBad.
+type fakeSampler struct {
+	steps []map[string]value
+	reads int
+}
+
+func (f *fakeSampler) read(samples []sample) {
+	step := f.steps[min(f.reads, len(f.steps)-1)]
+	f.reads++
+	for i := range samples {
+		samples[i].value = step[samples[i].name]
+	}
+}
+
+// fakeMetricDesc describes a non-cumulative metric that the oldest supported
+// Go version may not have, e.g. /sched/goroutines/running:goroutines added in
+// go1.26, for tests reading it from a fakeSampler.
+func fakeMetricDesc(name string, kind metrics.ValueKind) metrics.Description {
+	return metrics.Description{Name: name, Kind: kind}
+}
+
+// newFakeStore returns a store reading the given metric from a fakeSampler
+// replaying steps. The first step is read by the constructor as the baseline.
+func newFakeStore(name string, kind metrics.ValueKind, steps ...value) (*statsdClientMock, *runtimeMetricStore) {
+	f := &fakeSampler{}
+	for _, v := range steps {
+		f.steps = append(f.steps, map[string]value{name: v})
+	}
+	mock := &statsdClientMock{}
+	rms := newRuntimeMetricStoreWithSampler([]metrics.Description{metricDesc(name, kind)}, f, mock, &Options{Logger: slog.Default()})
+	return mock, rms
+}
+
+func TestRuntimeSampler(t *testing.T) {
+	samples := []sample{
+		{name: "/gc/gogc:percent"},
+		{name: "/sync/mutex/wait/total:seconds"},
+		{name: "/gc/pauses:seconds"},
+		{name: "/unknown:bytes"},
+	}
+	(&runtimeSampler{}).read(samples)
+	assert.Equal(t, metrics.KindUint64, samples[0].value.Kind())
+	assert.Equal(t, metrics.KindFloat64, samples[1].value.Kind())
+	assert.Equal(t, metrics.KindFloat64Histogram, samples[2].value.Kind())
+	assert.NotNil(t, samples[2].value.Float64Histogram())
+	assert.Equal(t, metrics.KindBad, samples[3].value.Kind())
+}
+
+func TestFakeSampler(t *testing.T) {
+	t.Run("cumulative deltas skip unchanged values", func(t *testing.T) {
+		mock, rms := newFakeStore("/gc/cycles/total:gc-cycles", metrics.KindUint64,
+			uint64Value(1), uint64Value(3), uint64Value(3), uint64Value(4))
+		rms.report()
+		rms.report()
+		rms.report()
+		assert.Equal(t, []float64{3, 4}, gaugeValues(mock, "runtime.go.metrics.gc_cycles_total.gc_cycles"))
+	})
+
+	t.Run("counter resets are reported as-is", func(t *testing.T) {
+		mock, rms := newFakeStore("/gc/cycles/total:gc-cycles", metrics.KindUint64,
+			uint64Value(10), uint64Value(12), uint64Value(2))
+		rms.report()
+		rms.report()
+		assert.Equal(t, []float64{12, 2}, gaugeValues(mock, "runtime.go.metrics.gc_cycles_total.gc_cycles"))
+	})
+
+	t.Run("KindBad transitions", func(t *testing.T) {
+		mock, rms := newFakeStore("/gc/cycles/total:gc-cycles", metrics.KindUint64,
+			uint64Value(1), value{}, uint64Value(5))
+		require.NotPanics(t, rms.report)
+		require.NotPanics(t, rms.report)
+		assert.Equal(t, []float64{5}, gaugeValues(mock, "runtime.go.metrics.gc_cycles_total.gc_cycles"))
+	})
+
+	t.Run("absurd values are skipped", func(t *testing.T) {
+		mock, rms := newFakeStore("/gc/heap/goal:bytes", metrics.KindUint64,
+			uint64Value(1), uint64Value(math.MaxUint64-1))
+		rms.report()
+		assert.Empty(t, gaugeValues(mock, "runtime.go.metrics.gc_heap_goal.bytes"))
+		require.Len(t, mock.CountCalls(), 1)
+		assert.Equal(t, "runtime.go.metrics.skipped_values", mock.CountCalls()[0].Name)
+	})
+
+	t.Run("histograms", func(t *testing.T) {
+		buckets := []float64{math.Inf(-1), 0, 1, math.Inf(1)}
+		hist := func(counts ...uint64) value {
+			return histogramValue(&metrics.Float64Histogram{Counts: counts, Buckets: buckets})
+		}
+		const name = "runtime.go.metrics.gc_pauses.seconds.max"
+
+		t.Run("only counts in the infinite buckets", func(t *testing.T) {
+			mock, rms := newFakeStore("/gc/pauses:seconds", metrics.KindFloat64Histogram,
+				hist(0, 0, 0), hist(1, 0, 2))
+			rms.report()
+			assert.Equal(t, []float64{1}, gaugeValues(mock, name))
+		})
+
+		t.Run("unchanged histograms are skipped", func(t *testing.T) {
+			mock, rms := newFakeStore("/gc/pauses:seconds", metrics.KindFloat64Histogram,
+				hist(0, 1, 0), hist(0, 1, 0))
+			rms.report()
+			assert.Empty(t, mock.GaugeCalls())
+			assert.Empty(t, mock.DistributionCalls())
+		})
+
+		t.Run("missing baseline", func(t *testing.T) {
+			mock, rms := newFakeStore("/gc/pauses:seconds", metrics.KindFloat64Histogram,
+				value{}, hist(0, 2, 0))
+			require.NotPanics(t, rms.report)
+			assert.Equal(t, []float64{1}, gaugeValues(mock, name))
+		})
+	})
+}
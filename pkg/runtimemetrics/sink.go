@@ -0,0 +1,58 @@
+package runtimemetrics
+
+import (
+	"runtime/metrics"
+	"time"
+)
+
+// Sink receives the metrics reported by an emitter, for backends that don't
+// speak statsd. Wrap it with NewSinkClient to pass it to NewEmitter.
+//
+// The slices and histograms of the events belong to the emitter: they must be
+// copied to be retained. Errors are ignored, like the errors of statsd
+// clients.
+type Sink interface {
+	// Gauge receives the scalar runtime metrics, the summaries of
+	// histograms, and the additional gauges.
+	Gauge(e MetricEvent) error
+	// Count receives the counts, such as Options.HistogramCounts.
+	Count(e MetricEvent) error
+	// Distribution receives the distribution samples of histograms.
+	Distribution(e MetricEvent) error
+	// Histogram receives the histograms themselves, before they are turned
+	// into distribution samples and summaries. Sinks that prefer those can
+	// ignore it.
+	Histogram(e MetricEvent) error
+}
+
+// SinkClient adapts a Sink to the statsd client interface the emitter
+// submits to, see NewSinkClient.
+type SinkClient struct {
+	sink Sink
+}
+
+// NewSinkClient returns a SinkClient submitting to sink. It can be passed to
+// NewEmitter instead of a statsd client.
+func NewSinkClient(sink Sink) *SinkClient {
+	return &SinkClient{sink: sink}
+}
+
+// GaugeWithTimestamp submits a gauge to the sink.
+func (c *SinkClient) GaugeWithTimestamp(name string, value float64, tags []string, rate float64, timestamp time.Time) error {
+	return c.sink.Gauge(MetricEvent{Kind: GaugeMetric, Name: name, Value: value, Tags: tags, Rate: rate, Timestamp: timestamp})
+}
+
+// CountWithTimestamp submits a count to the sink.
+func (c *SinkClient) CountWithTimestamp(name string, value int64, tags []string, rate float64, timestamp time.Time) error {
+	return c.sink.Count(MetricEvent{Kind: CountMetric, Name: name, Value: float64(value), Tags: tags, Rate: rate, Timestamp: timestamp})
+}
+
+// DistributionSamples submits distribution samples to the sink.
+func (c *SinkClient) DistributionSamples(name string, values []float64, tags []string, rate float64) error {
+	return c.sink.Distribution(MetricEvent{Kind: DistributionMetric, Name: name, Values: values, Tags: tags, Rate: rate})
+}
+
+// HistogramWithTimestamp submits a histogram to the sink.
+func (c *SinkClient) HistogramWithTimestamp(name string, h *metrics.Float64Histogram, tags []string, timestamp time.Time) error {
+	return c.sink.Histogram(MetricEvent{Kind: HistogramMetric, Name: name, Histogram: h, Tags: tags, Rate: 1, Timestamp: timestamp})
+}
@@ -0,0 +1,92 @@
+package runtimemetrics
+
+import (
+	"log/slog"
+	"runtime/metrics"
+	"slices"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// recordingSink is a Sink recording the names of the events by kind.
+type recordingSink struct {
+	mu         sync.Mutex
+	samples    map[string][]string
+	histograms []*metrics.Float64Histogram
+}
+
+func (s *recordingSink) record(sample MetricEvent) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.samples == nil {
+		s.samples = map[string][]string{}
+	}
+	s.samples[sample.Kind.String()] = append(s.samples[sample.Kind.String()], sample.Name)
+	if sample.Histogram != nil {
+		s.histograms = append(s.histograms, sample.Histogram)
+	}
+	return nil
+}
+
+func (s *recordingSink) Gauge(sample MetricEvent) error        { return s.record(sample) }
+func (s *recordingSink) Count(sample MetricEvent) error        { return s.record(sample) }
+func (s *recordingSink) Distribution(sample MetricEvent) error { return s.record(sample) }
+func (s *recordingSink) Histogram(sample MetricEvent) error    { return s.record(sample) }
+
+func TestSinkClient(t *testing.T) {
+	descs := []metrics.Description{
+		metricDesc("/gc/heap/live:bytes", metrics.KindUint64),
+		metricDesc("/gc/pauses:seconds", metrics.KindFloat64Histogram),
+	}
+	steps := []map[string]value{
+		{
+			"/gc/heap/live:bytes": uint64Value(1),
+			"/gc/pauses:seconds":  histogramValue(&metrics.Float64Histogram{Counts: []uint64{1, 1}, Buckets: []float64{0, 1, 2}}),
+		},
+		{
+			"/gc/heap/live:bytes": uint64Value(2),
+			"/gc/pauses:seconds":  histogramValue(&metrics.Float64Histogram{Counts: []uint64{3, 1}, Buckets: []float64{0, 1, 2}}),
+		},
+	}
+
+	for _, tt := range []struct {
+		name string
+		opts Options
+	}{
+		{name: "sync"},
+		{name: "async", opts: Options{SubmitTimeout: time.Second}},
+	} {
+		t.Run("should receive all kinds/"+tt.name, func(t *testing.T) {
+			sink := &recordingSink{}
+			opts := tt.opts
+			opts.Logger = slog.Default()
+			opts.HistogramCounts = true
+			rms := newRuntimeMetricStoreWithSampler(descs, &fakeSampler{steps: steps}, NewSinkClient(sink), &opts)
+			defer rms.close()
+			rms.report()
+
+			sink.mu.Lock()
+			defer sink.mu.Unlock()
+			assert.Contains(t, sink.samples["gauge"], "runtime.go.metrics.gc_heap_live.bytes")
+			assert.Contains(t, sink.samples["gauge"], "runtime.go.metrics.gc_pauses.seconds.p99")
+			assert.Equal(t, []string{"runtime.go.metrics.gc_pauses.seconds.count"}, sink.samples["count"])
+			assert.NotEmpty(t, sink.samples["distribution"])
+			assert.Equal(t, []string{"runtime.go.metrics.gc_pauses.seconds"}, slices.Compact(sink.samples["distribution"]))
+			assert.Equal(t, []string{"runtime.go.metrics.gc_pauses.seconds"}, sink.samples["histogram"])
+			require.Len(t, sink.histograms, 1)
+			assert.Equal(t, []uint64{2, 0}, sink.histograms[0].Counts, "histograms should be deltas")
+		})
+	}
+
+	t.Run("statsd clients don't receive histograms", func(t *testing.T) {
+		mock := &statsdClientMock{}
+		rms := newRuntimeMetricStoreWithSampler(descs, &fakeSampler{steps: steps}, mock, &Options{Logger: slog.Default(), SubmitTimeout: time.Second})
+		defer rms.close()
+		assert.NotPanics(t, rms.report)
+		assert.NotEmpty(t, mock.DistributionCalls())
+	})
+}
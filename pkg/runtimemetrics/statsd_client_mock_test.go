@@ -1,63 +1,147 @@
 package runtimemetrics
 
-import "time"
+import (
+	"sync/atomic"
+	"time"
 
-// statsdClientMock is a hand-rolled mock for partialStatsdClientInterface. Not
-// using any mocking library to reduce dependencies for a future move into
-// dd-trace-go.
+	"github.com/DataDog/go-runtime-metrics-internal/pkg/runtimemetrics/runtimemetricstest"
+)
+
+// statsdClientMock is a runtimemetricstest.Recorder, so that the recorder
+// shipped to users stays maintained, that can discard calls.
 type statsdClientMock struct {
+	runtimemetricstest.Recorder
+
 	// Discard causes all calls to be discarded rather than tracked.
 	Discard bool
-
-	gaugeCall              []statsdCall[float64]
-	countCall              []statsdCall[int64]
-	distributionSampleCall []statsdCall[[]float64]
 }
 
 // GaugeWithTimestamp implements partialStatsdClientInterface.
-func (s *statsdClientMock) GaugeWithTimestamp(name string, value float64, tags []string, rate float64, _ time.Time) error {
+func (s *statsdClientMock) GaugeWithTimestamp(name string, value float64, tags []string, rate float64, timestamp time.Time) error {
 	if s.Discard {
 		return nil
 	}
-	s.gaugeCall = append(s.gaugeCall, statsdCall[float64]{
-		name:  name,
-		value: value,
-		tags:  tags,
-		rate:  rate,
-	})
-	return nil
+	return s.Recorder.GaugeWithTimestamp(name, value, tags, rate, timestamp)
 }
 
 // CountWithTimestamp implements partialStatsdClientInterface.
-func (s *statsdClientMock) CountWithTimestamp(name string, value int64, tags []string, rate float64, _ time.Time) error {
+func (s *statsdClientMock) CountWithTimestamp(name string, value int64, tags []string, rate float64, timestamp time.Time) error {
 	if s.Discard {
 		return nil
 	}
-	s.countCall = append(s.countCall, statsdCall[int64]{
-		name:  name,
-		value: value,
-		tags:  tags,
-		rate:  rate,
-	})
-	return nil
+	return s.Recorder.CountWithTimestamp(name, value, tags, rate, timestamp)
 }
 
+// DistributionSamples implements partialStatsdClientInterface.
 func (s *statsdClientMock) DistributionSamples(name string, values []float64, tags []string, rate float64) error {
 	if s.Discard {
 		return nil
 	}
-	s.distributionSampleCall = append(s.distributionSampleCall, statsdCall[[]float64]{
-		name:  name,
-		value: values,
-		tags:  tags,
-		rate:  rate,
-	})
-	return nil
-}
-
-type statsdCall[T int64 | float64 | []float64] struct {
-	name  string
-	value T
-	tags  []string
-	rate  float64
+	return s.Recorder.DistributionSamples(name, values, tags, rate)
+}
+
+// closableStatsdClientMock is a statsdClientMock that implements
+// closableStatsdClient.
+type closableStatsdClientMock struct {
+	statsdClientMock
+	closed atomic.Bool
+}
+
+// IsClosed implements closableStatsdClient.
+func (s *closableStatsdClientMock) IsClosed() bool {
+	return s.closed.Load()
+}
+
+// noOpStatsdClientMock is a statsdClientMock that implements
+// noOpStatsdClient.
+type noOpStatsdClientMock struct {
+	statsdClientMock
+}
+
+// IsNoOp implements noOpStatsdClient.
+func (s *noOpStatsdClientMock) IsNoOp() bool {
+	return true
+}
+
+// flushableStatsdClientMock is a statsdClientMock that implements
+// flushableStatsdClient.
+type flushableStatsdClientMock struct {
+	statsdClientMock
+	flushCalls atomic.Int64
+	flushErr   error
+}
+
+// Flush implements flushableStatsdClient.
+func (s *flushableStatsdClientMock) Flush() error {
+	s.flushCalls.Add(1)
+	return s.flushErr
+}
+
+// blockingStatsdClientMock is a statsdClientMock whose calls block until
+// unblock is closed.
+type blockingStatsdClientMock struct {
+	statsdClientMock
+	unblock chan struct{}
+}
+
+// GaugeWithTimestamp implements partialStatsdClientInterface.
+func (s *blockingStatsdClientMock) GaugeWithTimestamp(name string, value float64, tags []string, rate float64, timestamp time.Time) error {
+	<-s.unblock
+	return s.statsdClientMock.GaugeWithTimestamp(name, value, tags, rate, timestamp)
+}
+
+// CountWithTimestamp implements partialStatsdClientInterface.
+func (s *blockingStatsdClientMock) CountWithTimestamp(name string, value int64, tags []string, rate float64, timestamp time.Time) error {
+	<-s.unblock
+	return s.statsdClientMock.CountWithTimestamp(name, value, tags, rate, timestamp)
+}
+
+// DistributionSamples implements partialStatsdClientInterface.
+func (s *blockingStatsdClientMock) DistributionSamples(name string, values []float64, tags []string, rate float64) error {
+	<-s.unblock
+	return s.statsdClientMock.DistributionSamples(name, values, tags, rate)
+}
+
+// slowStatsdClientMock is a statsdClientMock whose gauge calls take delay.
+type slowStatsdClientMock struct {
+	statsdClientMock
+	delay time.Duration
+}
+
+// GaugeWithTimestamp implements partialStatsdClientInterface.
+func (s *slowStatsdClientMock) GaugeWithTimestamp(name string, value float64, tags []string, rate float64, timestamp time.Time) error {
+	time.Sleep(s.delay)
+	return s.statsdClientMock.GaugeWithTimestamp(name, value, tags, rate, timestamp)
+}
+
+// failingStatsdClientMock is a statsdClientMock whose calls return err while
+// failing is set, without recording them.
+type failingStatsdClientMock struct {
+	statsdClientMock
+	failing atomic.Bool
+	err     error
+}
+
+// GaugeWithTimestamp implements partialStatsdClientInterface.
+func (s *failingStatsdClientMock) GaugeWithTimestamp(name string, value float64, tags []string, rate float64, timestamp time.Time) error {
+	if s.failing.Load() {
+		return s.err
+	}
+	return s.statsdClientMock.GaugeWithTimestamp(name, value, tags, rate, timestamp)
+}
+
+// CountWithTimestamp implements partialStatsdClientInterface.
+func (s *failingStatsdClientMock) CountWithTimestamp(name string, value int64, tags []string, rate float64, timestamp time.Time) error {
+	if s.failing.Load() {
+		return s.err
+	}
+	return s.statsdClientMock.CountWithTimestamp(name, value, tags, rate, timestamp)
+}
+
+// DistributionSamples implements partialStatsdClientInterface.
+func (s *failingStatsdClientMock) DistributionSamples(name string, values []float64, tags []string, rate float64) error {
+	if s.failing.Load() {
+		return s.err
+	}
+	return s.statsdClientMock.DistributionSamples(name, values, tags, rate)
 }
@@ -0,0 +1,37 @@
+package runtimemetrics
+
+import (
+	"fmt"
+	"runtime/metrics"
+	"strings"
+)
+
+// datadogMetricPrefix is the prefix of the Datadog name of runtime metrics,
+// unless overridden by Options.SubsystemPrefixes.
+const datadogMetricPrefix = "runtime.go.metrics."
+
+// metricSubsystem returns the first segment of the path of a runtime/metrics
+// name, e.g. "gc" for /gc/heap/allocs:bytes.
+func metricSubsystem(runtimeName string) string {
+	subsystem, _, _ := strings.Cut(strings.TrimPrefix(runtimeName, "/"), "/")
+	return subsystem
+}
+
+// validateSubsystemPrefixes returns an error if prefixes maps a subsystem that
+// none of descs belongs to, or to a prefix that isn't a valid Datadog metric
+// name prefix.
+func validateSubsystemPrefixes(prefixes map[string]string, descs []metrics.Description) error {
+	subsystems := map[string]bool{}
+	for _, d := range descs {
+		subsystems[metricSubsystem(d.Name)] = true
+	}
+	for subsystem, prefix := range prefixes {
+		if !subsystems[subsystem] {
+			return fmt.Errorf("runtimemetrics: unknown subsystem %q in subsystem prefixes", subsystem)
+		}
+		if prefix == "" || datadogMetricRegex.MatchString(prefix) || !strings.HasSuffix(prefix, ".") {
+			return fmt.Errorf("runtimemetrics: invalid prefix %q for subsystem %q, it must only contain alphanumerics, underscores and periods, and end with a period", prefix, subsystem)
+		}
+	}
+	return nil
+}
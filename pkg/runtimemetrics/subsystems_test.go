@@ -0,0 +1,46 @@
+package runtimemetrics
+
+import (
+	"log/slog"
+	"runtime/metrics"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSubsystemPrefixes(t *testing.T) {
+	t.Run("gc metrics get the gc prefix and others the default", func(t *testing.T) {
+		mock := &statsdClientMock{}
+		descs := []metrics.Description{
+			metricDesc("/gc/heap/goal:bytes", metrics.KindUint64),
+			metricDesc("/sched/gomaxprocs:threads", metrics.KindUint64),
+		}
+		opts := &Options{Logger: slog.Default(), SubsystemPrefixes: map[string]string{"gc": "runtime.gc."}}
+		require.NoError(t, validateSubsystemPrefixes(opts.SubsystemPrefixes, descs))
+		rms := newRuntimeMetricStore(descs, mock, opts)
+		rms.report()
+
+		var names []string
+		for _, call := range mock.GaugeCalls() {
+			names = append(names, call.Name)
+		}
+		assert.ElementsMatch(t, []string{"runtime.gc.gc_heap_goal.bytes", "runtime.go.metrics.sched_gomaxprocs.threads"}, names)
+	})
+
+	t.Run("validation", func(t *testing.T) {
+		descs := []metrics.Description{metricDesc("/gc/heap/goal:bytes", metrics.KindUint64)}
+		assert.NoError(t, validateSubsystemPrefixes(nil, descs))
+		assert.NoError(t, validateSubsystemPrefixes(map[string]string{"gc": "runtime.gc."}, descs))
+		assert.Error(t, validateSubsystemPrefixes(map[string]string{"sched": "runtime.sched."}, descs), "unknown subsystem")
+		assert.Error(t, validateSubsystemPrefixes(map[string]string{"gc": ""}, descs), "empty prefix")
+		assert.Error(t, validateSubsystemPrefixes(map[string]string{"gc": "runtime.gc"}, descs), "missing trailing period")
+		assert.Error(t, validateSubsystemPrefixes(map[string]string{"gc": "runtime/gc."}, descs), "invalid character")
+	})
+
+	t.Run("NewEmitter rejects invalid prefixes", func(t *testing.T) {
+		e, err := NewEmitter(&statsdClientMock{}, &Options{SubsystemPrefixes: map[string]string{"nope": "runtime.nope."}})
+		assert.Error(t, err)
+		assert.Nil(t, e)
+	})
+}
@@ -4,12 +4,37 @@ import (
 	"fmt"
 	"math"
 	"runtime/metrics"
+	"slices"
+	"strings"
 )
 
 const gogcMetricName = "/gc/gogc:percent"
 const gomemlimitMetricName = "/gc/gomemlimit:bytes"
 const gomaxProcsMetricName = "/sched/gomaxprocs:threads"
 
+// gomaxprocsGaugeName is the gauge reporting GOMAXPROCS, see
+// Options.GOMAXPROCSGauge.
+const gomaxprocsGaugeName = "runtime.go.metrics.gomaxprocs"
+
+// numCPUGaugeName is the gauge reporting runtime.NumCPU(), see
+// Options.NumCPUGauge.
+const numCPUGaugeName = "runtime.go.metrics.num_cpu"
+
+// baseTagNames are the names of the tags returned by getBaseTags.
+var baseTagNames = []string{"gogc", "gomemlimit", "gomaxprocs"}
+
+// BaseTagNames returns the names of the base tags attached to all metrics,
+// e.g. to group metrics by them: gogc, gomemlimit and gomaxprocs.
+func BaseTagNames() []string {
+	return slices.Clone(baseTagNames)
+}
+
+// readBaseTagSamples is a variable so tests can simulate runtime metrics
+// that can't be read.
+var readBaseTagSamples = metrics.Read
+
+// getBaseTags returns the tags attached to all metrics. Tags whose runtime
+// metric can't be read are omitted, see validateBaseTags.
 func getBaseTags() []string {
 	samples := []metrics.Sample{
 		{Name: gogcMetricName},
@@ -19,9 +44,12 @@ func getBaseTags() []string {
 
 	baseTags := make([]string, 0, len(samples))
 
-	metrics.Read(samples)
+	readBaseTagSamples(samples)
 
 	for _, s := range samples {
+		if s.Value.Kind() != metrics.KindUint64 {
+			continue
+		}
 		switch s.Name {
 		case gogcMetricName:
 			gogc := s.Value.Uint64()
@@ -51,6 +79,29 @@ func getBaseTags() []string {
 	return baseTags
 }
 
+// validateBaseTags returns an error if any of the baseTagNames is missing
+// from tags or has an empty value.
+func validateBaseTags(tags []string) error {
+	for _, name := range baseTagNames {
+		found := false
+		for _, tag := range tags {
+			value, ok := strings.CutPrefix(tag, name+":")
+			if !ok {
+				continue
+			}
+			if value == "" {
+				return fmt.Errorf("runtimemetrics: base tag %s has an empty value", name)
+			}
+			found = true
+			break
+		}
+		if !found {
+			return fmt.Errorf("runtimemetrics: base tag %s could not be computed", name)
+		}
+	}
+	return nil
+}
+
 // Function to format byte size with the right unit
 func formatByteSize(bytes uint64) string {
 	const (
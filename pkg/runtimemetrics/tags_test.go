@@ -2,13 +2,16 @@ package runtimemetrics
 
 import (
 	"fmt"
+	"log/slog"
 	"math"
 	"runtime"
 	"runtime/debug"
+	"runtime/metrics"
 	"strings"
 	"testing"
 
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 )
 
 func assertTagValue(t *testing.T, tagName, expectedTagValue string, actualTags []string) {
@@ -103,6 +106,50 @@ func TestGetBaseTags(t *testing.T) {
 	})
 }
 
+func TestGOMAXPROCSGauge(t *testing.T) {
+	gauges := func(mock *statsdClientMock) []float64 {
+		var values []float64
+		for _, c := range mock.GaugeCalls() {
+			if c.Name == gomaxprocsGaugeName {
+				values = append(values, c.Value)
+			}
+		}
+		return values
+	}
+
+	t.Run("should report the live value", func(t *testing.T) {
+		old := runtime.GOMAXPROCS(2)
+		defer runtime.GOMAXPROCS(old)
+
+		mock := &statsdClientMock{}
+		rms := newRuntimeMetricStore([]metrics.Description{}, mock, &Options{Logger: slog.Default(), GOMAXPROCSGauge: true})
+		rms.report()
+		runtime.GOMAXPROCS(3)
+		rms.report()
+
+		assert.Equal(t, []float64{2, 3}, gauges(mock))
+		assertTagValue(t, "gomaxprocs", "2", mock.GaugeCalls()[1].Tags)
+	})
+
+	t.Run("should not be reported by default", func(t *testing.T) {
+		mock := &statsdClientMock{}
+		rms := newRuntimeMetricStore([]metrics.Description{}, mock, &Options{Logger: slog.Default()})
+		rms.report()
+		assert.Empty(t, gauges(mock))
+	})
+}
+
+func TestBaseTagNames(t *testing.T) {
+	names := BaseTagNames()
+	require.NoError(t, validateBaseTags(getBaseTags()))
+	for _, tag := range getBaseTags() {
+		name, _, _ := strings.Cut(tag, ":")
+		assert.Contains(t, names, name)
+	}
+	names[0] = "modified"
+	assert.NotEqual(t, names, BaseTagNames())
+}
+
 func TestFormatByteSize(t *testing.T) {
 	t.Run("should format byte size correctly", func(t *testing.T) {
 		tests := []struct {
@@ -126,3 +173,64 @@ func TestFormatByteSize(t *testing.T) {
 		}
 	})
 }
+
+func TestStrictTags(t *testing.T) {
+	// Simulate a runtime that doesn't support /gc/gomemlimit:bytes
+	old := readBaseTagSamples
+	readBaseTagSamples = func(samples []metrics.Sample) {
+		metrics.Read(samples)
+		for i := range samples {
+			if samples[i].Name == gomemlimitMetricName {
+				samples[i].Value = metrics.Value{}
+			}
+		}
+	}
+	t.Cleanup(func() { readBaseTagSamples = old })
+
+	t.Run("should omit the missing tag", func(t *testing.T) {
+		tags := getBaseTags()
+		assert.Len(t, tags, 2)
+		assert.EqualError(t, validateBaseTags(tags), "runtimemetrics: base tag gomemlimit could not be computed")
+	})
+
+	t.Run("should return an error in strict mode", func(t *testing.T) {
+		e, err := NewEmitter(&statsdClientMock{}, &Options{StrictTags: true})
+		assert.Error(t, err)
+		assert.Nil(t, e)
+	})
+
+	t.Run("should tolerate the missing tag by default", func(t *testing.T) {
+		e, err := NewEmitter(&statsdClientMock{}, nil)
+		require.NoError(t, err)
+		assert.EqualError(t, e.LastError(), "runtimemetrics: base tag gomemlimit could not be computed")
+		e.Stop()
+	})
+}
+
+func TestValidateBaseTags(t *testing.T) {
+	assert.NoError(t, validateBaseTags(getBaseTags()))
+	assert.Error(t, validateBaseTags([]string{"gogc:100", "gomemlimit:", "gomaxprocs:8"}))
+}
+
+func TestNumCPUGauge(t *testing.T) {
+	t.Run("should report runtime.NumCPU()", func(t *testing.T) {
+		mock := &statsdClientMock{}
+		rms := newRuntimeMetricStore([]metrics.Description{}, mock, &Options{Logger: slog.Default(), NumCPUGauge: true})
+		rms.report()
+
+		calls := mock.GaugeCalls()
+		require.Len(t, calls, 1)
+		assert.Equal(t, numCPUGaugeName, calls[0].Name)
+		assert.Equal(t, float64(runtime.NumCPU()), calls[0].Value)
+		assert.Equal(t, rms.baseTags, calls[0].Tags)
+	})
+
+	t.Run("should not be reported by default", func(t *testing.T) {
+		mock := &statsdClientMock{}
+		rms := newRuntimeMetricStore([]metrics.Description{}, mock, &Options{Logger: slog.Default()})
+		rms.report()
+		for _, c := range mock.GaugeCalls() {
+			assert.NotEqual(t, numCPUGaugeName, c.Name)
+		}
+	})
+}
@@ -0,0 +1,253 @@
+package runtimemetrics
+
+import (
+	"errors"
+	"fmt"
+	"net"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// maxUDPPacketSize is the maximum size of the packets sent by UDPClient, the
+// default of the datadog-go client for UDP, which avoids fragmentation on
+// common networks.
+const maxUDPPacketSize = 1432
+
+// errUDPClientClosed is returned by the methods of a closed UDPClient.
+var errUDPClientClosed = errors.New("runtimemetrics: the UDP statsd client is closed")
+
+// StatsdDialect is the variant of the statsd protocol spoken by a
+// UDPClient.
+type StatsdDialect int
+
+const (
+	// DogStatsD is the protocol of the Datadog agent, with tags, timestamps
+	// and distributions, e.g. name:1|g|#gogc:100|T1700000000.
+	DogStatsD StatsdDialect = iota
+	// StatsdInfluxTags is plain statsd, with the tags encoded as InfluxDB
+	// style suffixes of the name as parsed by Telegraf, e.g.
+	// name,gogc=100:1|g. See Statsd for timestamps and distributions.
+	StatsdInfluxTags
+	// Statsd is plain statsd, e.g. for graphite stacks. Tags and timestamps
+	// are dropped, and distribution samples are submitted as timings, one
+	// message per sample, in the unit of the metric, e.g. name:0.25|ms.
+	Statsd
+)
+
+// UDPClient is a minimal dogstatsd client submitting metrics over UDP, for
+// applications that don't otherwise depend on the datadog-go client. It can
+// be passed to NewEmitter, see also NewEmitterWithStatsdAddr.
+//
+// Metrics are buffered into packets of up to 1432 bytes, which are sent once
+// full or when Flush is called.
+type UDPClient struct {
+	mu      sync.Mutex
+	conn    net.Conn
+	dialect StatsdDialect
+	buf     []byte
+	closed  bool
+}
+
+// NewUDPClient returns a UDPClient sending to the dogstatsd server at addr,
+// e.g. "localhost:8125".
+func NewUDPClient(addr string) (*UDPClient, error) {
+	return NewUDPClientWithDialect(addr, DogStatsD)
+}
+
+// NewUDPClientWithDialect returns a UDPClient sending to the statsd server
+// at addr, e.g. "localhost:8125", in the given dialect.
+func NewUDPClientWithDialect(addr string, dialect StatsdDialect) (*UDPClient, error) {
+	if dialect < DogStatsD || dialect > Statsd {
+		return nil, fmt.Errorf("runtimemetrics: unknown statsd dialect %d", dialect)
+	}
+	conn, err := net.Dial("udp", addr)
+	if err != nil {
+		return nil, err
+	}
+	return &UDPClient{conn: conn, dialect: dialect, buf: make([]byte, 0, maxUDPPacketSize)}, nil
+}
+
+// GaugeWithTimestamp submits a gauge. Plain statsd reads a signed gauge as
+// an adjustment of its current value, so in the dialects other than
+// DogStatsD a negative value is submitted after setting the gauge to 0.
+func (c *UDPClient) GaugeWithTimestamp(name string, value float64, tags []string, rate float64, timestamp time.Time) error {
+	if value < 0 && c.dialect != DogStatsD {
+		if err := c.submit(name, "0", "g", tags, rate, timestamp); err != nil {
+			return err
+		}
+	}
+	return c.submit(name, strconv.FormatFloat(value, 'f', -1, 64), "g", tags, rate, timestamp)
+}
+
+// CountWithTimestamp submits a count.
+func (c *UDPClient) CountWithTimestamp(name string, value int64, tags []string, rate float64, timestamp time.Time) error {
+	return c.submit(name, strconv.FormatInt(value, 10), "c", tags, rate, timestamp)
+}
+
+// DistributionSamples submits distribution samples, packing as many as fit
+// in a packet into each message, like the datadog-go client, unless the
+// dialect has no distributions, see Statsd.
+func (c *UDPClient) DistributionSamples(name string, values []float64, tags []string, rate float64) error {
+	if c.dialect != DogStatsD {
+		for _, v := range values {
+			if err := c.submit(name, strconv.FormatFloat(v, 'f', -1, 64), "ms", tags, rate, time.Time{}); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+	// The message without values, to which each value adds its length and
+	// a separator.
+	overhead := len(c.message(name, "", "d", tags, rate, time.Time{}))
+	var b strings.Builder
+	for _, v := range values {
+		value := strconv.FormatFloat(v, 'f', -1, 64)
+		if b.Len() > 0 && overhead+b.Len()+1+len(value) > maxUDPPacketSize {
+			if err := c.submit(name, b.String(), "d", tags, rate, time.Time{}); err != nil {
+				return err
+			}
+			b.Reset()
+		}
+		if b.Len() > 0 {
+			b.WriteByte(':')
+		}
+		b.WriteString(value)
+	}
+	if b.Len() == 0 {
+		return nil
+	}
+	return c.submit(name, b.String(), "d", tags, rate, time.Time{})
+}
+
+// submit buffers a statsd message, see message.
+func (c *UDPClient) submit(name, value, metricType string, tags []string, rate float64, timestamp time.Time) error {
+	return c.buffer(c.message(name, value, metricType, tags, rate, timestamp))
+}
+
+// message returns a statsd message in the dialect of c, e.g.
+// name:1|g|@0.5|#tag:a|T1700000000 in the DogStatsD dialect.
+func (c *UDPClient) message(name, value, metricType string, tags []string, rate float64, timestamp time.Time) string {
+	var b strings.Builder
+	b.WriteString(name)
+	if c.dialect == StatsdInfluxTags {
+		for _, tag := range tags {
+			k, v, ok := strings.Cut(tag, ":")
+			if !ok {
+				// Like InfluxWriter, valueless tags become booleans.
+				v = "true"
+			}
+			if k == "" || v == "" {
+				continue
+			}
+			b.WriteByte(',')
+			b.WriteString(statsdTagEscaper.Replace(k))
+			b.WriteByte('=')
+			b.WriteString(statsdTagEscaper.Replace(v))
+		}
+	}
+	b.WriteByte(':')
+	b.WriteString(value)
+	b.WriteByte('|')
+	b.WriteString(metricType)
+	if rate != 1 {
+		b.WriteString("|@")
+		b.WriteString(strconv.FormatFloat(rate, 'f', -1, 64))
+	}
+	if c.dialect != DogStatsD {
+		return b.String()
+	}
+	if len(tags) > 0 {
+		b.WriteString("|#")
+		b.WriteString(strings.Join(tags, ","))
+	}
+	if !timestamp.IsZero() {
+		b.WriteString("|T")
+		b.WriteString(strconv.FormatInt(timestamp.Unix(), 10))
+	}
+	return b.String()
+}
+
+// buffer adds msg to the packet being buffered, sending it first if msg
+// doesn't fit.
+func (c *UDPClient) buffer(msg string) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.closed {
+		return errUDPClientClosed
+	}
+	if len(c.buf) > 0 && len(c.buf)+1+len(msg) > maxUDPPacketSize {
+		if err := c.flushLocked(); err != nil {
+			return err
+		}
+	}
+	if len(c.buf) > 0 {
+		c.buf = append(c.buf, '\n')
+	}
+	c.buf = append(c.buf, msg...)
+	return nil
+}
+
+// statsdTagEscaper replaces the characters of tags that are special in the
+// StatsdInfluxTags dialect.
+var statsdTagEscaper = strings.NewReplacer(",", "_", "=", "_", ":", "_", "|", "_", " ", "_")
+
+// Flush sends the buffered metrics.
+func (c *UDPClient) Flush() error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.closed {
+		return errUDPClientClosed
+	}
+	return c.flushLocked()
+}
+
+func (c *UDPClient) flushLocked() error {
+	if len(c.buf) == 0 {
+		return nil
+	}
+	_, err := c.conn.Write(c.buf)
+	c.buf = c.buf[:0]
+	return err
+}
+
+// Close flushes the buffered metrics and closes the connection.
+func (c *UDPClient) Close() error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.closed {
+		return nil
+	}
+	c.closed = true
+	return errors.Join(c.flushLocked(), c.conn.Close())
+}
+
+// IsClosed returns true once Close has been called.
+func (c *UDPClient) IsClosed() bool {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.closed
+}
+
+// NewEmitterWithStatsdAddr is NewEmitter with a UDPClient sending to the
+// dogstatsd server at addr, e.g. "localhost:8125". The client is flushed
+// after each report, and closed when the emitter is stopped.
+func NewEmitterWithStatsdAddr(addr string, opts *Options) (*Emitter, error) {
+	client, err := NewUDPClient(addr)
+	if err != nil {
+		return nil, err
+	}
+	var o Options
+	if opts != nil {
+		o = *opts
+	}
+	o.FlushClient = true
+	e, err := NewEmitter(client, &o)
+	if err != nil {
+		client.Close()
+		return nil, err
+	}
+	e.ownedClient = client
+	return e, nil
+}
@@ -0,0 +1,195 @@
+package runtimemetrics
+
+import (
+	"net"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// listenUDP returns a local UDP listener, closed at the end of the test.
+func listenUDP(t *testing.T) net.PacketConn {
+	l, err := net.ListenPacket("udp", "127.0.0.1:0")
+	require.NoError(t, err)
+	t.Cleanup(func() { l.Close() })
+	return l
+}
+
+// readPacket returns the next packet received by l.
+func readPacket(t *testing.T, l net.PacketConn) string {
+	require.NoError(t, l.SetReadDeadline(time.Now().Add(5*time.Second)))
+	buf := make([]byte, 65536)
+	n, _, err := l.ReadFrom(buf)
+	require.NoError(t, err)
+	return string(buf[:n])
+}
+
+func TestUDPClient(t *testing.T) {
+	t.Run("should send dogstatsd messages", func(t *testing.T) {
+		l := listenUDP(t)
+		c, err := NewUDPClient(l.LocalAddr().String())
+		require.NoError(t, err)
+		defer c.Close()
+
+		ts := time.Unix(1700000000, 0)
+		require.NoError(t, c.GaugeWithTimestamp("a.gauge", 1.5, []string{"gogc:100", "env:test"}, 1, ts))
+		require.NoError(t, c.CountWithTimestamp("a.count", 3, nil, 1, ts))
+		require.NoError(t, c.DistributionSamples("a.distribution", []float64{0.25, 2}, []string{"gogc:100"}, 0.5))
+		require.NoError(t, c.Flush())
+
+		assert.Equal(t, strings.Join([]string{
+			"a.gauge:1.5|g|#gogc:100,env:test|T1700000000",
+			"a.count:3|c|T1700000000",
+			"a.distribution:0.25:2|d|@0.5|#gogc:100",
+		}, "\n"), readPacket(t, l))
+	})
+
+	t.Run("should split packets", func(t *testing.T) {
+		l := listenUDP(t)
+		c, err := NewUDPClient(l.LocalAddr().String())
+		require.NoError(t, err)
+		defer c.Close()
+
+		name := strings.Repeat("a", maxUDPPacketSize/2)
+		require.NoError(t, c.GaugeWithTimestamp(name, 1, nil, 1, time.Time{}))
+		require.NoError(t, c.GaugeWithTimestamp(name, 2, nil, 1, time.Time{}))
+		require.NoError(t, c.Flush())
+		assert.Equal(t, name+":1|g", readPacket(t, l))
+		assert.Equal(t, name+":2|g", readPacket(t, l))
+	})
+
+	t.Run("should split distribution samples into packets", func(t *testing.T) {
+		l := listenUDP(t)
+		c, err := NewUDPClient(l.LocalAddr().String())
+		require.NoError(t, err)
+		defer c.Close()
+
+		values := make([]float64, 1000)
+		for i := range values {
+			values[i] = float64(i) + 0.5
+		}
+		require.NoError(t, c.DistributionSamples("a.distribution", values, []string{"gogc:100"}, 1))
+		require.NoError(t, c.Flush())
+
+		var got []string
+		for len(got) < len(values) {
+			packet := readPacket(t, l)
+			assert.LessOrEqual(t, len(packet), maxUDPPacketSize)
+			for _, msg := range strings.Split(packet, "\n") {
+				samples, ok := strings.CutPrefix(msg, "a.distribution:")
+				require.True(t, ok, msg)
+				samples, ok = strings.CutSuffix(samples, "|d|#gogc:100")
+				require.True(t, ok, msg)
+				got = append(got, strings.Split(samples, ":")...)
+			}
+		}
+		require.Len(t, got, len(values))
+		for i, v := range got {
+			assert.Equal(t, strconv.FormatFloat(values[i], 'f', -1, 64), v)
+		}
+	})
+
+	t.Run("should flush on close", func(t *testing.T) {
+		l := listenUDP(t)
+		c, err := NewUDPClient(l.LocalAddr().String())
+		require.NoError(t, err)
+		require.NoError(t, c.CountWithTimestamp("a.count", 1, nil, 1, time.Time{}))
+		assert.False(t, c.IsClosed())
+		require.NoError(t, c.Close())
+		assert.True(t, c.IsClosed())
+		assert.Equal(t, "a.count:1|c", readPacket(t, l))
+		assert.ErrorIs(t, c.CountWithTimestamp("a.count", 1, nil, 1, time.Time{}), errUDPClientClosed)
+		assert.NoError(t, c.Close())
+	})
+}
+
+func TestUDPClientDialects(t *testing.T) {
+	for _, tt := range []struct {
+		name    string
+		dialect StatsdDialect
+		want    []string
+	}{
+		{
+			name:    "DogStatsD",
+			dialect: DogStatsD,
+			want: []string{
+				"a.gauge:1.5|g|#gogc:100,class:heap/free,valueless|T1700000000",
+				"a.delta:-5|g|T1700000000",
+				"a.count:3|c|T1700000000",
+				"a.distribution:0.25:2|d|@0.5|#gogc:100",
+			},
+		},
+		{
+			name:    "StatsdInfluxTags",
+			dialect: StatsdInfluxTags,
+			want: []string{
+				"a.gauge,gogc=100,class=heap/free,valueless=true:1.5|g",
+				"a.delta:0|g",
+				"a.delta:-5|g",
+				"a.count:3|c",
+				"a.distribution,gogc=100:0.25|ms|@0.5",
+				"a.distribution,gogc=100:2|ms|@0.5",
+			},
+		},
+		{
+			name:    "Statsd",
+			dialect: Statsd,
+			want: []string{
+				"a.gauge:1.5|g",
+				"a.delta:0|g",
+				"a.delta:-5|g",
+				"a.count:3|c",
+				"a.distribution:0.25|ms|@0.5",
+				"a.distribution:2|ms|@0.5",
+			},
+		},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			l := listenUDP(t)
+			c, err := NewUDPClientWithDialect(l.LocalAddr().String(), tt.dialect)
+			require.NoError(t, err)
+			defer c.Close()
+
+			ts := time.Unix(1700000000, 0)
+			require.NoError(t, c.GaugeWithTimestamp("a.gauge", 1.5, []string{"gogc:100", "class:heap/free", "valueless"}, 1, ts))
+			require.NoError(t, c.GaugeWithTimestamp("a.delta", -5, nil, 1, ts))
+			require.NoError(t, c.CountWithTimestamp("a.count", 3, nil, 1, ts))
+			require.NoError(t, c.DistributionSamples("a.distribution", []float64{0.25, 2}, []string{"gogc:100"}, 0.5))
+			require.NoError(t, c.Flush())
+			assert.Equal(t, strings.Join(tt.want, "\n"), readPacket(t, l))
+		})
+	}
+
+	t.Run("should escape tags", func(t *testing.T) {
+		l := listenUDP(t)
+		c, err := NewUDPClientWithDialect(l.LocalAddr().String(), StatsdInfluxTags)
+		require.NoError(t, err)
+		defer c.Close()
+		require.NoError(t, c.GaugeWithTimestamp("a", 1, []string{"k,=|:v a:b"}, 1, time.Time{}))
+		require.NoError(t, c.Flush())
+		assert.Equal(t, "a,k___=v_a_b:1|g", readPacket(t, l))
+	})
+
+	t.Run("should reject unknown dialects", func(t *testing.T) {
+		_, err := NewUDPClientWithDialect("127.0.0.1:8125", StatsdDialect(42))
+		assert.Error(t, err)
+	})
+}
+
+func TestNewEmitterWithStatsdAddr(t *testing.T) {
+	l := listenUDP(t)
+	e, err := NewEmitterWithStatsdAddr(l.LocalAddr().String(), &Options{Period: MinPeriod})
+	require.NoError(t, err)
+	packet := readPacket(t, l)
+	e.Stop()
+
+	assert.Contains(t, packet, "runtime.go.metrics.")
+	assert.True(t, e.ownedClient.(*UDPClient).IsClosed(), "the client should be closed with the emitter")
+
+	_, err = NewEmitterWithStatsdAddr("not an address", nil)
+	assert.Error(t, err)
+}
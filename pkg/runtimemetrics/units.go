@@ -0,0 +1,95 @@
+package runtimemetrics
+
+import (
+	"fmt"
+	"runtime/metrics"
+	"slices"
+	"strings"
+)
+
+// durationUnitScales are the units supported by Options.DurationUnit, with
+// the factor converting seconds to them.
+var durationUnitScales = map[string]float64{
+	"seconds":      1,
+	"milliseconds": 1e3,
+	"nanoseconds":  1e9,
+}
+
+// validateDurationUnit returns an error if unit isn't supported by
+// Options.DurationUnit.
+func validateDurationUnit(unit string) error {
+	if _, ok := durationUnitScales[unit]; !ok && unit != "" {
+		return fmt.Errorf("runtimemetrics: unsupported duration unit %q", unit)
+	}
+	return nil
+}
+
+// durationUnit returns the Datadog name of a metric reported in the given
+// duration unit, and the factor to apply to its values. Only metrics in
+// seconds are converted.
+func durationUnit(runtimeName, ddMetricName, unit string) (string, float64) {
+	if unit == "" || unit == "seconds" || !strings.HasSuffix(runtimeName, ":seconds") {
+		return ddMetricName, 1
+	}
+	return replaceUnitSuffix(ddMetricName, "seconds", unit), durationUnitScales[unit]
+}
+
+// replaceUnitSuffix replaces the unit suffix of a Datadog name with newUnit,
+// keeping its separator, see Options.NameSeparator. Names without the suffix,
+// e.g. aliases, get newUnit appended.
+func replaceUnitSuffix(ddMetricName, unit, newUnit string) string {
+	if base, ok := strings.CutSuffix(ddMetricName, "_"+unit); ok {
+		return base + "_" + newUnit
+	}
+	return strings.TrimSuffix(ddMetricName, "."+unit) + "." + newUnit
+}
+
+// unitScaleSuffixes are the runtime units supported by Options.UnitScale,
+// with the scales they support and the unit replacing theirs in the names of
+// the scaled metrics.
+var unitScaleSuffixes = map[string]map[float64]string{
+	"bytes": {
+		1.0 / (1 << 10): "kibibytes",
+		1.0 / (1 << 20): "mebibytes",
+		1.0 / (1 << 30): "gibibytes",
+		1e-3:            "kilobytes",
+		1e-6:            "megabytes",
+		1e-9:            "gigabytes",
+	},
+}
+
+// validateUnitScale returns an error if scales has units or scales that
+// aren't supported by Options.UnitScale.
+func validateUnitScale(scales map[string]float64) error {
+	for unit, scale := range scales {
+		suffixes, ok := unitScaleSuffixes[unit]
+		if !ok {
+			return fmt.Errorf("runtimemetrics: unsupported unit %q in unit scales", unit)
+		}
+		if _, ok := suffixes[scale]; !ok && scale != 1 {
+			return fmt.Errorf("runtimemetrics: unsupported scale %v for unit %q", scale, unit)
+		}
+	}
+	return nil
+}
+
+// unitScale returns the Datadog name of a metric whose unit is scaled by
+// scales, see Options.UnitScale, and the factor to apply to its values.
+func unitScale(runtimeName, ddMetricName string, scales map[string]float64) (string, float64) {
+	_, unit, _ := strings.Cut(runtimeName, ":")
+	suffix, ok := unitScaleSuffixes[unit][scales[unit]]
+	if !ok {
+		return ddMetricName, 1
+	}
+	return replaceUnitSuffix(ddMetricName, unit, suffix), scales[unit]
+}
+
+// scaleHistogram sets dst to h with its bucket boundaries multiplied by
+// scale. The counts are shared, the buckets of dst are reused.
+func scaleHistogram(dst, h *metrics.Float64Histogram, scale float64) {
+	dst.Counts = h.Counts
+	dst.Buckets = slices.Grow(dst.Buckets[:0], len(h.Buckets))[:len(h.Buckets)]
+	for i, b := range h.Buckets {
+		dst.Buckets[i] = b * scale
+	}
+}
@@ -0,0 +1,119 @@
+package runtimemetrics
+
+import (
+	"log/slog"
+	"math"
+	"runtime/metrics"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDurationUnit(t *testing.T) {
+	t.Run("should convert float64 metrics", func(t *testing.T) {
+		f := &fakeSampler{steps: []map[string]value{
+			{"/sync/mutex/wait/total:seconds": float64Value(0)},
+			{"/sync/mutex/wait/total:seconds": float64Value(0.25)},
+		}}
+		desc := metricDesc("/sync/mutex/wait/total:seconds", metrics.KindFloat64)
+		mock := &statsdClientMock{}
+		rms := newRuntimeMetricStoreWithSampler([]metrics.Description{desc}, f, mock, &Options{Logger: slog.Default(), DurationUnit: "milliseconds"})
+		rms.report()
+		assert.Equal(t, []float64{250}, gaugeValues(mock, "runtime.go.metrics.sync_mutex_wait_total.milliseconds"))
+	})
+
+	t.Run("should convert histogram summaries and distributions", func(t *testing.T) {
+		buckets := []float64{math.Inf(-1), 0, 0.001, 0.002, math.Inf(1)}
+		f := &fakeSampler{steps: []map[string]value{
+			{"/gc/pauses:seconds": histogramValue(&metrics.Float64Histogram{Counts: []uint64{0, 0, 0, 0}, Buckets: buckets})},
+			{"/gc/pauses:seconds": histogramValue(&metrics.Float64Histogram{Counts: []uint64{0, 0, 2, 0}, Buckets: buckets})},
+		}}
+		desc := metricDesc("/gc/pauses:seconds", metrics.KindFloat64Histogram)
+		mock := &statsdClientMock{}
+		rms := newRuntimeMetricStoreWithSampler([]metrics.Description{desc}, f, mock, &Options{Logger: slog.Default(), DurationUnit: "nanoseconds"})
+		rms.report()
+
+		assert.Equal(t, []float64{2e6}, gaugeValues(mock, "runtime.go.metrics.gc_pauses.nanoseconds.max"))
+		dists := mock.DistributionCalls()
+		require.Len(t, dists, 1)
+		assert.Equal(t, "runtime.go.metrics.gc_pauses.nanoseconds", dists[0].Name)
+		assert.Equal(t, []float64{1.5e6}, dists[0].Value)
+		// The raw values are kept, e.g. for legacy metrics.
+		assert.Equal(t, 0.002, rms.metrics["/gc/pauses:seconds"].currentValue.Float64Histogram().Buckets[3])
+	})
+
+	t.Run("should not convert other units", func(t *testing.T) {
+		name, scale := durationUnit("/cpu/classes/gc/total:cpu-seconds", "runtime.go.metrics.cpu_classes_gc_total.cpu_seconds", "milliseconds")
+		assert.Equal(t, "runtime.go.metrics.cpu_classes_gc_total.cpu_seconds", name)
+		assert.Equal(t, 1.0, scale)
+	})
+
+	t.Run("should rename aliased metrics", func(t *testing.T) {
+		name, scale := durationUnit("/sched/pauses/total/gc:seconds", "runtime.go.metrics.gc_pauses.seconds", "milliseconds")
+		assert.Equal(t, "runtime.go.metrics.gc_pauses.milliseconds", name)
+		assert.Equal(t, 1e3, scale)
+	})
+
+	t.Run("should keep the name separator", func(t *testing.T) {
+		name, _ := durationUnit("/gc/pauses:seconds", "runtime.go.metrics.gc_pauses_seconds", "milliseconds")
+		assert.Equal(t, "runtime.go.metrics.gc_pauses_milliseconds", name)
+	})
+
+	t.Run("should reject unsupported units", func(t *testing.T) {
+		assert.NoError(t, validateDurationUnit(""))
+		assert.NoError(t, validateDurationUnit("seconds"))
+		e, err := NewEmitter(&statsdClientMock{}, &Options{DurationUnit: "minutes"})
+		assert.Error(t, err)
+		assert.Nil(t, e)
+	})
+}
+
+func TestUnitScale(t *testing.T) {
+	const mib = 1 << 20
+
+	t.Run("should scale byte gauges and rename their unit", func(t *testing.T) {
+		f := &fakeSampler{steps: []map[string]value{
+			{"/gc/heap/live:bytes": uint64Value(mib), "/gc/heap/allocs:bytes": uint64Value(mib)},
+			{"/gc/heap/live:bytes": uint64Value(3 * mib), "/gc/heap/allocs:bytes": uint64Value(mib / 2 * 5)},
+		}}
+		descs := []metrics.Description{
+			metricDesc("/gc/heap/live:bytes", metrics.KindUint64),
+			metricDesc("/gc/heap/allocs:bytes", metrics.KindUint64),
+		}
+		mock := &statsdClientMock{}
+		rms := newRuntimeMetricStoreWithSampler(descs, f, mock, &Options{Logger: slog.Default(), UnitScale: map[string]float64{"bytes": 1.0 / 1048576}})
+		rms.report()
+		assert.Equal(t, []float64{3}, gaugeValues(mock, "runtime.go.metrics.gc_heap_live.mebibytes"))
+		assert.Equal(t, []float64{2.5}, gaugeValues(mock, "runtime.go.metrics.gc_heap_allocs.mebibytes"))
+		assert.Empty(t, mock.CallsWithSuffix(".bytes").Gauges)
+		// The values are kept unscaled.
+		assert.Equal(t, uint64(mib/2*5), rms.metrics["/gc/heap/allocs:bytes"].currentValue.Uint64())
+	})
+
+	t.Run("should rename the unit with the name separator", func(t *testing.T) {
+		name, scale := unitScale("/gc/heap/live:bytes", "runtime.go.metrics.gc_heap_live_bytes", map[string]float64{"bytes": 1e-3})
+		assert.Equal(t, "runtime.go.metrics.gc_heap_live_kilobytes", name)
+		assert.Equal(t, 1e-3, scale)
+	})
+
+	t.Run("should not scale other units", func(t *testing.T) {
+		name, scale := unitScale("/gc/heap/allocs:objects", "runtime.go.metrics.gc_heap_allocs.objects", map[string]float64{"bytes": 1e-3})
+		assert.Equal(t, "runtime.go.metrics.gc_heap_allocs.objects", name)
+		assert.Equal(t, 1.0, scale)
+	})
+
+	t.Run("should reject unsupported units and scales", func(t *testing.T) {
+		assert.NoError(t, validateUnitScale(nil))
+		assert.NoError(t, validateUnitScale(map[string]float64{"bytes": 1}))
+		for _, scales := range []map[string]float64{
+			{"bytes": 0.5},
+			{"seconds": 1e3},
+			{"objects": 1e-3},
+		} {
+			e, err := NewEmitter(&statsdClientMock{}, &Options{UnitScale: scales})
+			assert.Error(t, err, scales)
+			assert.Nil(t, e)
+		}
+	})
+}
@@ -0,0 +1,134 @@
+package runtimemetrics
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"math"
+	"regexp"
+	"runtime/metrics"
+	"slices"
+	"time"
+)
+
+// heartbeatMetricName is the gauge VerifySetup submits to the statsd client.
+const heartbeatMetricName = "runtime.go.metrics.heartbeat"
+
+// maxDatadogMetricNameLength is the maximum length of a Datadog metric name.
+const maxDatadogMetricNameLength = 200
+
+// validDatadogMetricNameRegex matches the metric names Datadog accepts.
+var validDatadogMetricNameRegex = regexp.MustCompile(`^[a-zA-Z][a-zA-Z0-9_.]*$`)
+
+// VerifyReport describes the outcome of VerifySetup.
+type VerifyReport struct {
+	// Collected is the number of distinct metric names a report submits.
+	Collected int
+	// Invalid are the sorted names of the metrics that failed validation:
+	// names Datadog rejects, or non-finite values.
+	Invalid []string
+	// ClientAccepted is true if the statsd client accepted the heartbeat
+	// metric, and flushed it without error if it buffers submissions.
+	ClientAccepted bool
+	// ClientError is the error returned by the statsd client, if any.
+	ClientError error
+	// Elapsed is the time VerifySetup took.
+	Elapsed time.Duration
+}
+
+// VerifySetup diagnoses the setup of an emitter with the given options,
+// e.g. in a startup debug mode. It collects the runtime metrics once into a
+// validating sink, then submits one runtime.go.metrics.heartbeat gauge to
+// statsd and flushes it if the client supports it. It doesn't depend on nor
+// disturb a running emitter, and the report hooks, e.g. Options.OnReport,
+// aren't called.
+//
+// It returns an error if opts are invalid, or if ctx is done before the
+// client accepted the heartbeat, in which case the report is partial. A
+// client rejecting the heartbeat isn't an error, see
+// VerifyReport.ClientAccepted.
+func VerifySetup(ctx context.Context, statsd partialStatsdClientInterface, opts *Options) (VerifyReport, error) {
+	start := time.Now()
+	var o Options
+	if opts != nil {
+		o = *opts
+	}
+	if o.Logger == nil {
+		o.Logger = slog.Default()
+	}
+	o.OnReport = nil
+	o.BeforeReport = nil
+	o.AfterReport = nil
+	o.FlushClient = false
+	o.SubmitTimeout = 0
+
+	var report VerifyReport
+	descs := metrics.All()
+	if err := validateOptions(&o, descs); err != nil {
+		return report, err
+	}
+
+	var c sampleCollector
+	rms := newRuntimeMetricStore(descs, &c, &o)
+	// Forget the values read by the constructor, like Collect.
+	for _, rm := range rms.metrics {
+		rm.currentValue = value{}
+	}
+	rms.report()
+	rms.close()
+	report.Collected, report.Invalid = validateSamples(c.samples)
+
+	if statsd == nil {
+		report.ClientError = errors.New("runtimemetrics: no statsd client")
+		report.Elapsed = time.Since(start)
+		return report, nil
+	}
+	// The client may block, e.g. on a full unix socket.
+	done := make(chan error, 1)
+	go func() {
+		err := statsd.GaugeWithTimestamp(heartbeatMetricName, 1, rms.selfTags, 1, time.Now())
+		if f, ok := statsd.(flushableStatsdClient); ok && err == nil {
+			err = f.Flush()
+		}
+		done <- err
+	}()
+	select {
+	case err := <-done:
+		report.ClientAccepted = err == nil
+		report.ClientError = err
+	case <-ctx.Done():
+		report.ClientError = ctx.Err()
+		report.Elapsed = time.Since(start)
+		return report, ctx.Err()
+	}
+	report.Elapsed = time.Since(start)
+	return report, nil
+}
+
+// validateSamples returns the number of distinct names of samples, and the
+// sorted names of the invalid samples.
+func validateSamples(samples []MetricEvent) (int, []string) {
+	names := map[string]bool{}
+	var invalid []string
+	for _, s := range samples {
+		names[s.Name] = true
+		if !validSample(s) {
+			invalid = append(invalid, s.Name)
+		}
+	}
+	slices.Sort(invalid)
+	return len(names), slices.Compact(invalid)
+}
+
+// validSample returns true if Datadog accepts the name and values of s.
+func validSample(s MetricEvent) bool {
+	if len(s.Name) > maxDatadogMetricNameLength || !validDatadogMetricNameRegex.MatchString(s.Name) {
+		return false
+	}
+	for _, v := range append([]float64{s.Value}, s.Values...) {
+		if math.IsNaN(v) || math.IsInf(v, 0) {
+			return false
+		}
+	}
+	return true
+}
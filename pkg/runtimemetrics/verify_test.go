@@ -0,0 +1,90 @@
+package runtimemetrics
+
+import (
+	"context"
+	"errors"
+	"math"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestVerifySetup(t *testing.T) {
+	t.Run("should collect and submit the heartbeat", func(t *testing.T) {
+		mock := &flushableStatsdClientMock{}
+		report, err := VerifySetup(context.Background(), mock, nil)
+		require.NoError(t, err)
+		assert.Greater(t, report.Collected, 10)
+		assert.Empty(t, report.Invalid)
+		assert.True(t, report.ClientAccepted)
+		assert.NoError(t, report.ClientError)
+		assert.Positive(t, report.Elapsed)
+
+		calls := mock.GaugeCalls()
+		require.Len(t, calls, 1, "only the heartbeat should be submitted to the client")
+		assert.Equal(t, heartbeatMetricName, calls[0].Name)
+		assert.Equal(t, int64(1), mock.flushCalls.Load())
+	})
+
+	t.Run("should report client errors", func(t *testing.T) {
+		mock := &flushableStatsdClientMock{flushErr: errors.New("connection refused")}
+		report, err := VerifySetup(context.Background(), mock, nil)
+		require.NoError(t, err)
+		assert.False(t, report.ClientAccepted)
+		assert.EqualError(t, report.ClientError, "connection refused")
+
+		report, err = VerifySetup(context.Background(), nil, nil)
+		require.NoError(t, err)
+		assert.False(t, report.ClientAccepted)
+		assert.Error(t, report.ClientError)
+	})
+
+	t.Run("should give up on blocked clients", func(t *testing.T) {
+		mock := &blockingStatsdClientMock{unblock: make(chan struct{})}
+		defer close(mock.unblock)
+		ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+		defer cancel()
+		report, err := VerifySetup(ctx, mock, nil)
+		assert.ErrorIs(t, err, context.DeadlineExceeded)
+		assert.False(t, report.ClientAccepted)
+		assert.Positive(t, report.Collected)
+	})
+
+	t.Run("should reject invalid options", func(t *testing.T) {
+		_, err := VerifySetup(context.Background(), &statsdClientMock{}, &Options{NameSeparator: "-"})
+		assert.Error(t, err)
+	})
+
+	t.Run("should not disturb a running emitter", func(t *testing.T) {
+		e, err := NewEmitter(&statsdClientMock{}, nil)
+		require.NoError(t, err)
+		defer e.Stop()
+		var reports int
+		_, err = VerifySetup(context.Background(), &statsdClientMock{}, &Options{OnReport: func(ReportStats) { reports++ }})
+		require.NoError(t, err)
+		assert.Zero(t, reports, "OnReport shouldn't be called")
+	})
+}
+
+func TestValidateSamples(t *testing.T) {
+	collected, invalid := validateSamples([]MetricEvent{
+		{Name: "runtime.go.metrics.valid", Value: 1},
+		{Name: "runtime.go.metrics.valid", Value: 2},
+		{Name: "runtime.go.metrics.nan", Value: math.NaN()},
+		{Name: "runtime.go.metrics.inf", Values: []float64{1, math.Inf(1)}},
+		{Name: "runtime.go.metrics.invalid-name", Value: 1},
+		{Name: "1runtime.go.metrics", Value: 1},
+		{Name: strings.Repeat("a", maxDatadogMetricNameLength+1), Value: 1},
+	})
+	assert.Equal(t, 6, collected)
+	assert.Equal(t, []string{
+		"1runtime.go.metrics",
+		strings.Repeat("a", maxDatadogMetricNameLength+1),
+		"runtime.go.metrics.inf",
+		"runtime.go.metrics.invalid-name",
+		"runtime.go.metrics.nan",
+	}, invalid)
+}
@@ -0,0 +1,46 @@
+package runtimemetrics
+
+import (
+	"runtime/debug"
+	"sync"
+)
+
+// version is the version of this library returned by Version when the build
+// info doesn't record it, e.g. in the tests of this module. The module isn't
+// tagged while v0, see CONTRIBUTING.md, so this is a coarse marker: users get
+// pseudo-versions of specific commits.
+const version = "v0.1.0"
+
+const modulePath = "github.com/DataDog/go-runtime-metrics-internal"
+
+var versionOnce = sync.OnceValue(func() string { return resolveVersion(readBuildInfo) })
+
+// Version returns the version of go-runtime-metrics-internal embedded in the
+// current binary.
+//
+// Since this module isn't tagged (see CONTRIBUTING.md), the version recorded
+// by the go tool in the binary's build info (usually a pseudo-version) is the
+// most precise information available and is returned when present. Otherwise
+// Version falls back to a coarse internal constant.
+func Version() string {
+	return versionOnce()
+}
+
+func resolveVersion(read func() (*debug.BuildInfo, bool)) string {
+	bi, ok := read()
+	if !ok {
+		return version
+	}
+	for _, dep := range bi.Deps {
+		if dep.Path != modulePath {
+			continue
+		}
+		if dep.Replace != nil && dep.Replace.Version != "" {
+			return dep.Replace.Version
+		}
+		if dep.Version != "" {
+			return dep.Version
+		}
+	}
+	return version
+}
@@ -0,0 +1,55 @@
+package runtimemetrics
+
+import (
+	"log/slog"
+	"regexp"
+	"runtime/debug"
+	"runtime/metrics"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// semverRegex is a loose semver check that also accepts Go pseudo-versions.
+var semverRegex = regexp.MustCompile(`^v\d+\.\d+\.\d+(-[0-9A-Za-z.-]+)?(\+[0-9A-Za-z.-]+)?$`)
+
+func TestVersion(t *testing.T) {
+	t.Run("should return a semver-ish version", func(t *testing.T) {
+		assert.Regexp(t, semverRegex, Version())
+		assert.Regexp(t, semverRegex, version)
+	})
+
+	t.Run("should fall back to the constant without build info", func(t *testing.T) {
+		v := resolveVersion(func() (*debug.BuildInfo, bool) { return nil, false })
+		assert.Equal(t, version, v)
+	})
+
+	t.Run("should fall back to the constant when the module is the main module", func(t *testing.T) {
+		v := resolveVersion(func() (*debug.BuildInfo, bool) {
+			return &debug.BuildInfo{Main: debug.Module{Path: modulePath, Version: "(devel)"}}, true
+		})
+		assert.Equal(t, version, v)
+	})
+
+	t.Run("should prefer the dependency version from the build info", func(t *testing.T) {
+		pseudo := "v0.0.0-20240501120000-830ed3f12345"
+		v := resolveVersion(func() (*debug.BuildInfo, bool) {
+			return &debug.BuildInfo{Deps: []*debug.Module{
+				{Path: "github.com/stretchr/testify", Version: "v1.9.0"},
+				{Path: modulePath, Version: pseudo},
+			}}, true
+		})
+		assert.Equal(t, pseudo, v)
+		assert.Regexp(t, semverRegex, v)
+	})
+
+	t.Run("should attach the version tag when enabled", func(t *testing.T) {
+		rms := newRuntimeMetricStore([]metrics.Description{}, &statsdClientMock{}, &Options{Logger: slog.Default(), VersionTag: true})
+		assertTagValue(t, "go_runtime_metrics_version", Version(), rms.baseTags)
+
+		rms = newRuntimeMetricStore([]metrics.Description{}, &statsdClientMock{}, &Options{Logger: slog.Default()})
+		for _, tag := range rms.baseTags {
+			assert.NotContains(t, tag, "go_runtime_metrics_version:")
+		}
+	})
+}
@@ -0,0 +1,221 @@
+package gen
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"slices"
+	"strings"
+
+	"github.com/DataDog/go-runtime-metrics-internal/pkg/runtimemetrics"
+)
+
+// Dashboard is a Datadog dashboard definition, see
+// https://docs.datadoghq.com/api/latest/dashboards/. Only the fields used by
+// NewDashboard are modeled.
+type Dashboard struct {
+	Title             string             `json:"title"`
+	Description       string             `json:"description"`
+	LayoutType        string             `json:"layout_type"`
+	TemplateVariables []TemplateVariable `json:"template_variables"`
+	Widgets           []Widget           `json:"widgets"`
+}
+
+// TemplateVariable is a dashboard template variable.
+type TemplateVariable struct {
+	Name    string `json:"name"`
+	Prefix  string `json:"prefix"`
+	Default string `json:"default"`
+}
+
+// Widget is a dashboard widget.
+type Widget struct {
+	Definition WidgetDefinition `json:"definition"`
+}
+
+// WidgetDefinition is a group widget, with Widgets and LayoutType, or a
+// timeseries widget, with Requests.
+type WidgetDefinition struct {
+	Type       string    `json:"type"`
+	Title      string    `json:"title"`
+	LayoutType string    `json:"layout_type,omitempty"`
+	Widgets    []Widget  `json:"widgets,omitempty"`
+	Requests   []Request `json:"requests,omitempty"`
+}
+
+// Request is a timeseries widget request.
+type Request struct {
+	ResponseFormat string    `json:"response_format"`
+	Queries        []Query   `json:"queries"`
+	Formulas       []Formula `json:"formulas"`
+	DisplayType    string    `json:"display_type"`
+}
+
+// Query is a metric query of a Request.
+type Query struct {
+	DataSource string `json:"data_source"`
+	Name       string `json:"name"`
+	Query      string `json:"query"`
+}
+
+// Formula is a formula of a Request, combining its queries.
+type Formula struct {
+	Formula string `json:"formula"`
+	Alias   string `json:"alias,omitempty"`
+}
+
+// serviceVariable is the template variable the queries are scoped by.
+const serviceVariable = "service"
+
+// NewDashboard returns a "Go Runtime (v2)" dashboard graphing a curated set
+// of the given metrics. It returns an error if a metric it needs is missing
+// from metadata, so that the dashboard can't reference metrics that aren't
+// reported.
+func NewDashboard(metadata []Metric) (*Dashboard, error) {
+	d := metricResolver{metadata: metadata, what: "dashboard"}
+	dash := &Dashboard{
+		Title:       "Go Runtime (v2)",
+		Description: "Runtime metrics reported by github.com/DataDog/go-runtime-metrics-internal.",
+		LayoutType:  "ordered",
+		TemplateVariables: []TemplateVariable{
+			{Name: serviceVariable, Prefix: serviceVariable, Default: "*"},
+		},
+		Widgets: []Widget{
+			group("Memory",
+				timeseries("Memory classes", "area", "sum", d.prefixed("/memory/classes/", "/memory/classes/total:bytes")...),
+				timeseries("Live heap", "line", "avg", d.metric("/gc/heap/live:bytes"), d.metric("/gc/heap/goal:bytes")),
+			),
+			group("Garbage collector",
+				timeseries("GC pauses", "line", "avg", d.summary("/gc/pauses:seconds", "p95"), d.summary("/gc/pauses:seconds", "p99")),
+				ratio("GC CPU fraction", d.metric("/cpu/classes/gc/total:cpu-seconds"), d.metric("/cpu/classes/total:cpu-seconds")),
+			),
+			group("Scheduler",
+				timeseries("Goroutine states", "area", "sum", d.prefixed("/sched/goroutines/")...),
+				timeseries("Scheduling latency", "line", "avg", d.summary("/sched/latencies:seconds", "p95"), d.summary("/sched/latencies:seconds", "p99")),
+			),
+		},
+	}
+	if d.err != nil {
+		return nil, d.err
+	}
+	return dash, nil
+}
+
+// WriteDashboard writes the dashboard of the given metrics as JSON to w, see
+// NewDashboard.
+func WriteDashboard(w io.Writer, metadata []Metric) error {
+	dash, err := NewDashboard(metadata)
+	if err != nil {
+		return err
+	}
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	return enc.Encode(dash)
+}
+
+// metricResolver looks up the Datadog names of the metrics referenced by a
+// template, recording the first missing metric.
+type metricResolver struct {
+	metadata []Metric
+	// what is the template, used in errors.
+	what string
+	err  error
+}
+
+// metric returns the Datadog name of the runtime metric.
+func (d *metricResolver) metric(runtimeName string) string {
+	for _, m := range d.metadata {
+		if m.RuntimeName == runtimeName {
+			return m.Name
+		}
+	}
+	d.fail("%s isn't reported", runtimeName)
+	return ""
+}
+
+// summary returns the Datadog name of the given summary of the runtime
+// histogram.
+func (d *metricResolver) summary(runtimeName, stat string) string {
+	if !slices.Contains(runtimemetrics.HistogramSummaries(), stat) {
+		d.fail("%s isn't one of the summaries %v", stat, runtimemetrics.HistogramSummaries())
+		return ""
+	}
+	name, err := runtimemetrics.DatadogSummaryMetricName(runtimeName, stat)
+	if err != nil {
+		d.fail("%v", err)
+		return ""
+	}
+	for _, m := range d.metadata {
+		if m.RuntimeName == runtimeName && m.Name == name {
+			return name
+		}
+	}
+	d.fail("%s %s isn't reported", runtimeName, stat)
+	return ""
+}
+
+// prefixed returns the Datadog names of the scalar runtime metrics starting
+// with prefix, except the excluded ones, e.g. totals that would be stacked
+// over their members.
+func (d *metricResolver) prefixed(prefix string, exclude ...string) []string {
+	var names []string
+	for _, m := range d.metadata {
+		if !strings.HasPrefix(m.RuntimeName, prefix) || m.Type != "gauge" {
+			continue
+		}
+		excluded := false
+		for _, e := range exclude {
+			excluded = excluded || m.RuntimeName == e
+		}
+		if !excluded {
+			names = append(names, m.Name)
+		}
+	}
+	if len(names) == 0 {
+		d.fail("no %s* metric is reported", prefix)
+	}
+	return names
+}
+
+func (d *metricResolver) fail(format string, args ...any) {
+	if d.err == nil {
+		d.err = fmt.Errorf(d.what+": "+format, args...)
+	}
+}
+
+// timeseries returns a widget graphing the given metrics, aggregated across
+// the sources of the service with aggregation.
+func timeseries(title, displayType, aggregation string, names ...string) Widget {
+	r := Request{ResponseFormat: "timeseries", DisplayType: displayType}
+	for i, name := range names {
+		q := query(i, aggregation, name)
+		r.Queries = append(r.Queries, q)
+		r.Formulas = append(r.Formulas, Formula{Formula: q.Name, Alias: name})
+	}
+	return Widget{WidgetDefinition{Type: "timeseries", Title: title, Requests: []Request{r}}}
+}
+
+// ratio returns a widget graphing the ratio of the rates of the given
+// cumulative metrics.
+func ratio(title, numerator, denominator string) Widget {
+	num, den := query(0, "sum", numerator), query(1, "sum", denominator)
+	r := Request{
+		ResponseFormat: "timeseries",
+		DisplayType:    "line",
+		Queries:        []Query{num, den},
+		Formulas:       []Formula{{Formula: fmt.Sprintf("per_second(%s) / per_second(%s)", num.Name, den.Name), Alias: title}},
+	}
+	return Widget{WidgetDefinition{Type: "timeseries", Title: title, Requests: []Request{r}}}
+}
+
+func query(i int, aggregation, name string) Query {
+	return Query{
+		DataSource: "metrics",
+		Name:       fmt.Sprintf("query%d", i+1),
+		Query:      fmt.Sprintf("%s:%s{$%s}", aggregation, name, serviceVariable),
+	}
+}
+
+func group(title string, widgets ...Widget) Widget {
+	return Widget{WidgetDefinition{Type: "group", Title: title, LayoutType: "ordered", Widgets: widgets}}
+}
@@ -0,0 +1,69 @@
+package gen
+
+import (
+	"bytes"
+	"encoding/json"
+	"regexp"
+	"testing"
+
+	"github.com/DataDog/go-runtime-metrics-internal/pkg/runtimemetrics"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestWriteDashboard(t *testing.T) {
+	// Like the command, use the embedded metadata: the metrics graphed by
+	// the dashboard are missing from older Go versions.
+	metadata := Embedded(runtimemetrics.DefaultPeriod)
+	names := map[string]bool{}
+	for _, m := range metadata {
+		names[m.Name] = true
+	}
+
+	var buf bytes.Buffer
+	require.NoError(t, WriteDashboard(&buf, metadata))
+
+	// Decode generically, to validate the JSON rather than the Go types.
+	var dash map[string]any
+	require.NoError(t, json.Unmarshal(buf.Bytes(), &dash))
+	assert.Equal(t, "Go Runtime (v2)", dash["title"])
+	assert.Equal(t, "ordered", dash["layout_type"])
+	assert.Equal(t, []any{map[string]any{"name": "service", "prefix": "service", "default": "*"}}, dash["template_variables"])
+
+	queryRegex := regexp.MustCompile(`^(avg|sum):([a-z0-9_.]+)\{\$service\}$`)
+	groups := dash["widgets"].([]any)
+	require.Len(t, groups, 3)
+	for _, g := range groups {
+		group := g.(map[string]any)["definition"].(map[string]any)
+		assert.Equal(t, "group", group["type"])
+		widgets := group["widgets"].([]any)
+		require.NotEmpty(t, widgets, group["title"])
+		for _, w := range widgets {
+			widget := w.(map[string]any)["definition"].(map[string]any)
+			assert.Equal(t, "timeseries", widget["type"])
+			requests := widget["requests"].([]any)
+			require.Len(t, requests, 1)
+			request := requests[0].(map[string]any)
+			queries := request["queries"].([]any)
+			require.NotEmpty(t, queries, widget["title"])
+			assert.NotEmpty(t, request["formulas"], widget["title"])
+			for _, q := range queries {
+				query := q.(map[string]any)["query"].(string)
+				m := queryRegex.FindStringSubmatch(query)
+				require.NotNil(t, m, query)
+				assert.True(t, names[m[2]], "%s isn't a generated metric", m[2])
+			}
+		}
+	}
+
+	t.Run("missing metrics are an error", func(t *testing.T) {
+		var withoutPauses []Metric
+		for _, m := range metadata {
+			if m.RuntimeName != "/gc/pauses:seconds" {
+				withoutPauses = append(withoutPauses, m)
+			}
+		}
+		_, err := NewDashboard(withoutPauses)
+		assert.ErrorContains(t, err, "/gc/pauses:seconds")
+	})
+}
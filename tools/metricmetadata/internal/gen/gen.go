@@ -0,0 +1,427 @@
+// Package gen generates the Datadog metadata of the runtime metrics reported
+// by pkg/runtimemetrics, see tools/metricmetadata.
+package gen
+
+import (
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"io"
+	"runtime/metrics"
+	"slices"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/DataDog/go-runtime-metrics-internal/internal/metadata"
+	"github.com/DataDog/go-runtime-metrics-internal/pkg/runtimemetrics"
+)
+
+// integration is the value of the integration column of all the rows.
+const integration = "go_runtime"
+
+// maxDescriptionLength is the maximum length of a description accepted by
+// the backend.
+const maxDescriptionLength = 400
+
+// sampleTags is the value of the sample_tags column of all the rows: the
+// base tags attached to all the metrics.
+var sampleTags = strings.Join(runtimemetrics.BaseTagNames(), ",")
+
+// MaxShortNameLength is the maximum length of the short names, see
+// -max-short-name. Longer names are truncated.
+var MaxShortNameLength = 64
+
+// versionComment starts the first line of metadata.csv, followed by the
+// version of the library.
+const versionComment = "# go-runtime-metrics-internal "
+
+// Header is the header of metadata.csv.
+var Header = []string{
+	"metric_name",
+	"metric_type",
+	"interval",
+	"unit_name",
+	"per_unit_name",
+	"description",
+	"orientation",
+	"integration",
+	"short_name",
+	"curated_metric",
+	"sample_tags",
+}
+
+// Metric is a row of metadata.csv.
+type Metric struct {
+	Name          string
+	Type          string
+	Interval      string
+	Unit          string
+	PerUnit       string
+	Description   string
+	Orientation   string
+	Integration   string
+	ShortName     string
+	CuratedMetric string
+	SampleTags    string
+
+	// RuntimeName is the runtime/metrics name the metric is reported from.
+	// It isn't part of metadata.csv.
+	RuntimeName string
+}
+
+// record returns the CSV fields of m, in the order of Header.
+func (m Metric) record() []string {
+	return []string{
+		m.Name,
+		m.Type,
+		m.Interval,
+		m.Unit,
+		m.PerUnit,
+		m.Description,
+		m.Orientation,
+		m.Integration,
+		m.ShortName,
+		m.CuratedMetric,
+		m.SampleTags,
+	}
+}
+
+func metricFromRecord(r []string) Metric {
+	return Metric{
+		Name:          r[0],
+		Type:          r[1],
+		Interval:      r[2],
+		Unit:          r[3],
+		PerUnit:       r[4],
+		Description:   r[5],
+		Orientation:   r[6],
+		Integration:   r[7],
+		ShortName:     r[8],
+		CuratedMetric: r[9],
+		SampleTags:    r[10],
+	}
+}
+
+// Generate returns the metadata of the runtime metrics reported by the
+// library, see runtimemetrics.SupportedMetrics, sorted by runtime metric name,
+// followed by the derived metrics, see runtimemetrics.DerivedMetrics.
+func Generate() ([]Metric, error) {
+	return GenerateForPeriod(runtimemetrics.DefaultPeriod)
+}
+
+// GenerateForPeriod is Generate for metrics reported every period, see
+// runtimemetrics.Options.Period, which is the interval of count and rate
+// metrics.
+func GenerateForPeriod(period time.Duration) ([]Metric, error) {
+	res, err := generate(runtimemetrics.SupportedMetrics())
+	if err != nil {
+		return nil, err
+	}
+	for i := range res {
+		res[i].Interval = interval(res[i].Type, period)
+	}
+	return res, nil
+}
+
+// interval returns the value of the interval column of a metric of the given
+// type submitted every period: its number of seconds for count and rate
+// metrics, and "" for the others.
+func interval(metricType string, period time.Duration) string {
+	if metricType != "count" && metricType != "rate" {
+		return ""
+	}
+	return strconv.FormatInt(int64(period/time.Second), 10)
+}
+
+func generate(descs []metrics.Description) ([]Metric, error) {
+	descs = slices.Clone(descs)
+	sort.Slice(descs, func(i, j int) bool { return descs[i].Name < descs[j].Name })
+
+	var res []Metric
+	collected := map[string]bool{}
+	shortNames := baseShortNames(descs)
+	for _, d := range descs {
+		rows, err := metadataRows(d, shortNames[d.Name])
+		if err != nil {
+			return nil, err
+		}
+		res = append(res, rows...)
+		collected[d.Name] = true
+	}
+	for _, d := range runtimemetrics.DerivedMetrics() {
+		if !allCollected(collected, d.Inputs) {
+			continue
+		}
+		row, err := derivedMetadataRow(d)
+		if err != nil {
+			return nil, err
+		}
+		res = append(res, row)
+	}
+	if err := checkShortNames(res); err != nil {
+		return nil, err
+	}
+	return res, nil
+}
+
+func allCollected(collected map[string]bool, names []string) bool {
+	for _, n := range names {
+		if !collected[n] {
+			return false
+		}
+	}
+	return true
+}
+
+// Write writes metadata as CSV to w, after a comment recording the version
+// of the library it describes, see runtimemetrics.Version.
+func Write(w io.Writer, metadata []Metric) error {
+	if _, err := fmt.Fprintf(w, "%s%s\n", versionComment, runtimemetrics.Version()); err != nil {
+		return err
+	}
+	cw := csv.NewWriter(w)
+	if err := cw.Write(Header); err != nil {
+		return err
+	}
+	for _, m := range metadata {
+		if err := cw.Write(m.record()); err != nil {
+			return err
+		}
+	}
+	cw.Flush()
+	return cw.Error()
+}
+
+// Read reads the metadata written by Write. The version comment is optional.
+func Read(r io.Reader) ([]Metric, error) {
+	cr := csv.NewReader(r)
+	cr.Comment = '#'
+	cr.FieldsPerRecord = len(Header)
+	records, err := cr.ReadAll()
+	if err != nil {
+		return nil, err
+	}
+	if len(records) == 0 || !slices.Equal(records[0], Header) {
+		return nil, errors.New("missing or unexpected CSV header")
+	}
+	res := make([]Metric, 0, len(records)-1)
+	for _, r := range records[1:] {
+		res = append(res, metricFromRecord(r))
+	}
+	return res, nil
+}
+
+// Diff returns a line for each metric added, removed or changed in
+// got compared to want, ignoring the order of the rows.
+func Diff(want, got []Metric) []string {
+	wantByName := map[string]Metric{}
+	for _, m := range want {
+		wantByName[m.Name] = m
+	}
+	gotByName := map[string]Metric{}
+	for _, m := range got {
+		gotByName[m.Name] = m
+	}
+
+	var diff []string
+	for _, m := range got {
+		old, ok := wantByName[m.Name]
+		if !ok {
+			diff = append(diff, "+ "+m.Name)
+			continue
+		}
+		oldRecord, newRecord := old.record(), m.record()
+		if slices.Equal(oldRecord, newRecord) {
+			continue
+		}
+		var changes []string
+		for i := range Header {
+			if oldRecord[i] != newRecord[i] {
+				changes = append(changes, fmt.Sprintf("%s: %q -> %q", Header[i], oldRecord[i], newRecord[i]))
+			}
+		}
+		diff = append(diff, "~ "+m.Name+": "+strings.Join(changes, ", "))
+	}
+	for _, m := range want {
+		if _, ok := gotByName[m.Name]; !ok {
+			diff = append(diff, "- "+m.Name)
+		}
+	}
+	sort.Slice(diff, func(i, j int) bool { return diff[i][2:] < diff[j][2:] })
+	return diff
+}
+
+// metadataRows returns the rows of the metrics reported for d: a gauge for
+// scalar metrics, and a distribution plus a gauge per summary for
+// histograms. shortName is the short name of d, see baseShortNames.
+func metadataRows(d metrics.Description, shortName string) ([]Metric, error) {
+	name, err := runtimemetrics.DatadogMetricName(d.Name)
+	if err != nil {
+		return nil, err
+	}
+	unit, err := mapUnit(d.Name)
+	if err != nil {
+		return nil, err
+	}
+	description := processDescription(d.Description)
+	orientation := getOrientation(d.Name)
+
+	if d.Kind != metrics.KindFloat64Histogram {
+		return []Metric{newMetric(d.Name, name, "gauge", unit, description, orientation, truncateShortName(shortName, ""))}, nil
+	}
+	rows := []Metric{newMetric(d.Name, name, "distribution", unit, description, orientation, truncateShortName(shortName, ""))}
+	for _, stat := range runtimemetrics.HistogramSummaries() {
+		statName, err := runtimemetrics.DatadogSummaryMetricName(d.Name, stat)
+		if err != nil {
+			// The name was parsed above, and the stats are known.
+			panic(err)
+		}
+		rows = append(rows, newMetric(
+			d.Name,
+			statName,
+			"gauge",
+			unit,
+			processDescription(stat+" of: "+d.Description),
+			orientation,
+			truncateShortName(shortName, " "+stat),
+		))
+	}
+	return rows, nil
+}
+
+// derivedMetadataRow returns the row of the gauge reported for d with
+// Options.DerivedMetrics. Its RuntimeName is empty.
+func derivedMetadataRow(d runtimemetrics.DerivedMetric) (Metric, error) {
+	unit, err := mapUnit(d.Name)
+	if err != nil {
+		return Metric{}, err
+	}
+	orientation := getOrientation(d.Name)
+	return newMetric("", d.Name, "gauge", unit, processDescription(d.Description), orientation, truncateShortName(datadogShortName(d.Name), "")), nil
+}
+
+func newMetric(runtimeName, name, metricType string, unit metadata.Unit, description, orientation, shortName string) Metric {
+	return Metric{
+		RuntimeName:   runtimeName,
+		Name:          name,
+		Type:          metricType,
+		Unit:          unit.Name,
+		PerUnit:       unit.PerUnit,
+		Description:   description,
+		Orientation:   orientation,
+		Integration:   integration,
+		ShortName:     shortName,
+		CuratedMetric: metadata.CuratedMetrics[name],
+		SampleTags:    sampleTags,
+	}
+}
+
+// mapUnit returns the Datadog unit of the runtime/metrics or Datadog metric
+// name, or an error if its unit is unknown.
+func mapUnit(name string) (metadata.Unit, error) {
+	unit, ok := metadata.MetricUnit(name)
+	if !ok {
+		return metadata.Unit{}, fmt.Errorf("%s: unknown unit %q, add it to metadata.RuntimeUnitMapping or metadata.MetricUnitOverrides", name, metadata.RuntimeUnit(name))
+	}
+	return unit, nil
+}
+
+// processDescription turns a runtime/metrics description into a single line
+// of at most maxDescriptionLength characters.
+func processDescription(description string) string {
+	description = strings.Join(strings.Fields(description), " ")
+	if len(description) > maxDescriptionLength {
+		description = description[:maxDescriptionLength-3] + "..."
+	}
+	return description
+}
+
+// getOrientation returns the orientation of a runtime/metrics or Datadog
+// metric name, see metadata.OrientationOverrides. Without an override, it is
+// -1 for metrics where lower values are better, such as pauses and
+// latencies, and 0 otherwise.
+func getOrientation(name string) string {
+	if o, ok := metadata.OrientationOverrides[name]; ok {
+		return strconv.Itoa(o)
+	}
+	for _, s := range []string{"pauses", "latencies", "/cpu/classes/gc/"} {
+		if strings.Contains(name, s) {
+			return "-1"
+		}
+	}
+	return "0"
+}
+
+// getShortName returns a human-readable name for the runtime metric, made of
+// the words of its path, e.g. "gc heap live" for /gc/heap/live:bytes.
+func getShortName(runtimeName string) string {
+	path, _, _ := strings.Cut(runtimeName, ":")
+	words := strings.FieldsFunc(path, func(r rune) bool {
+		return r == '/' || r == '-' || r == '_'
+	})
+	return strings.Join(words, " ")
+}
+
+// datadogShortName returns a human-readable name for a metric without a
+// runtime metric of its own, made of the words of its Datadog name, e.g.
+// "gc frequency" for runtime.go.metrics.gc_frequency.
+func datadogShortName(name string) string {
+	name = strings.TrimPrefix(name, "runtime.go.metrics.")
+	return strings.Join(strings.FieldsFunc(name, func(r rune) bool {
+		return r == '.' || r == '_'
+	}), " ")
+}
+
+// baseShortNames returns the short names of descs, see getShortName, by
+// runtime metric name. The unit is appended to the short names shared by
+// several metrics, e.g. "gc heap allocs bytes" and "gc heap allocs objects".
+func baseShortNames(descs []metrics.Description) map[string]string {
+	byShortName := map[string][]string{}
+	for _, d := range descs {
+		shortName := getShortName(d.Name)
+		byShortName[shortName] = append(byShortName[shortName], d.Name)
+	}
+	res := map[string]string{}
+	for shortName, names := range byShortName {
+		for _, name := range names {
+			if len(names) == 1 {
+				res[name] = shortName
+				continue
+			}
+			res[name] = shortName + " " + strings.Join(strings.FieldsFunc(metadata.RuntimeUnit(name), func(r rune) bool {
+				return r == '-' || r == '/' || r == '*'
+			}), " ")
+		}
+	}
+	return res
+}
+
+// truncateShortName returns shortName followed by suffix, truncating
+// shortName at a word boundary so that the result is at most
+// MaxShortNameLength long.
+func truncateShortName(shortName, suffix string) string {
+	limit := max(MaxShortNameLength-len(suffix), 0)
+	if len(shortName) > limit {
+		if i := strings.LastIndex(shortName[:limit+1], " "); i > 0 {
+			shortName = shortName[:i]
+		} else {
+			shortName = shortName[:limit]
+		}
+	}
+	return shortName + suffix
+}
+
+// checkShortNames returns an error if rows share a short name, which the
+// backend rejects.
+func checkShortNames(rows []Metric) error {
+	names := map[string]string{}
+	for _, m := range rows {
+		if other, ok := names[m.ShortName]; ok {
+			return fmt.Errorf("%s and %s have the same short name %q, disambiguate them in getShortName", other, m.Name, m.ShortName)
+		}
+		names[m.ShortName] = m.Name
+	}
+	return nil
+}
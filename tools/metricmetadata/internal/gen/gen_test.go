@@ -0,0 +1,322 @@
+package gen
+
+import (
+	"bytes"
+	"runtime/metrics"
+	"slices"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/DataDog/go-runtime-metrics-internal/internal/metadata"
+	"github.com/DataDog/go-runtime-metrics-internal/pkg/runtimemetrics"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestGenerate(t *testing.T) {
+	t.Run("all the runtime metrics are described", func(t *testing.T) {
+		metadata, err := Generate()
+		require.NoError(t, err)
+		require.NotEmpty(t, metadata)
+
+		names := map[string]Metric{}
+		for _, m := range metadata {
+			names[m.Name] = m
+		}
+		assert.Equal(t, "gauge", names["runtime.go.metrics.gc_heap_live.bytes"].Type)
+		assert.Equal(t, "byte", names["runtime.go.metrics.gc_heap_live.bytes"].Unit)
+		assert.Equal(t, "distribution", names["runtime.go.metrics.gc_pauses.seconds"].Type)
+		assert.Equal(t, "gauge", names["runtime.go.metrics.gc_pauses.seconds.p99"].Type)
+	})
+
+	t.Run("curated metrics and sample tags", func(t *testing.T) {
+		generated, err := Generate()
+		require.NoError(t, err)
+
+		curated := map[string]string{}
+		for _, m := range generated {
+			assert.Equal(t, strings.Join(runtimemetrics.BaseTagNames(), ","), m.SampleTags, m.Name)
+			if m.CuratedMetric != "" {
+				curated[m.Name] = m.CuratedMetric
+			}
+		}
+		// All the curated metrics are reported, and typos don't go unnoticed.
+		assert.Equal(t, metadata.CuratedMetrics, curated)
+		for name, curatedType := range metadata.CuratedMetrics {
+			assert.Contains(t, []string{"cpu", "memory"}, curatedType, name)
+		}
+	})
+
+	t.Run("derived metrics are described", func(t *testing.T) {
+		metadata, err := generate([]metrics.Description{
+			{Name: "/gc/cycles/total:gc-cycles", Kind: metrics.KindUint64, Cumulative: true},
+		})
+		require.NoError(t, err)
+		require.Len(t, metadata, 3)
+		assert.Equal(t, "runtime.go.metrics.derived.seconds_since_gc.seconds", metadata[1].Name)
+		assert.Equal(t, "second", metadata[1].Unit)
+		assert.Empty(t, metadata[1].RuntimeName)
+		assert.Equal(t, "runtime.go.metrics.gc_frequency", metadata[2].Name)
+		assert.Equal(t, "second", metadata[2].PerUnit)
+		assert.Equal(t, "gc frequency", metadata[2].ShortName)
+	})
+
+	t.Run("unknown units are an error", func(t *testing.T) {
+		_, err := generate([]metrics.Description{{Name: "/test/distance:parsecs", Kind: metrics.KindUint64}})
+		assert.ErrorContains(t, err, "parsecs")
+	})
+
+	t.Run("unparsable names are an error", func(t *testing.T) {
+		_, err := generate([]metrics.Description{{Name: "bytes", Kind: metrics.KindUint64}})
+		assert.Error(t, err)
+	})
+}
+
+// TestGenerateMatchesLibrary ensures the metadata documents exactly the
+// metrics the library reports with the default options and derived metrics.
+func TestGenerateMatchesLibrary(t *testing.T) {
+	metadata, err := Generate()
+	require.NoError(t, err)
+	var documented []string
+	for _, m := range metadata {
+		documented = append(documented, m.Name)
+	}
+	slices.Sort(documented)
+
+	est, err := runtimemetrics.EstimateSeries(&runtimemetrics.Options{DerivedMetrics: true})
+	require.NoError(t, err)
+	assert.Equal(t, est.Names, documented)
+
+	all := runtimemetrics.AllDatadogMetricNames()
+	for _, name := range documented {
+		assert.Contains(t, all, name)
+	}
+}
+
+func TestInterval(t *testing.T) {
+	assert.Equal(t, "10", interval("count", runtimemetrics.DefaultPeriod))
+	assert.Equal(t, "10", interval("rate", runtimemetrics.DefaultPeriod))
+	assert.Equal(t, "60", interval("count", time.Minute))
+	assert.Empty(t, interval("gauge", runtimemetrics.DefaultPeriod))
+	assert.Empty(t, interval("distribution", runtimemetrics.DefaultPeriod))
+
+	generated, err := Generate()
+	require.NoError(t, err)
+	for _, m := range generated {
+		assert.Equal(t, interval(m.Type, runtimemetrics.DefaultPeriod), m.Interval, m.Name)
+	}
+}
+
+func TestWriteRead(t *testing.T) {
+	metadata, err := Generate()
+	require.NoError(t, err)
+
+	var buf bytes.Buffer
+	require.NoError(t, Write(&buf, metadata))
+	firstLine, _, _ := strings.Cut(buf.String(), "\n")
+	assert.Equal(t, versionComment+runtimemetrics.Version(), firstLine)
+	read, err := Read(&buf)
+	require.NoError(t, err)
+	require.Len(t, read, len(metadata))
+	for i := range read {
+		assert.Equal(t, metadata[i].record(), read[i].record())
+	}
+	assert.Empty(t, Diff(metadata, read))
+
+	_, err = Read(strings.NewReader(strings.Join(Header, ",") + "\n"))
+	assert.NoError(t, err, "the version comment is optional")
+	_, err = Read(strings.NewReader("name,type\n"))
+	assert.Error(t, err)
+}
+
+func TestDiff(t *testing.T) {
+	a := Metric{Name: "a", Type: "gauge"}
+	b := Metric{Name: "b", Type: "gauge"}
+	c := Metric{Name: "c", Type: "gauge"}
+	changed := Metric{Name: "b", Type: "gauge", Unit: "byte"}
+
+	assert.Empty(t, Diff([]Metric{a, b}, []Metric{b, a}))
+	assert.Equal(t, []string{
+		"- a",
+		`~ b: unit_name: "" -> "byte"`,
+		"+ c",
+	}, Diff([]Metric{a, b}, []Metric{c, changed}))
+}
+
+func TestProcessDescription(t *testing.T) {
+	assert.Equal(t, "Count of calls. Across lines.", processDescription("Count of calls.\n\tAcross   lines."))
+
+	exact := strings.Repeat("a", maxDescriptionLength)
+	assert.Equal(t, exact, processDescription(exact))
+
+	long := processDescription(exact + "b")
+	assert.Len(t, long, maxDescriptionLength)
+	assert.True(t, strings.HasSuffix(long, "..."))
+}
+
+func TestMapUnit(t *testing.T) {
+	t.Run("units", func(t *testing.T) {
+		unit, err := mapUnit("/gc/heap/live:bytes")
+		require.NoError(t, err)
+		assert.Equal(t, metadata.Unit{Name: "byte"}, unit)
+
+		unit, err = mapUnit("/sched/goroutines:goroutines")
+		require.NoError(t, err)
+		assert.Equal(t, metadata.Unit{}, unit)
+
+		unit, err = mapUnit("runtime.go.metrics.gc_frequency")
+		require.NoError(t, err)
+		assert.Equal(t, metadata.Unit{PerUnit: "second"}, unit)
+
+		_, err = mapUnit("/test/distance:parsecs")
+		assert.ErrorContains(t, err, "parsecs")
+	})
+
+	t.Run("all the supported metrics have a unit", func(t *testing.T) {
+		for _, d := range runtimemetrics.SupportedMetrics() {
+			_, err := mapUnit(d.Name)
+			assert.NoError(t, err)
+		}
+		for _, d := range runtimemetrics.DerivedMetrics() {
+			_, err := mapUnit(d.Name)
+			assert.NoError(t, err)
+		}
+	})
+}
+
+func TestGetOrientation(t *testing.T) {
+	t.Run("heuristic and overrides", func(t *testing.T) {
+		for name, want := range map[string]string{
+			"/gc/pauses:seconds":                    "-1",
+			"/sched/latencies:seconds":              "-1",
+			"/cpu/classes/gc/total:cpu-seconds":     "-1",
+			"/cpu/classes/gc/mark/idle:cpu-seconds": "0",
+			"/sync/mutex/wait/total:seconds":        "-1",
+			"/cpu/classes/idle:cpu-seconds":         "1",
+			"/gc/heap/live:bytes":                   "0",
+			"/cpu/classes/user:cpu-seconds":         "0",
+			"/sched/goroutines/running:goroutines":  "0",
+			"runtime.go.metrics.gc_frequency":       "-1",
+		} {
+			assert.Equal(t, want, getOrientation(name), name)
+		}
+	})
+
+	t.Run("overrides refer to known metrics", func(t *testing.T) {
+		known := runtimemetrics.AllDatadogMetricNames()
+		for _, d := range metrics.All() {
+			known = append(known, d.Name)
+		}
+		for name, o := range metadata.OrientationOverrides {
+			assert.Contains(t, known, name)
+			assert.Contains(t, []int{-1, 0, 1}, o, name)
+		}
+	})
+
+	// Metrics with an orientation other than 0 are listed here, so that the
+	// orientation of new metrics is a deliberate choice.
+	t.Run("all the oriented metrics", func(t *testing.T) {
+		want := map[string]string{
+			"/cpu/classes/gc/mark/assist:cpu-seconds":    "-1",
+			"/cpu/classes/gc/mark/dedicated:cpu-seconds": "-1",
+			"/cpu/classes/gc/pause:cpu-seconds":          "-1",
+			"/cpu/classes/gc/total:cpu-seconds":          "-1",
+			"/cpu/classes/idle:cpu-seconds":              "1",
+			"/cpu/classes/scavenge/assist:cpu-seconds":   "-1",
+			"/gc/pauses:seconds":                         "-1",
+			"/memory/classes/heap/unused:bytes":          "-1",
+			"/sched/latencies:seconds":                   "-1",
+			"/sched/pauses/stopping/gc:seconds":          "-1",
+			"/sched/pauses/stopping/other:seconds":       "-1",
+			"/sched/pauses/total/gc:seconds":             "-1",
+			"/sched/pauses/total/other:seconds":          "-1",
+			"/sync/mutex/wait/total:seconds":             "-1",
+			"runtime.go.metrics.gc_frequency":            "-1",
+			"runtime.go.metrics.derived.gc_thrashing":    "-1",
+			"runtime.go.metrics.heap_fragmentation":      "-1",
+			"runtime.go.metrics.gc_cpu_fraction":         "-1",
+		}
+		var names []string
+		for _, d := range runtimemetrics.SupportedMetrics() {
+			names = append(names, d.Name)
+		}
+		for _, d := range runtimemetrics.DerivedMetrics() {
+			names = append(names, d.Name)
+		}
+		for _, name := range names {
+			if o := getOrientation(name); o != "0" || want[name] != "" {
+				assert.Equal(t, want[name], o, name)
+			}
+		}
+	})
+}
+
+func TestShortNames(t *testing.T) {
+	t.Run("collisions are disambiguated with the unit", func(t *testing.T) {
+		metadata, err := generate([]metrics.Description{
+			{Name: "/gc/heap/allocs:bytes", Kind: metrics.KindUint64},
+			{Name: "/gc/heap/allocs:objects", Kind: metrics.KindUint64},
+			{Name: "/gc/heap/live:bytes", Kind: metrics.KindUint64},
+			{Name: "/sched/pauses:seconds", Kind: metrics.KindFloat64Histogram},
+			{Name: "/sched/pauses:cpu-seconds", Kind: metrics.KindUint64},
+		})
+		require.NoError(t, err)
+		var shortNames []string
+		for _, m := range metadata {
+			shortNames = append(shortNames, m.ShortName)
+		}
+		assert.Equal(t, []string{
+			"gc heap allocs bytes",
+			"gc heap allocs objects",
+			"gc heap live",
+			"sched pauses cpu seconds",
+			"sched pauses seconds",
+			"sched pauses seconds avg",
+			"sched pauses seconds min",
+			"sched pauses seconds max",
+			"sched pauses seconds median",
+			"sched pauses seconds p95",
+			"sched pauses seconds p99",
+		}, shortNames)
+	})
+
+	t.Run("remaining collisions are an error", func(t *testing.T) {
+		_, err := generate([]metrics.Description{
+			{Name: "/gc/heap-live:bytes", Kind: metrics.KindUint64},
+			{Name: "/gc/heap/live:bytes", Kind: metrics.KindUint64},
+		})
+		assert.ErrorContains(t, err, `same short name "gc heap live bytes"`)
+	})
+
+	t.Run("long names are truncated at a word boundary", func(t *testing.T) {
+		old := MaxShortNameLength
+		MaxShortNameLength = 20
+		t.Cleanup(func() { MaxShortNameLength = old })
+
+		assert.Equal(t, "gc heap live", truncateShortName("gc heap live", ""))
+		assert.Equal(t, "memory classes heap", truncateShortName("memory classes heap released", ""))
+		assert.Equal(t, "memory classes p99", truncateShortName("memory classes heap released", " p99"))
+		assert.Equal(t, "abcdefghijklmnopqrst", truncateShortName("abcdefghijklmnopqrstuvwxyz", ""))
+
+		metadata, err := generate([]metrics.Description{
+			{Name: "/memory/classes/heap/released:bytes", Kind: metrics.KindFloat64Histogram},
+		})
+		require.NoError(t, err)
+		for _, m := range metadata {
+			assert.LessOrEqual(t, len(m.ShortName), MaxShortNameLength, m.ShortName)
+		}
+	})
+
+	t.Run("generated short names are unique", func(t *testing.T) {
+		generated, err := Generate()
+		require.NoError(t, err)
+		assert.NoError(t, checkShortNames(generated))
+	})
+}
+
+func TestGetShortName(t *testing.T) {
+	assert.Equal(t, "gc heap live", getShortName("/gc/heap/live:bytes"))
+	assert.Equal(t, "gc limiter last enabled", getShortName("/gc/limiter/last-enabled:gc-cycle"))
+	assert.Equal(t, "sched goroutines created", getShortName("/sched/goroutines-created:goroutines"))
+}
@@ -0,0 +1,78 @@
+package gen
+
+import (
+	"bytes"
+	"fmt"
+	"go/format"
+	"io"
+	"runtime"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/DataDog/go-runtime-metrics-internal/internal/metadata"
+	"github.com/DataDog/go-runtime-metrics-internal/pkg/runtimemetrics"
+)
+
+// WriteGo writes metadata as the Go source of pkg/runtimemetrics embedding
+// it, see runtimemetrics.Metadata. The source records the Go version of the
+// running toolchain.
+func WriteGo(w io.Writer, metadata []Metric) error {
+	var b bytes.Buffer
+	b.WriteString("// Code generated by go run ./tools/metricmetadata -format go. DO NOT EDIT.\n\n")
+	b.WriteString("package runtimemetrics\n\n")
+	b.WriteString("// MetadataGoVersion is the Go version Metadata was generated with.\n")
+	fmt.Fprintf(&b, "const MetadataGoVersion = %q\n\n", goVersion(runtime.Version()))
+	b.WriteString("var generatedMetadata = []MetricMetadata{\n")
+	for _, m := range metadata {
+		orientation, err := strconv.Atoi(m.Orientation)
+		if err != nil {
+			return fmt.Errorf("%s: invalid orientation %q", m.Name, m.Orientation)
+		}
+		fmt.Fprintf(&b, "{Name: %q, RuntimeName: %q, Type: %q, Unit: %q, PerUnit: %q, Description: %q, Orientation: %d, ShortName: %q},\n",
+			m.Name, m.RuntimeName, m.Type, m.Unit, m.PerUnit, m.Description, orientation, m.ShortName)
+	}
+	b.WriteString("}\n")
+	src, err := format.Source(b.Bytes())
+	if err != nil {
+		return err
+	}
+	_, err = w.Write(src)
+	return err
+}
+
+// Embedded returns the metadata compiled into the library, see
+// runtimemetrics.Metadata, for metrics reported every period. It is the
+// output of GenerateForPeriod for runtimemetrics.MetadataGoVersion.
+func Embedded(period time.Duration) []Metric {
+	var res []Metric
+	for _, m := range runtimemetrics.Metadata() {
+		row := newMetric(
+			m.RuntimeName,
+			m.Name,
+			m.Type,
+			metadata.Unit{Name: m.Unit, PerUnit: m.PerUnit},
+			m.Description,
+			strconv.Itoa(m.Orientation),
+			m.ShortName,
+		)
+		row.Interval = interval(m.Type, period)
+		res = append(res, row)
+	}
+	return res
+}
+
+// goVersion returns the major and minor version of a Go release, e.g. go1.22
+// for go1.22.3 or go1.22rc1. Other versions, e.g. of development builds, are
+// returned as-is.
+func goVersion(v string) string {
+	major, rest, ok := strings.Cut(v, ".")
+	if !ok || !strings.HasPrefix(major, "go") {
+		return v
+	}
+	minor := rest
+	if i := strings.IndexFunc(minor, func(r rune) bool { return r < '0' || r > '9' }); i >= 0 {
+		minor = minor[:i]
+	}
+	return major + "." + minor
+}
@@ -0,0 +1,84 @@
+package gen
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"runtime"
+	"runtime/metrics"
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/DataDog/go-runtime-metrics-internal/pkg/runtimemetrics"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestWriteGo(t *testing.T) {
+	metadata, err := generate([]metrics.Description{
+		{Name: "/gc/heap/live:bytes", Description: `Heap memory "occupied" by live objects.`, Kind: metrics.KindUint64},
+		{Name: "/gc/pauses:seconds", Description: "Distribution of GC pauses.", Kind: metrics.KindFloat64Histogram, Cumulative: true},
+	})
+	require.NoError(t, err)
+
+	var buf bytes.Buffer
+	require.NoError(t, WriteGo(&buf, metadata))
+	src := buf.String()
+	assert.Contains(t, src, "// Code generated by go run ./tools/metricmetadata -format go. DO NOT EDIT.\n")
+	assert.Contains(t, src, `const MetadataGoVersion = "`+goVersion(runtime.Version())+`"`)
+	assert.Contains(t, src, `{Name: "runtime.go.metrics.gc_heap_live.bytes", RuntimeName: "/gc/heap/live:bytes", Type: "gauge", Unit: "byte", PerUnit: "", Description: "Heap memory \"occupied\" by live objects.", Orientation: 0, ShortName: "gc heap live"},`)
+	assert.Contains(t, src, `{Name: "runtime.go.metrics.gc_pauses.seconds.p99", RuntimeName: "/gc/pauses:seconds", Type: "gauge", Unit: "second", PerUnit: "", Description: "p99 of: Distribution of GC pauses.", Orientation: -1, ShortName: "gc pauses p99"},`)
+
+	assert.Error(t, WriteGo(&buf, []Metric{{Name: "a", Orientation: "up"}}))
+}
+
+// TestEmbedded ensures the metadata compiled into pkg/runtimemetrics is up to
+// date. It can only be regenerated with the Go version it was generated
+// with, the runtime metrics differing between versions: CI runs that version
+// too, see TestCIGoVersions. In CI, a newer toolchain fails the test, the
+// metadata should be regenerated with it.
+func TestEmbedded(t *testing.T) {
+	embedded := Embedded(runtimemetrics.DefaultPeriod)
+	require.NotEmpty(t, embedded)
+
+	if v := goVersion(runtime.Version()); v != runtimemetrics.MetadataGoVersion {
+		if os.Getenv("CI") != "" && minorGoVersion(t, v) > minorGoVersion(t, runtimemetrics.MetadataGoVersion) {
+			t.Fatalf("the metadata was generated with %s, run go generate ./pkg/runtimemetrics with %s", runtimemetrics.MetadataGoVersion, v)
+		}
+		t.Skipf("the metadata was generated with %s, not %s", runtimemetrics.MetadataGoVersion, v)
+	}
+	generated, err := Generate()
+	require.NoError(t, err)
+	assert.Equal(t, generated, embedded)
+
+	var buf bytes.Buffer
+	require.NoError(t, WriteGo(&buf, generated))
+	want, err := os.ReadFile(filepath.Join("..", "..", "..", "..", "pkg", "runtimemetrics", "metadata_generated.go"))
+	require.NoError(t, err)
+	assert.Equal(t, string(want), buf.String(), "the embedded metadata is out of date, run go generate ./pkg/runtimemetrics")
+}
+
+// TestCIGoVersions ensures CI runs the Go version the embedded metadata was
+// generated with, so that TestEmbedded doesn't skip there.
+func TestCIGoVersions(t *testing.T) {
+	workflow, err := os.ReadFile(filepath.Join("..", "..", "..", "..", ".github", "workflows", "go.yml"))
+	require.NoError(t, err)
+	version := strings.TrimPrefix(runtimemetrics.MetadataGoVersion, "go")
+	assert.Contains(t, string(workflow), "'"+version+"'", "CI should test the Go version of the embedded metadata")
+}
+
+// minorGoVersion returns the minor version of a goVersion, e.g. 22 for
+// go1.22.
+func minorGoVersion(t *testing.T, v string) int {
+	minor, err := strconv.Atoi(strings.TrimPrefix(v, "go1."))
+	require.NoError(t, err, v)
+	return minor
+}
+
+func TestGoVersion(t *testing.T) {
+	assert.Equal(t, "go1.22", goVersion("go1.22.3"))
+	assert.Equal(t, "go1.22", goVersion("go1.22"))
+	assert.Equal(t, "go1.23", goVersion("go1.23rc1"))
+	assert.Equal(t, "devel go1.24-abc", goVersion("devel go1.24-abc"))
+}
@@ -0,0 +1,72 @@
+package gen
+
+import (
+	"fmt"
+	"io"
+	"slices"
+	"strings"
+)
+
+// markdownHeader is the header of the table written by WriteMarkdown.
+var markdownHeader = []string{"Datadog name", "Runtime metric", "Type", "Unit", "Description", "Go version"}
+
+// WriteMarkdown writes metadata as a markdown reference table to w, sorted by
+// Datadog name. The summaries of histograms are listed right after their
+// distribution.
+func WriteMarkdown(w io.Writer, metadata []Metric) error {
+	// Group the rows by runtime metric, which keeps the summaries with their
+	// distribution, and sort the groups by the name of their first row.
+	var groups [][]Metric
+	for i, m := range metadata {
+		if i > 0 && m.RuntimeName != "" && m.RuntimeName == metadata[i-1].RuntimeName {
+			groups[len(groups)-1] = append(groups[len(groups)-1], m)
+			continue
+		}
+		groups = append(groups, []Metric{m})
+	}
+	slices.SortStableFunc(groups, func(a, b []Metric) int { return strings.Compare(a[0].Name, b[0].Name) })
+
+	var b strings.Builder
+	writeMarkdownRow(&b, markdownHeader)
+	b.WriteString("|" + strings.Repeat(" --- |", len(markdownHeader)) + "\n")
+	for _, g := range groups {
+		for i, m := range g {
+			name := "`" + m.Name + "`"
+			if i > 0 {
+				name = "↳ " + name
+			}
+			runtimeName := "`" + m.RuntimeName + "`"
+			if m.RuntimeName == "" {
+				runtimeName = "(derived)"
+			}
+			writeMarkdownRow(&b, []string{
+				name,
+				runtimeName,
+				m.Type,
+				markdownUnit(m),
+				m.Description,
+				// TODO: Report the first Go version supporting each metric
+				// once the supported metrics are tracked per version.
+				"all",
+			})
+		}
+	}
+	_, err := io.WriteString(w, b.String())
+	return err
+}
+
+// markdownUnit returns the unit of m, e.g. "byte/second" for rates.
+func markdownUnit(m Metric) string {
+	if m.PerUnit == "" {
+		return m.Unit
+	}
+	return m.Unit + "/" + m.PerUnit
+}
+
+func writeMarkdownRow(b *strings.Builder, cells []string) {
+	b.WriteString("|")
+	for _, c := range cells {
+		fmt.Fprintf(b, " %s |", strings.ReplaceAll(c, "|", `\|`))
+	}
+	b.WriteString("\n")
+}
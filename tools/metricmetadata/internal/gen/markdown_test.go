@@ -0,0 +1,40 @@
+package gen
+
+import (
+	"bytes"
+	"flag"
+	"os"
+	"path/filepath"
+	"runtime/metrics"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+var update = flag.Bool("update", false, "update the golden files in testdata")
+
+// TestWriteMarkdown pins the format of the markdown reference table. Run with
+// -update to regenerate the golden file.
+func TestWriteMarkdown(t *testing.T) {
+	metadata, err := generate([]metrics.Description{
+		{Name: "/sched/goroutines:goroutines", Description: "Count of live goroutines.", Kind: metrics.KindUint64},
+		{Name: "/gc/pauses:seconds", Description: "Distribution of individual GC-related stop-the-world pause latencies.", Kind: metrics.KindFloat64Histogram, Cumulative: true},
+		{Name: "/gc/heap/live:bytes", Description: "Heap memory occupied by live objects | test.", Kind: metrics.KindUint64},
+		{Name: "/gc/cycles/total:gc-cycles", Description: "Count of all completed GC cycles.", Kind: metrics.KindUint64, Cumulative: true},
+	})
+	require.NoError(t, err)
+
+	var buf bytes.Buffer
+	require.NoError(t, WriteMarkdown(&buf, metadata))
+
+	golden := filepath.Join("testdata", "reference.md.golden")
+	if *update {
+		require.NoError(t, os.MkdirAll(filepath.Dir(golden), 0o755))
+		require.NoError(t, os.WriteFile(golden, buf.Bytes(), 0o644))
+		return
+	}
+	want, err := os.ReadFile(golden)
+	require.NoError(t, err)
+	assert.Equal(t, string(want), buf.String(), "the markdown changed, run go test -run TestWriteMarkdown -update if this is intended")
+}
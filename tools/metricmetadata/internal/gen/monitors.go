@@ -0,0 +1,114 @@
+package gen
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/DataDog/go-runtime-metrics-internal/pkg/runtimemetrics"
+)
+
+// Monitor is a Datadog monitor definition, see
+// https://docs.datadoghq.com/api/latest/monitors/. Only the fields used by
+// NewMonitors are modeled.
+type Monitor struct {
+	Name    string         `json:"name"`
+	Type    string         `json:"type"`
+	Query   string         `json:"query"`
+	Message string         `json:"message"`
+	Tags    []string       `json:"tags"`
+	Options MonitorOptions `json:"options"`
+}
+
+// MonitorOptions are the options of a Monitor.
+type MonitorOptions struct {
+	Thresholds        Thresholds `json:"thresholds"`
+	NotifyNoData      bool       `json:"notify_no_data"`
+	RequireFullWindow bool       `json:"require_full_window"`
+}
+
+// Thresholds are the alert thresholds of a Monitor.
+type Thresholds struct {
+	Critical float64 `json:"critical"`
+	Warning  float64 `json:"warning"`
+}
+
+// monitorMessage is appended to the message of all monitors.
+const monitorMessage = "The thresholds of this monitor are placeholders, adjust them to the service. Notify: @REPLACE_ME"
+
+// NewMonitors returns a curated set of monitor templates for the given
+// metrics. They are grouped by service and by the base tags of the library,
+// see runtimemetrics.BaseTagNames. It returns an error if a metric a monitor
+// needs is missing from metadata.
+func NewMonitors(metadata []Metric) ([]Monitor, error) {
+	r := metricResolver{metadata: metadata, what: "monitors"}
+	by := "{*} by {" + strings.Join(append([]string{"service"}, runtimemetrics.BaseTagNames()...), ",") + "}"
+	metric := func(aggregation, name string) string {
+		return aggregation + ":" + name + by
+	}
+
+	monitors := []Monitor{
+		newMonitor(
+			"GC pause p99 is high on {{service.name}}",
+			"avg(last_10m):"+metric("avg", r.summary("/gc/pauses:seconds", "p99")),
+			0.05, 0.02,
+			"The p99 of the GC stop-the-world pauses is above {{threshold}}s.",
+		),
+		newMonitor(
+			"Memory limit utilization is high on {{service.name}}",
+			"avg(last_10m):"+metric("avg", r.metric("/memory/classes/total:bytes"))+" / "+metric("avg", r.metric("/gc/gomemlimit:bytes")),
+			0.9, 0.8,
+			"The memory mapped by the Go runtime is above {{threshold}} of GOMEMLIMIT, the GC will run more and more often.",
+		),
+		newMonitor(
+			"Goroutine count is spiking on {{service.name}}",
+			"change(avg(last_5m),last_5m):"+metric("avg", r.metric("/sched/goroutines:goroutines")),
+			10000, 5000,
+			"The number of goroutines increased by more than {{threshold}} in 5 minutes, which may be a goroutine leak.",
+		),
+		newMonitor(
+			"GC CPU usage is high on {{service.name}}",
+			"avg(last_10m):per_second("+metric("sum", r.metric("/cpu/classes/gc/total:cpu-seconds"))+") / per_second("+metric("sum", r.metric("/cpu/classes/total:cpu-seconds"))+")",
+			0.25, 0.15,
+			"The GC uses more than {{threshold}} of the CPU time available to the process.",
+		),
+		newMonitor(
+			"Scheduling latency p99 is high on {{service.name}}",
+			"avg(last_10m):"+metric("avg", r.summary("/sched/latencies:seconds", "p99")),
+			0.01, 0.005,
+			"Goroutines wait more than {{threshold}}s to run at the p99, the process may be CPU starved.",
+		),
+	}
+	if r.err != nil {
+		return nil, r.err
+	}
+	return monitors, nil
+}
+
+func newMonitor(name, query string, critical, warning float64, message string) Monitor {
+	return Monitor{
+		Name:    name,
+		Type:    "query alert",
+		Query:   fmt.Sprintf("%s > %v", query, critical),
+		Message: message + "\n\n" + monitorMessage,
+		Tags:    []string{"source:go-runtime-metrics"},
+		Options: MonitorOptions{
+			Thresholds: Thresholds{Critical: critical, Warning: warning},
+		},
+	}
+}
+
+// WriteMonitors writes the monitor templates of the given metrics as a JSON
+// array to w, see NewMonitors.
+func WriteMonitors(w io.Writer, metadata []Metric) error {
+	monitors, err := NewMonitors(metadata)
+	if err != nil {
+		return err
+	}
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	// Keep the > of the queries readable.
+	enc.SetEscapeHTML(false)
+	return enc.Encode(monitors)
+}
@@ -0,0 +1,53 @@
+package gen
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"runtime/metrics"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// TestWriteMonitors pins the monitor templates. Run with -update to
+// regenerate the golden file.
+func TestWriteMonitors(t *testing.T) {
+	metadata, err := generate([]metrics.Description{
+		{Name: "/cpu/classes/gc/total:cpu-seconds", Kind: metrics.KindFloat64, Cumulative: true},
+		{Name: "/cpu/classes/total:cpu-seconds", Kind: metrics.KindFloat64, Cumulative: true},
+		{Name: "/gc/gomemlimit:bytes", Kind: metrics.KindUint64},
+		{Name: "/gc/pauses:seconds", Kind: metrics.KindFloat64Histogram, Cumulative: true},
+		{Name: "/memory/classes/total:bytes", Kind: metrics.KindUint64},
+		{Name: "/sched/goroutines:goroutines", Kind: metrics.KindUint64},
+		{Name: "/sched/latencies:seconds", Kind: metrics.KindFloat64Histogram, Cumulative: true},
+	})
+	require.NoError(t, err)
+
+	var buf bytes.Buffer
+	require.NoError(t, WriteMonitors(&buf, metadata))
+
+	golden := filepath.Join("testdata", "monitors.json.golden")
+	if *update {
+		require.NoError(t, os.MkdirAll(filepath.Dir(golden), 0o755))
+		require.NoError(t, os.WriteFile(golden, buf.Bytes(), 0o644))
+		return
+	}
+	want, err := os.ReadFile(golden)
+	require.NoError(t, err)
+	assert.Equal(t, string(want), buf.String(), "the monitors changed, run go test -run TestWriteMonitors -update if this is intended")
+
+	t.Run("the monitors of the current Go version can be generated", func(t *testing.T) {
+		metadata, err := Generate()
+		require.NoError(t, err)
+		monitors, err := NewMonitors(metadata)
+		require.NoError(t, err)
+		assert.GreaterOrEqual(t, len(monitors), 5)
+	})
+
+	t.Run("missing metrics are an error", func(t *testing.T) {
+		_, err := NewMonitors(metadata[1:])
+		assert.ErrorContains(t, err, "/cpu/classes/gc/total:cpu-seconds")
+	})
+}
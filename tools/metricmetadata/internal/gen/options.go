@@ -0,0 +1,111 @@
+package gen
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/DataDog/go-runtime-metrics-internal/pkg/runtimemetrics"
+)
+
+// durationUnits are the Datadog units of the metrics in seconds converted to
+// the units of Options.DurationUnit.
+var durationUnits = map[string]string{
+	"milliseconds": "millisecond",
+	"nanoseconds":  "nanosecond",
+}
+
+// EmbeddedWithOptions is Embedded for an emitter created with opts, whose
+// naming options rename the metrics, see
+// runtimemetrics.DatadogMetricNameWithOptions. The metrics reported under a
+// single name with a tag telling them apart, e.g. the members of a tagged
+// family or the summaries of a histogram with Options.PercentilesAsTags, are
+// merged into one row, with the tag appended to its sample tags.
+// With Options.DurationUnit, the unit of the metrics in seconds is converted
+// too. The other options are ignored, and nil opts are the default options.
+func EmbeddedWithOptions(period time.Duration, opts *runtimemetrics.Options) ([]Metric, error) {
+	if opts == nil {
+		opts = &runtimemetrics.Options{}
+	}
+	var res []Metric
+	// rows maps the names of res to their index, to merge the metrics
+	// reported under the same name.
+	rows := map[string]int{}
+	// merged maps the index of merged rows to the tag key and values, and
+	// the runtime names of their metrics. The summaries of a histogram
+	// merged by Options.PercentilesAsTags also keep its description.
+	type mergedRow struct {
+		tag          string
+		values       []string
+		runtimeNames []string
+		summaryOf    string
+	}
+	merged := map[int]*mergedRow{}
+	// Short names don't depend on the prefix of the names.
+	unprefixed := *opts
+	unprefixed.SubsystemPrefixes = nil
+	// dist is the last distribution, which its summaries follow.
+	var dist Metric
+	for _, m := range Embedded(period) {
+		if m.RuntimeName == "" {
+			res = append(res, m)
+			continue
+		}
+		var name, tag string
+		var err error
+		summary := m.Type == "gauge" && m.RuntimeName == dist.RuntimeName
+		if summary {
+			stat := strings.TrimPrefix(m.Name, dist.Name+".")
+			name, tag, err = runtimemetrics.DatadogSummaryMetricNameWithOptions(m.RuntimeName, stat, opts)
+		} else {
+			if m.Type == "distribution" {
+				dist = m
+			}
+			name, tag, err = runtimemetrics.DatadogMetricNameWithOptions(m.RuntimeName, opts)
+		}
+		if err != nil {
+			return nil, err
+		}
+		if unit, ok := durationUnits[opts.DurationUnit]; ok && strings.HasSuffix(m.RuntimeName, ":seconds") {
+			m.Unit = unit
+		}
+		if tag == "" {
+			m.Name = name
+			rows[name] = len(res)
+			res = append(res, m)
+			continue
+		}
+		key, value, _ := strings.Cut(tag, ":")
+		i, ok := rows[name]
+		if !ok {
+			r := &mergedRow{tag: key}
+			if summary {
+				m.ShortName = truncateShortName(dist.ShortName, " summary")
+				r.summaryOf = dist.Description
+			} else {
+				shortName, _, err := runtimemetrics.DatadogMetricNameWithOptions(m.RuntimeName, &unprefixed)
+				if err != nil {
+					return nil, err
+				}
+				m.ShortName = truncateShortName(datadogShortName(shortName), "")
+			}
+			m.Name = name
+			m.SampleTags += "," + key
+			i = len(res)
+			rows[name] = i
+			merged[i] = r
+			res = append(res, m)
+		}
+		merged[i].values = append(merged[i].values, value)
+		merged[i].runtimeNames = append(merged[i].runtimeNames, m.RuntimeName)
+	}
+	for i, r := range merged {
+		if r.summaryOf != "" {
+			res[i].Description = processDescription(fmt.Sprintf("One series per %s tag, for the %s of: %s", r.tag, strings.Join(r.values, ", "), r.summaryOf))
+			continue
+		}
+		res[i].RuntimeName = strings.Join(r.runtimeNames, ", ")
+		res[i].Description = processDescription(fmt.Sprintf("One series per %s tag, for %s.", r.tag, res[i].RuntimeName))
+	}
+	return res, nil
+}
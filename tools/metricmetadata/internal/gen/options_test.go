@@ -0,0 +1,86 @@
+package gen
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/DataDog/go-runtime-metrics-internal/pkg/runtimemetrics"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestEmbeddedWithOptions(t *testing.T) {
+	t.Run("default options", func(t *testing.T) {
+		got, err := EmbeddedWithOptions(runtimemetrics.DefaultPeriod, nil)
+		require.NoError(t, err)
+		assert.Equal(t, Embedded(runtimemetrics.DefaultPeriod), got)
+	})
+
+	t.Run("tagged families and subsystem prefixes", func(t *testing.T) {
+		got, err := EmbeddedWithOptions(runtimemetrics.DefaultPeriod, &runtimemetrics.Options{
+			TaggedFamilies:    []string{"goroutines"},
+			SubsystemPrefixes: map[string]string{"sched": "runtime.sched."},
+		})
+		require.NoError(t, err)
+
+		names := map[string]Metric{}
+		for _, m := range got {
+			_, dup := names[m.Name]
+			assert.False(t, dup, m.Name)
+			names[m.Name] = m
+		}
+		family, ok := names["runtime.sched.sched_goroutines_by_state.goroutines"]
+		require.True(t, ok)
+		assert.Equal(t, "gauge", family.Type)
+		assert.True(t, strings.HasSuffix(family.SampleTags, ",state"), family.SampleTags)
+		assert.Equal(t, "sched goroutines by state goroutines", family.ShortName)
+		assert.Contains(t, family.RuntimeName, "/sched/goroutines/running:goroutines")
+		assert.NotContains(t, names, "runtime.go.metrics.sched_goroutines_running.goroutines")
+
+		assert.Equal(t, "distribution", names["runtime.sched.sched_latencies.seconds"].Type)
+		assert.Equal(t, "gauge", names["runtime.sched.sched_latencies.seconds.p99"].Type)
+		assert.Equal(t, "gauge", names["runtime.go.metrics.gc_heap_live.bytes"].Type)
+	})
+
+	t.Run("duration unit", func(t *testing.T) {
+		got, err := EmbeddedWithOptions(runtimemetrics.DefaultPeriod, &runtimemetrics.Options{DurationUnit: "milliseconds"})
+		require.NoError(t, err)
+
+		names := map[string]Metric{}
+		for _, m := range got {
+			names[m.Name] = m
+		}
+		assert.Equal(t, "millisecond", names["runtime.go.metrics.sched_latencies.milliseconds"].Unit)
+		assert.Equal(t, "millisecond", names["runtime.go.metrics.sched_latencies.milliseconds.p99"].Unit)
+		assert.NotContains(t, names, "runtime.go.metrics.sched_latencies.seconds")
+		assert.Equal(t, "byte", names["runtime.go.metrics.gc_heap_live.bytes"].Unit)
+	})
+
+	t.Run("percentiles as tags", func(t *testing.T) {
+		got, err := EmbeddedWithOptions(runtimemetrics.DefaultPeriod, &runtimemetrics.Options{PercentilesAsTags: true})
+		require.NoError(t, err)
+
+		names := map[string]Metric{}
+		for _, m := range got {
+			_, dup := names[m.Name]
+			assert.False(t, dup, m.Name)
+			names[m.Name] = m
+		}
+		summary, ok := names["runtime.go.metrics.sched_latencies.seconds.summary"]
+		require.True(t, ok)
+		assert.Equal(t, "gauge", summary.Type)
+		assert.Equal(t, "/sched/latencies:seconds", summary.RuntimeName)
+		assert.True(t, strings.HasSuffix(summary.SampleTags, ",stat"), summary.SampleTags)
+		assert.Equal(t, "sched latencies summary", summary.ShortName)
+		assert.Contains(t, summary.Description, "avg, min, max, median, p95, p99 of:")
+		assert.NotContains(t, names, "runtime.go.metrics.sched_latencies.seconds.p99")
+		assert.Equal(t, "distribution", names["runtime.go.metrics.sched_latencies.seconds"].Type)
+	})
+
+	t.Run("invalid options", func(t *testing.T) {
+		_, err := EmbeddedWithOptions(runtimemetrics.DefaultPeriod, &runtimemetrics.Options{TaggedFamilies: []string{"nope"}})
+		assert.Error(t, err)
+		_, err = EmbeddedWithOptions(runtimemetrics.DefaultPeriod, &runtimemetrics.Options{DurationUnit: "hours"})
+		assert.ErrorContains(t, err, "unsupported duration unit")
+	})
+}
@@ -0,0 +1,231 @@
+// Command metricmetadata generates the Datadog metadata.csv describing the
+// metrics reported by pkg/runtimemetrics.
+//
+// Usage:
+//
+//	go run ./tools/metricmetadata [-format csv|markdown|go] [-period d] [-o path] [naming flags]
+//	go run ./tools/metricmetadata -dashboard [-o path]
+//	go run ./tools/metricmetadata -monitors [-o path]
+//	go run ./tools/metricmetadata -check path [naming flags]
+//	go run ./tools/metricmetadata -audit
+//
+// The metadata of the running Go toolchain is compiled into pkg/runtimemetrics,
+// see runtimemetrics.Metadata, by go generate with -format go. The other
+// outputs format that embedded metadata.
+//
+// The metadata is written to metadata.csv by default, or to stdout with -o -,
+// after a comment line recording runtimemetrics.Version. With -format
+// markdown, a reference table of the metrics is written to metrics.md instead.
+// With -dashboard, a Datadog dashboard JSON definition graphing the metrics is
+// written to dashboard.json. With -monitors, a JSON array of recommended
+// Datadog monitor templates is written to monitors.json. With -check, the
+// embedded metadata is compared with an existing file instead, and the command
+// exits with status 1 if they differ. -period sets the interval of count
+// metrics when the emitter isn't configured with the default period.
+// -max-short-name sets the length short names are truncated to when generating
+// the embedded metadata.
+//
+// The naming flags describe the metrics of an emitter whose options rename
+// them, for the CSV and markdown outputs and -check: -tagged-families,
+// -subsystem-prefixes, -duration-unit and -percentiles-as-tags match
+// Options.TaggedFamilies, Options.SubsystemPrefixes, Options.DurationUnit and
+// Options.PercentilesAsTags. The members of a tagged family, and the
+// summaries of a histogram with -percentiles-as-tags, are described by a
+// single row, with the tag in its sample tags. The metrics in seconds get the
+// unit of -duration-unit. The dashboard and monitors only support the default
+// names: they graph and alert on the per-summary gauges, e.g.
+// runtime.go.metrics.gc_pauses.seconds.p99, which an emitter with
+// PercentilesAsTags doesn't report.
+//
+// With -audit, the runtime metrics of the running Go toolchain are compared
+// with the ones supported by the library, to review what changed when
+// bumping the Go version: metrics the library doesn't report, metrics it
+// refers to that the toolchain doesn't have, and units without a Datadog
+// unit. The command exits with status 1 if the library refers to missing
+// metrics.
+package main
+
+import (
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"runtime"
+	"runtime/metrics"
+	"strings"
+	"time"
+
+	"github.com/DataDog/go-runtime-metrics-internal/internal/metadata"
+	"github.com/DataDog/go-runtime-metrics-internal/pkg/runtimemetrics"
+	"github.com/DataDog/go-runtime-metrics-internal/tools/metricmetadata/internal/gen"
+)
+
+func main() {
+	out := flag.String("o", "", "path of the generated file, - for stdout (default metadata.csv, metrics.md, dashboard.json or monitors.json depending on the output)")
+	format := flag.String("format", "csv", "output format: csv, markdown for a reference table, or go for the source embedding the metadata in pkg/runtimemetrics")
+	dashboard := flag.Bool("dashboard", false, "write a Datadog dashboard JSON definition instead of the metadata")
+	monitors := flag.Bool("monitors", false, "write Datadog monitor JSON templates instead of the metadata")
+	period := flag.Duration("period", runtimemetrics.DefaultPeriod, "reporting period of the emitter, the interval of count metrics")
+	check := flag.String("check", "", "compare the generated metadata with the given file instead of writing it, and exit with status 1 if they differ")
+	flag.IntVar(&gen.MaxShortNameLength, "max-short-name", gen.MaxShortNameLength, "maximum length of the short names, longer ones are truncated")
+	audit := flag.Bool("audit", false, "report the differences between the runtime metrics of the toolchain and the ones supported by the library")
+	taggedFamilies := flag.String("tagged-families", "", "comma-separated metric families reported under a single name with a tag, see Options.TaggedFamilies")
+	subsystemPrefixes := flag.String("subsystem-prefixes", "", "comma-separated subsystem=prefix pairs replacing the runtime.go.metrics. prefix, see Options.SubsystemPrefixes")
+	durationUnit := flag.String("duration-unit", "", "unit of the metrics in seconds: seconds, milliseconds or nanoseconds, see Options.DurationUnit")
+	percentilesAsTags := flag.Bool("percentiles-as-tags", false, "describe the summaries of histograms as a single gauge tagged with stat, see Options.PercentilesAsTags")
+	flag.Parse()
+
+	opts, err := namingOptions(*taggedFamilies, *subsystemPrefixes, *durationUnit, *percentilesAsTags)
+	if err == nil {
+		if *audit {
+			err = auditMetrics(os.Stdout)
+		} else if *check != "" {
+			err = checkMetadata(*check, *period, opts, os.Stdout)
+		} else {
+			switch {
+			case *dashboard:
+				*format = "dashboard"
+			case *monitors:
+				*format = "monitors"
+			}
+			err = run(*out, *format, *period, opts)
+		}
+	}
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "metricmetadata: %v\n", err)
+		os.Exit(1)
+	}
+}
+
+// namingOptions returns the emitter options of the naming flags, or nil if
+// none is set.
+func namingOptions(taggedFamilies, subsystemPrefixes, durationUnit string, percentilesAsTags bool) (*runtimemetrics.Options, error) {
+	if taggedFamilies == "" && subsystemPrefixes == "" && durationUnit == "" && !percentilesAsTags {
+		return nil, nil
+	}
+	// The options are validated like NewEmitter does by
+	// gen.EmbeddedWithOptions.
+	opts := &runtimemetrics.Options{DurationUnit: durationUnit, PercentilesAsTags: percentilesAsTags}
+	if taggedFamilies != "" {
+		opts.TaggedFamilies = strings.Split(taggedFamilies, ",")
+	}
+	if subsystemPrefixes != "" {
+		opts.SubsystemPrefixes = map[string]string{}
+		for _, pair := range strings.Split(subsystemPrefixes, ",") {
+			subsystem, prefix, ok := strings.Cut(pair, "=")
+			if !ok {
+				return nil, fmt.Errorf("invalid subsystem prefix %q, it must be subsystem=prefix", pair)
+			}
+			opts.SubsystemPrefixes[subsystem] = prefix
+		}
+	}
+	return opts, nil
+}
+
+func run(out, format string, period time.Duration, opts *runtimemetrics.Options) error {
+	var write func(io.Writer, []gen.Metric) error
+	var defaultOut string
+	switch format {
+	case "csv":
+		write, defaultOut = gen.Write, "metadata.csv"
+	case "markdown":
+		write, defaultOut = gen.WriteMarkdown, "metrics.md"
+	case "dashboard":
+		write, defaultOut = gen.WriteDashboard, "dashboard.json"
+	case "monitors":
+		write, defaultOut = gen.WriteMonitors, "monitors.json"
+	case "go":
+		write, defaultOut = gen.WriteGo, "metadata_generated.go"
+	default:
+		return fmt.Errorf("unknown format %q", format)
+	}
+	if out == "" {
+		out = defaultOut
+	}
+	if opts != nil && format != "csv" && format != "markdown" {
+		return fmt.Errorf("the naming flags aren't supported with the %s output", format)
+	}
+	// Only the embedded metadata is generated from the running toolchain.
+	metadata, err := gen.EmbeddedWithOptions(period, opts)
+	if err != nil {
+		return err
+	}
+	if format == "go" {
+		if metadata, err = gen.GenerateForPeriod(period); err != nil {
+			return err
+		}
+	}
+	if out == "-" {
+		if err := write(os.Stdout, metadata); err != nil {
+			return err
+		}
+		fmt.Fprintf(os.Stderr, "%s written to stdout\n", format)
+		return nil
+	}
+
+	if dir := filepath.Dir(out); dir != "." {
+		if err := os.MkdirAll(dir, 0o755); err != nil {
+			return err
+		}
+	}
+	f, err := os.Create(out)
+	if err != nil {
+		return err
+	}
+	if err := write(f, metadata); err != nil {
+		f.Close()
+		return err
+	}
+	if err := f.Close(); err != nil {
+		return err
+	}
+	fmt.Printf("%s written to %s\n", format, out)
+	return nil
+}
+
+// checkMetadata compares the embedded metadata, named as with opts if not
+// nil, with the metadata.csv at path, and prints the differences to w. It
+// returns an error if they differ.
+func checkMetadata(path string, period time.Duration, opts *runtimemetrics.Options, w io.Writer) error {
+	f, err := os.Open(path)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+	existing, err := gen.Read(f)
+	if err != nil {
+		return fmt.Errorf("%s: %w", path, err)
+	}
+	metadata, err := gen.EmbeddedWithOptions(period, opts)
+	if err != nil {
+		return err
+	}
+	diff := gen.Diff(existing, metadata)
+	if len(diff) == 0 {
+		fmt.Fprintf(w, "%s is up to date\n", path)
+		return nil
+	}
+	for _, line := range diff {
+		fmt.Fprintln(w, line)
+	}
+	return fmt.Errorf("%s is out of date, %d metrics differ, regenerate it with -o %s", path, len(diff), path)
+}
+
+// auditMetrics writes the audit of the runtime metrics to w, see
+// metadata.Audit. It returns an error if the library refers to metrics the
+// toolchain doesn't have.
+func auditMetrics(w io.Writer) error {
+	var supported []string
+	for _, d := range runtimemetrics.SupportedMetrics() {
+		supported = append(supported, d.Name)
+	}
+	report := metadata.Audit(metrics.All(), supported, runtimemetrics.ReferencedMetrics())
+	if err := report.Write(w); err != nil {
+		return err
+	}
+	if !report.OK() {
+		return fmt.Errorf("the library refers to %d metrics missing from %s", len(report.Missing), runtime.Version())
+	}
+	return nil
+}